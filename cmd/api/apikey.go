@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/domains/apikeys"
+	"github.com/gomantics/semantix/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// createSuperadminKey stores a new superadmin API key and prints it to
+// stdout, the only time it's shown. It bootstraps access to a fresh install
+// and returns the process exit code.
+func createSuperadminKey(name string) int {
+	l := logger.New().With(zap.String("service", "semantix"))
+	defer l.Sync()
+
+	ctx := context.Background()
+	if err := db.Connect(ctx); err != nil {
+		l.Error("failed to connect to database", zap.Error(err))
+		return 1
+	}
+	defer db.Close()
+
+	k, key, err := apikeys.Create(ctx, apikeys.CreateParams{Name: name, Superadmin: true})
+	if err != nil {
+		l.Error("failed to create API key", zap.Error(err))
+		return 1
+	}
+
+	l.Info("superadmin API key created", zap.Int64("api_key_id", k.ID), zap.String("prefix", k.Prefix))
+	fmt.Println(key)
+	return 0
+}
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	"github.com/gomantics/semantix/internal/api"
+	"github.com/gomantics/semantix/internal/embedder"
+	"github.com/gomantics/semantix/internal/vectordb"
+	"github.com/gomantics/semantix/internal/worker"
 	"github.com/gomantics/semantix/db"
 	"github.com/gomantics/semantix/pkg/logger"
 	"go.uber.org/fx"
@@ -10,6 +17,28 @@ import (
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "apply pending database migrations and exit")
+	rollback := flag.Int("rollback", 0, "roll back the given number of applied migrations and exit")
+	dryRun := flag.Bool("dry-run", false, "with --migrate-only or --rollback, report migrations without running them")
+	rotate := flag.Bool("rotate-keys", false, "re-encrypt stored git tokens with the active encryption key and exit")
+	superadminKey := flag.String("create-superadmin-key", "", "create a superadmin API key with the given name, print it and exit")
+	flag.Parse()
+
+	if *dryRun && !*migrateOnly && *rollback <= 0 {
+		fmt.Fprintln(flag.CommandLine.Output(), "--dry-run needs --migrate-only or --rollback")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *migrateOnly || *rollback > 0 {
+		os.Exit(migrate(*rollback, *dryRun))
+	}
+	if *rotate {
+		os.Exit(rotateKeys())
+	}
+	if *superadminKey != "" {
+		os.Exit(createSuperadminKey(*superadminKey))
+	}
+
 	fx.New(
 		fx.Provide(
 			logger.New,
@@ -19,8 +48,9 @@ func main() {
 		}),
 		fx.Invoke(
 			db.Init,
-			// TODO: Add Qdrant initialization (Phase 1)
-			// TODO: Re-enable indexing worker (Phase 2)
+			embedder.Init,
+			vectordb.Init,
+			worker.Run,
 			api.Run,
 		),
 		fx.WithLogger(func(l *zap.Logger) fxevent.Logger {
@@ -30,4 +60,3 @@ func main() {
 		}),
 	).Run()
 }
-
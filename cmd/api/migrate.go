@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// migrate applies (or rolls back) migrations without starting the server.
+// It returns the process exit code.
+func migrate(rollback int, dryRun bool) int {
+	l := logger.New().With(zap.String("service", "semantix"))
+	defer l.Sync()
+
+	ctx := context.Background()
+	if err := db.Connect(ctx); err != nil {
+		l.Error("failed to connect to database", zap.Error(err))
+		return 1
+	}
+	defer db.Close()
+
+	opts := db.MigrateOptions{DryRun: dryRun}
+
+	var (
+		result *db.MigrateResult
+		err    error
+	)
+	if rollback > 0 {
+		result, err = db.Rollback(ctx, l, rollback, opts)
+	} else {
+		result, err = db.Migrate(ctx, l, opts)
+	}
+	if err != nil {
+		l.Error("migration failed", zap.Error(err))
+		return 1
+	}
+
+	l.Info("migrations finished",
+		zap.Int("count", len(result.Migrations)),
+		zap.Bool("dry_run", dryRun),
+	)
+	return 0
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/domains/gittokens"
+	"github.com/gomantics/semantix/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// rotateKeys re-encrypts stored git tokens with the active encryption key.
+// It returns the process exit code.
+func rotateKeys() int {
+	l := logger.New().With(zap.String("service", "semantix"))
+	defer l.Sync()
+
+	ctx := context.Background()
+	if err := db.Connect(ctx); err != nil {
+		l.Error("failed to connect to database", zap.Error(err))
+		return 1
+	}
+	defer db.Close()
+
+	rotated, err := gittokens.RotateKeys(ctx, l)
+	if err != nil {
+		l.Error("key rotation failed", zap.Error(err), zap.Int("rotated", rotated))
+		return 1
+	}
+
+	l.Info("key rotation finished", zap.Int("rotated", rotated))
+	return 0
+}
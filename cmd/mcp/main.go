@@ -0,0 +1,54 @@
+// Command mcp serves one workspace to an AI assistant over the Model Context
+// Protocol's stdio transport. Assistants launch it as a subprocess:
+//
+//	{"command": "semantix-mcp", "args": ["--workspace", "acme"]}
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gomantics/semantix/internal/embedder"
+	"github.com/gomantics/semantix/internal/mcp"
+	"github.com/gomantics/semantix/internal/vectordb"
+	"github.com/gomantics/semantix/config"
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/logger"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap"
+)
+
+func main() {
+	workspace := flag.String("workspace", config.Mcp.Workspace(), "slug or ID of the workspace to serve")
+	flag.Parse()
+
+	// The embedded store's files belong to the API process
+	if config.Vectorstore.Backend() == vectordb.BackendEmbedded {
+		fmt.Fprintln(os.Stderr, "the stdio MCP server needs the qdrant or pgvector vector store backend")
+		os.Exit(1)
+	}
+
+	fx.New(
+		fx.Provide(
+			logger.New,
+		),
+		fx.Decorate(func(l *zap.Logger) *zap.Logger {
+			return l.With(zap.String("service", "semantix-mcp"))
+		}),
+		fx.Invoke(
+			db.Init,
+			embedder.Init,
+			vectordb.Init,
+			func(lc fx.Lifecycle, l *zap.Logger, sd fx.Shutdowner) error {
+				return mcp.RunStdio(lc, l, sd, *workspace)
+			},
+		),
+		fx.WithLogger(func(l *zap.Logger) fxevent.Logger {
+			return &fxevent.ZapLogger{
+				Logger: l,
+			}
+		}),
+	).Run()
+}
@@ -0,0 +1,7 @@
+package db
+
+// Conn returns the connection or transaction behind q, for statements sqlc
+// can't express, such as those using columns created at runtime
+func (q *Queries) Conn() DBTX {
+	return q.db
+}
@@ -4,9 +4,6 @@ import (
 	"context"
 	"embed"
 	"fmt"
-	"io/fs"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/gomantics/semantix/config"
@@ -20,123 +17,68 @@ var embedSchema embed.FS
 
 var defaultPool *pgxpool.Pool
 
-// Init initializes the database connection pool and applies schema
+// Init initializes the database connection pool and applies pending migrations
 func Init(lc fx.Lifecycle, l *zap.Logger) error {
 	ctx := context.Background()
 
-	poolConfig, err := pgxpool.ParseConfig(config.Database.Dsn())
-	if err != nil {
-		return fmt.Errorf("failed to parse database config: %w", err)
-	}
-
-	// Connection pool settings
-	poolConfig.MaxConns = 50
-	poolConfig.MinConns = 5
-	poolConfig.MaxConnLifetime = 30 * time.Minute
-	poolConfig.MaxConnIdleTime = 5 * time.Minute
-	poolConfig.HealthCheckPeriod = 1 * time.Minute
-
-	defaultPool, err = pgxpool.NewWithConfig(ctx, poolConfig)
-	if err != nil {
-		return fmt.Errorf("failed to create connection pool: %w", err)
-	}
-
-	// Test connection
-	if err := defaultPool.Ping(ctx); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+	if err := Connect(ctx); err != nil {
+		return err
 	}
 
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
 			l.Info("closing database pool")
-			if defaultPool != nil {
-				defaultPool.Close()
-			}
+			Close()
 			return nil
 		},
 	})
 
 	l.Info("database pool initialized")
 
-	// Apply schema
-	if err := ApplySchema(ctx, l); err != nil {
-		return fmt.Errorf("failed to apply schema: %w", err)
+	result, err := Migrate(ctx, l, MigrateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
 
-	l.Info("database schema applied")
+	l.Info("database migrations applied", zap.Int("count", len(result.Migrations)))
 	return nil
 }
 
-// GetPool returns the default connection pool
-func GetPool() *pgxpool.Pool {
-	return defaultPool
-}
-
-// ApplySchema applies all SQL schema files
-func ApplySchema(ctx context.Context, l *zap.Logger) error {
-	if defaultPool == nil {
-		return fmt.Errorf("pool not initialized")
-	}
-
-	// Get all SQL files from embedded schema
-	sqlFiles, err := getSchemaSQLFiles()
+// Connect creates the default connection pool and verifies connectivity
+func Connect(ctx context.Context) error {
+	poolConfig, err := pgxpool.ParseConfig(config.Database.Dsn())
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to parse database config: %w", err)
 	}
 
-	l.Info("found schema files", zap.Strings("files", sqlFiles))
+	// Connection pool settings
+	poolConfig.MaxConns = 50
+	poolConfig.MinConns = 5
+	poolConfig.MaxConnLifetime = 30 * time.Minute
+	poolConfig.MaxConnIdleTime = 5 * time.Minute
+	poolConfig.HealthCheckPeriod = 1 * time.Minute
 
-	// Acquire a connection for schema operations
-	conn, err := defaultPool.Acquire(ctx)
+	defaultPool, err = pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		return fmt.Errorf("failed to acquire connection: %w", err)
+		return fmt.Errorf("failed to create connection pool: %w", err)
 	}
-	defer conn.Release()
-
-	// Execute each SQL file
-	for _, filename := range sqlFiles {
-		filePath := "schema/" + filename
-
-		l.Info("executing schema file", zap.String("file", filename))
-
-		content, err := embedSchema.ReadFile(filePath)
-		if err != nil {
-			return fmt.Errorf("failed to read schema file %s: %w", filename, err)
-		}
 
-		_, err = conn.Exec(ctx, string(content))
-		if err != nil {
-			return fmt.Errorf("failed to execute schema %s: %w", filename, err)
-		}
-
-		l.Info("schema file executed", zap.String("file", filename))
+	// Test connection
+	if err := defaultPool.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	return nil
 }
 
-// getSchemaSQLFiles returns sorted list of SQL files from embedded schema
-func getSchemaSQLFiles() ([]string, error) {
-	fsys, err := fs.Sub(embedSchema, "schema")
-	if err != nil {
-		return nil, err
-	}
-
-	entries, err := fs.ReadDir(fsys, ".")
-	if err != nil {
-		return nil, err
-	}
-
-	var sqlFiles []string
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		if strings.HasSuffix(entry.Name(), ".sql") {
-			sqlFiles = append(sqlFiles, entry.Name())
-		}
+// Close closes the default connection pool
+func Close() {
+	if defaultPool != nil {
+		defaultPool.Close()
 	}
+}
 
-	sort.Strings(sqlFiles)
-	return sqlFiles, nil
+// GetPool returns the default connection pool
+func GetPool() *pgxpool.Pool {
+	return defaultPool
 }
@@ -0,0 +1,337 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// migrationLockKey is the advisory lock held while migrating ("semantix" in ASCII)
+const migrationLockKey int64 = 0x73656d616e746978
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+var (
+	ErrChecksumMismatch = errors.New("applied migration checksum does not match embedded file")
+	ErrUnknownMigration = errors.New("database has applied a migration unknown to this binary")
+	ErrOutOfOrder       = errors.New("pending migration is older than the latest applied migration")
+	ErrNoDownMigration  = errors.New("migration has no down file")
+)
+
+// Migration is a versioned schema change loaded from the embedded schema directory
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrateOptions controls how migrations are applied
+type MigrateOptions struct {
+	// DryRun reports what would run without changing the database
+	DryRun bool
+}
+
+// MigrateResult lists the migrations that were (or, on a dry run, would be) run
+type MigrateResult struct {
+	Migrations []Migration
+}
+
+type appliedMigration struct {
+	version  int64
+	checksum string
+}
+
+// Migrate applies all pending up migrations in version order.
+// An advisory lock serialises concurrent callers, so several replicas can boot at once.
+func Migrate(ctx context.Context, l *zap.Logger, opts MigrateOptions) (*MigrateResult, error) {
+	migrations, err := embeddedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrateResult{}
+	err = withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		pending, err := pendingMigrations(migrations, applied)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range pending {
+			if opts.DryRun {
+				l.Info("pending migration", zap.Int64("version", m.Version), zap.String("name", m.Name))
+				result.Migrations = append(result.Migrations, m)
+				continue
+			}
+
+			l.Info("applying migration", zap.Int64("version", m.Version), zap.String("name", m.Name))
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, m.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx,
+					`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+					m.Version, m.Name, m.Checksum, time.Now().UnixNano(),
+				)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", m.Version, m.Name, err)
+			}
+			result.Migrations = append(result.Migrations, m)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Rollback reverts the latest steps applied migrations using their down files
+func Rollback(ctx context.Context, l *zap.Logger, steps int, opts MigrateOptions) (*MigrateResult, error) {
+	migrations, err := embeddedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrateResult{}
+	err = withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		// Verify the whole chain before touching anything
+		rollback, err := rollbackMigrations(migrations, applied, steps)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range rollback {
+			if opts.DryRun {
+				l.Info("would roll back migration", zap.Int64("version", m.Version), zap.String("name", m.Name))
+				result.Migrations = append(result.Migrations, m)
+				continue
+			}
+
+			l.Info("rolling back migration", zap.Int64("version", m.Version), zap.String("name", m.Name))
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, m.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %w", m.Version, m.Name, err)
+			}
+			result.Migrations = append(result.Migrations, m)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// withMigrationLock runs fn on a dedicated connection holding the migration advisory lock
+func withMigrationLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	if defaultPool == nil {
+		return fmt.Errorf("pool not initialized")
+	}
+
+	conn, err := defaultPool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx was cancelled
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+	}()
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+		  version    BIGINT PRIMARY KEY,
+		  name       TEXT NOT NULL,
+		  checksum   TEXT NOT NULL,
+		  applied_at BIGINT NOT NULL  -- nanoseconds since epoch
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedMigrations returns the recorded migrations in version order
+func appliedMigrations(ctx context.Context, conn *pgxpool.Conn) ([]appliedMigration, error) {
+	rows, err := conn.Query(ctx, `SELECT version, checksum FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (appliedMigration, error) {
+		var a appliedMigration
+		err := row.Scan(&a.version, &a.checksum)
+		return a, err
+	})
+}
+
+// pendingMigrations verifies applied migrations against the embedded set and returns the rest
+func pendingMigrations(migrations []Migration, applied []appliedMigration) ([]Migration, error) {
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	done := make(map[int64]bool, len(applied))
+	var latest int64
+	for _, a := range applied {
+		m, ok := byVersion[a.version]
+		if !ok {
+			return nil, fmt.Errorf("%w: version %d", ErrUnknownMigration, a.version)
+		}
+		if m.Checksum != a.checksum {
+			return nil, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, m.Version, m.Name)
+		}
+		done[a.version] = true
+		latest = max(latest, a.version)
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if done[m.Version] {
+			continue
+		}
+		if m.Version < latest {
+			return nil, fmt.Errorf("%w: %d_%s", ErrOutOfOrder, m.Version, m.Name)
+		}
+		pending = append(pending, m)
+	}
+
+	return pending, nil
+}
+
+// rollbackMigrations verifies applied migrations against the embedded set and returns
+// the latest steps of them, newest first
+func rollbackMigrations(migrations []Migration, applied []appliedMigration, steps int) ([]Migration, error) {
+	if _, err := pendingMigrations(migrations, applied); err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	var rollback []Migration
+	for i := len(applied) - 1; i >= 0 && len(rollback) < steps; i-- {
+		m := byVersion[applied[i].version]
+		if m.Down == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrNoDownMigration, m.Version, m.Name)
+		}
+		rollback = append(rollback, m)
+	}
+
+	return rollback, nil
+}
+
+// embeddedMigrations loads the migrations of the embedded schema directory
+func embeddedMigrations() ([]Migration, error) {
+	fsys, err := fs.Sub(embedSchema, "schema")
+	if err != nil {
+		return nil, err
+	}
+	return loadMigrations(fsys)
+}
+
+// loadMigrations parses a directory of migration files into migrations sorted by version
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration filename %q (want NNNN_name.up.sql or NNNN_name.down.sql)", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			sum := sha256.Sum256(content)
+			m.Up = string(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func mapFile(content string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(content)}
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func versions(migrations []Migration) []int64 {
+	v := make([]int64, len(migrations))
+	for i, m := range migrations {
+		v[i] = m.Version
+	}
+	return v
+}
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"0010_files.up.sql":        mapFile("CREATE TABLE files ();"),
+				"0010_files.down.sql":      mapFile("DROP TABLE files;"),
+				"0002_repos.up.sql":        mapFile("CREATE TABLE repos ();"),
+				"0002_repos.down.sql":      mapFile("DROP TABLE repos;"),
+				"0001_workspaces.up.sql":   mapFile("CREATE TABLE workspaces ();"),
+				"0001_workspaces.down.sql": mapFile("DROP TABLE workspaces;"),
+			},
+			want: []int64{1, 2, 10},
+		},
+		{
+			name: "subdirectories are skipped",
+			fsys: fstest.MapFS{
+				"0001_workspaces.up.sql": mapFile("CREATE TABLE workspaces ();"),
+				"drafts/notes.txt":       mapFile("later"),
+			},
+			want: []int64{1},
+		},
+		{
+			name: "missing down file",
+			fsys: fstest.MapFS{"0001_workspaces.up.sql": mapFile("CREATE TABLE workspaces ();")},
+			want: []int64{1},
+		},
+		{
+			name:    "missing up file",
+			fsys:    fstest.MapFS{"0001_workspaces.down.sql": mapFile("DROP TABLE workspaces;")},
+			wantErr: true,
+		},
+		{
+			name:    "invalid filename",
+			fsys:    fstest.MapFS{"0001-workspaces.sql": mapFile("CREATE TABLE workspaces ();")},
+			wantErr: true,
+		},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"0001_workspaces.up.sql": mapFile("CREATE TABLE workspaces ();"),
+				"0001_tenants.down.sql":  mapFile("DROP TABLE tenants;"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadMigrations(tt.fsys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(versions(got), tt.want) {
+				t.Errorf("loadMigrations() versions = %v, want %v", versions(got), tt.want)
+			}
+			for _, m := range got {
+				up := string(tt.fsys[migrationFileName(m, "up")].Data)
+				down := tt.fsys[migrationFileName(m, "down")]
+				if m.Up != up || m.Checksum != checksum(up) {
+					t.Errorf("migration %d has up %q and checksum %s", m.Version, m.Up, m.Checksum)
+				}
+				if (down == nil && m.Down != "") || (down != nil && m.Down != string(down.Data)) {
+					t.Errorf("migration %d has down %q", m.Version, m.Down)
+				}
+			}
+		})
+	}
+}
+
+func migrationFileName(m Migration, direction string) string {
+	return fmt.Sprintf("%04d_%s.%s.sql", m.Version, m.Name, direction)
+}
+
+// TestEmbeddedMigrations checks the shipped schema loads and can be rolled
+// back all the way
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := embeddedMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s is numbered out of sequence", m.Version, m.Name)
+		}
+		if m.Down == "" {
+			t.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
+
+// testMigrations returns migrations 1 to 4 and the applied record of each
+func testMigrations() ([]Migration, []appliedMigration) {
+	var (
+		migrations []Migration
+		applied    []appliedMigration
+	)
+	for v := int64(1); v <= 4; v++ {
+		up := fmt.Sprintf("-- up %d", v)
+		migrations = append(migrations, Migration{Version: v, Name: "m", Up: up, Down: "-- down", Checksum: checksum(up)})
+		applied = append(applied, appliedMigration{version: v, checksum: checksum(up)})
+	}
+	return migrations, applied
+}
+
+func TestPendingMigrations(t *testing.T) {
+	migrations, all := testMigrations()
+
+	tests := []struct {
+		name    string
+		applied []appliedMigration
+		want    []int64
+		wantErr error
+	}{
+		{name: "fresh database", want: []int64{1, 2, 3, 4}},
+		{name: "up to date", applied: all},
+		{name: "new migrations", applied: all[:2], want: []int64{3, 4}},
+		{
+			name:    "checksum drift",
+			applied: []appliedMigration{all[0], {version: 2, checksum: checksum("-- edited")}},
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name:    "unknown migration",
+			applied: append(all[:4:4], appliedMigration{version: 5, checksum: checksum("-- up 5")}),
+			wantErr: ErrUnknownMigration,
+		},
+		{
+			name:    "gap in the applied set",
+			applied: []appliedMigration{all[0], all[2]},
+			wantErr: ErrOutOfOrder,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pendingMigrations(migrations, tt.applied)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("pendingMigrations() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(versions(got), tt.want) {
+				t.Errorf("pendingMigrations() = %v, want %v", versions(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestRollbackMigrations(t *testing.T) {
+	migrations, all := testMigrations()
+	noDown := append([]Migration(nil), migrations...)
+	noDown[1].Down = ""
+
+	tests := []struct {
+		name       string
+		migrations []Migration
+		applied    []appliedMigration
+		steps      int
+		want       []int64
+		wantErr    error
+	}{
+		{name: "latest first", migrations: migrations, applied: all[:3], steps: 2, want: []int64{3, 2}},
+		{name: "more steps than applied", migrations: migrations, applied: all[:2], steps: 5, want: []int64{2, 1}},
+		{name: "nothing applied", migrations: migrations, steps: 1},
+		{name: "missing down file", migrations: noDown, applied: all, steps: 3, wantErr: ErrNoDownMigration},
+		{name: "missing down file not reached", migrations: noDown, applied: all, steps: 2, want: []int64{4, 3}},
+		{
+			name:       "checksum drift",
+			migrations: migrations,
+			applied:    []appliedMigration{all[0], all[1], {version: 3, checksum: checksum("-- edited")}},
+			steps:      1,
+			wantErr:    ErrChecksumMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rollbackMigrations(tt.migrations, tt.applied, tt.steps)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("rollbackMigrations() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(versions(got), tt.want) {
+				t.Errorf("rollbackMigrations() = %v, want %v", versions(got), tt.want)
+			}
+		})
+	}
+}
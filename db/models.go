@@ -8,6 +8,134 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	ID         int64       `json:"id"`
+	Name       string      `json:"name"`
+	Prefix     string      `json:"prefix"`
+	KeyHash    string      `json:"key_hash"`
+	Superadmin bool        `json:"superadmin"`
+	ExpiresAt  pgtype.Int8 `json:"expires_at"`
+	LastUsedAt pgtype.Int8 `json:"last_used_at"`
+	Created    int64       `json:"created"`
+	Updated    int64       `json:"updated"`
+}
+
+type Chunk struct {
+	ID           string      `json:"id"`
+	WorkspaceID  int64       `json:"workspace_id"`
+	RepoID       int64       `json:"repo_id"`
+	FileID       int64       `json:"file_id"`
+	FilePath     string      `json:"file_path"`
+	Language     string      `json:"language"`
+	ChunkType    string      `json:"chunk_type"`
+	SymbolName   pgtype.Text `json:"symbol_name"`
+	Content      string      `json:"content"`
+	ContentHash  string      `json:"content_hash"`
+	ChunkIndex   int32       `json:"chunk_index"`
+	StartLine    int32       `json:"start_line"`
+	EndLine      int32       `json:"end_line"`
+	Created      int64       `json:"created"`
+	SearchVector interface{} `json:"search_vector"`
+}
+
+type EmbeddingCache struct {
+	ContentHash string `json:"content_hash"`
+	Model       string `json:"model"`
+	Embedding   []byte `json:"embedding"`
+	UseCount    int32  `json:"use_count"`
+	LastUsed    int64  `json:"last_used"`
+	Created     int64  `json:"created"`
+}
+
+type File struct {
+	ID          int64       `json:"id"`
+	RepoID      int64       `json:"repo_id"`
+	Path        string      `json:"path"`
+	ContentHash string      `json:"content_hash"`
+	Language    pgtype.Text `json:"language"`
+	SizeBytes   int64       `json:"size_bytes"`
+	LineCount   pgtype.Int4 `json:"line_count"`
+	ChunkCount  int32       `json:"chunk_count"`
+	IndexedAt   pgtype.Int8 `json:"indexed_at"`
+	Created     int64       `json:"created"`
+	Updated     int64       `json:"updated"`
+}
+
+type GitToken struct {
+	ID             int64       `json:"id"`
+	Name           string      `json:"name"`
+	Provider       string      `json:"provider"`
+	TokenEncrypted []byte      `json:"token_encrypted"`
+	KeyID          string      `json:"key_id"`
+	TokenHint      pgtype.Text `json:"token_hint"`
+	AadVersion     int16       `json:"aad_version"`
+	WorkspaceID    pgtype.Int8 `json:"workspace_id"`
+	Host           pgtype.Text `json:"host"`
+	Created        int64       `json:"created"`
+	Updated        int64       `json:"updated"`
+}
+
+type IndexRun struct {
+	ID             int64       `json:"id"`
+	RepoID         int64       `json:"repo_id"`
+	Status         string      `json:"status"`
+	ErrorMessage   pgtype.Text `json:"error_message"`
+	FromCommit     pgtype.Text `json:"from_commit"`
+	ToCommit       pgtype.Text `json:"to_commit"`
+	CommitMessage  pgtype.Text `json:"commit_message"`
+	Branch         pgtype.Text `json:"branch"`
+	CommitsBetween pgtype.Int4 `json:"commits_between"`
+	FilesTotal     int32       `json:"files_total"`
+	FilesAdded     int32       `json:"files_added"`
+	FilesChanged   int32       `json:"files_changed"`
+	FilesDeleted   int32       `json:"files_deleted"`
+	ChunksCreated  int32       `json:"chunks_created"`
+	CacheHits      int32       `json:"cache_hits"`
+	CacheMisses    int32       `json:"cache_misses"`
+	TokensUsed     int64       `json:"tokens_used"`
+	StartedAt      int64       `json:"started_at"`
+	CompletedAt    pgtype.Int8 `json:"completed_at"`
+	DurationMs     pgtype.Int8 `json:"duration_ms"`
+	Created        int64       `json:"created"`
+}
+
+type Job struct {
+	ID          int64       `json:"id"`
+	Kind        string      `json:"kind"`
+	Payload     []byte      `json:"payload"`
+	DedupeKey   pgtype.Text `json:"dedupe_key"`
+	Status      string      `json:"status"`
+	Attempts    int32       `json:"attempts"`
+	MaxAttempts int32       `json:"max_attempts"`
+	RunAfter    int64       `json:"run_after"`
+	LockedBy    pgtype.Text `json:"locked_by"`
+	LockedUntil pgtype.Int8 `json:"locked_until"`
+	LastError   pgtype.Text `json:"last_error"`
+	Created     int64       `json:"created"`
+	Updated     int64       `json:"updated"`
+}
+
+type Repo struct {
+	ID                int64       `json:"id"`
+	WorkspaceID       int64       `json:"workspace_id"`
+	GitTokenID        pgtype.Int8 `json:"git_token_id"`
+	Url               string      `json:"url"`
+	Provider          string      `json:"provider"`
+	Owner             string      `json:"owner"`
+	Name              string      `json:"name"`
+	Status            string      `json:"status"`
+	ErrorMessage      pgtype.Text `json:"error_message"`
+	DefaultBranch     pgtype.Text `json:"default_branch"`
+	HeadCommit        pgtype.Text `json:"head_commit"`
+	FileCount         int32       `json:"file_count"`
+	ChunkCount        int32       `json:"chunk_count"`
+	IndexedAt         pgtype.Int8 `json:"indexed_at"`
+	Created           int64       `json:"created"`
+	Updated           int64       `json:"updated"`
+	HeadCommitMessage pgtype.Text `json:"head_commit_message"`
+	IndexedCommit     pgtype.Text `json:"indexed_commit"`
+}
+
 type Workspace struct {
 	ID          int64       `json:"id"`
 	Name        string      `json:"name"`
@@ -17,3 +145,11 @@ type Workspace struct {
 	Created     int64       `json:"created"`
 	Updated     int64       `json:"updated"`
 }
+
+type WorkspaceMember struct {
+	WorkspaceID int64  `json:"workspace_id"`
+	ApiKeyID    int64  `json:"api_key_id"`
+	Role        string `json:"role"`
+	Created     int64  `json:"created"`
+	Updated     int64  `json:"updated"`
+}
@@ -6,16 +6,102 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddIndexRunStats(ctx context.Context, arg AddIndexRunStatsParams) error
+	ClaimJob(ctx context.Context, arg ClaimJobParams) (Job, error)
+	CompleteJob(ctx context.Context, arg CompleteJobParams) (int64, error)
+	CountAPIKeys(ctx context.Context) (int64, error)
+	CountCachedEmbeddings(ctx context.Context) (int64, error)
+	CountGitTokens(ctx context.Context, workspaceID pgtype.Int8) (int64, error)
+	CountIndexRunsByRepo(ctx context.Context, repoID int64) (int64, error)
+	CountReposByGitToken(ctx context.Context, gitTokenID pgtype.Int8) (int64, error)
+	CountReposByWorkspace(ctx context.Context, workspaceID int64) (int64, error)
+	CountWorkspaceMembers(ctx context.Context, workspaceID int64) (int64, error)
 	CountWorkspaces(ctx context.Context) (int64, error)
+	CountWorkspacesByIDs(ctx context.Context, ids []int64) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateGitToken(ctx context.Context, arg CreateGitTokenParams) (GitToken, error)
+	CreateIndexRun(ctx context.Context, arg CreateIndexRunParams) (IndexRun, error)
+	CreateRepo(ctx context.Context, arg CreateRepoParams) (Repo, error)
 	CreateWorkspace(ctx context.Context, arg CreateWorkspaceParams) (Workspace, error)
+	DeleteAPIKey(ctx context.Context, id int64) error
+	DeleteCachedEmbeddingsUnusedSince(ctx context.Context, lastUsed int64) (int64, error)
+	DeleteChunksByFileIDs(ctx context.Context, fileIds []int64) error
+	DeleteChunksByRepo(ctx context.Context, repoID int64) error
+	DeleteChunksByWorkspace(ctx context.Context, workspaceID int64) error
+	DeleteFileContentsByPaths(ctx context.Context, arg DeleteFileContentsByPathsParams) error
+	DeleteFileContentsByRepo(ctx context.Context, repoID int64) error
+	DeleteFileContentsByWorkspace(ctx context.Context, workspaceID int64) error
+	DeleteFilesByIDs(ctx context.Context, ids []int64) error
+	DeleteFilesByRepo(ctx context.Context, repoID int64) error
+	DeleteFilesByWorkspace(ctx context.Context, workspaceID int64) error
+	DeleteFinishedJobs(ctx context.Context, updated int64) (int64, error)
+	DeleteGitToken(ctx context.Context, id int64) error
+	DeleteGitTokensByWorkspace(ctx context.Context, workspaceID int64) error
+	DeleteIndexRunsByRepo(ctx context.Context, repoID int64) error
+	DeleteIndexRunsByWorkspace(ctx context.Context, workspaceID int64) error
+	DeleteLeastRecentlyUsedEmbeddings(ctx context.Context, limit int32) (int64, error)
+	DeleteRepo(ctx context.Context, id int64) error
+	DeleteReposByWorkspace(ctx context.Context, workspaceID int64) error
 	DeleteWorkspace(ctx context.Context, id int64) error
+	DeleteWorkspaceMember(ctx context.Context, arg DeleteWorkspaceMemberParams) error
+	DeleteWorkspaceMembersByAPIKey(ctx context.Context, apiKeyID int64) error
+	DeleteWorkspaceMembersByWorkspace(ctx context.Context, workspaceID int64) error
+	EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error)
+	FailJob(ctx context.Context, arg FailJobParams) (int64, error)
+	FailRunningIndexRunsByRepo(ctx context.Context, arg FailRunningIndexRunsByRepoParams) (int64, error)
+	FinishIndexRun(ctx context.Context, arg FinishIndexRunParams) (IndexRun, error)
+	GetAPIKeyByID(ctx context.Context, id int64) (ApiKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	GetCachedEmbeddings(ctx context.Context, arg GetCachedEmbeddingsParams) ([]GetCachedEmbeddingsRow, error)
+	GetGitTokenByID(ctx context.Context, id int64) (GitToken, error)
+	GetIndexRunByID(ctx context.Context, id int64) (IndexRun, error)
+	GetRepoByID(ctx context.Context, id int64) (Repo, error)
+	GetRepoByWorkspaceAndURL(ctx context.Context, arg GetRepoByWorkspaceAndURLParams) (Repo, error)
 	GetWorkspaceByID(ctx context.Context, id int64) (Workspace, error)
 	GetWorkspaceBySlug(ctx context.Context, slug string) (Workspace, error)
+	GetWorkspaceMember(ctx context.Context, arg GetWorkspaceMemberParams) (WorkspaceMember, error)
+	HeartbeatJob(ctx context.Context, arg HeartbeatJobParams) (int64, error)
+	ListAPIKeys(ctx context.Context, arg ListAPIKeysParams) ([]ApiKey, error)
+	ListFileContentHashes(ctx context.Context, repoID int64) ([]ListFileContentHashesRow, error)
+	ListFileContentsMatching(ctx context.Context, arg ListFileContentsMatchingParams) ([]ListFileContentsMatchingRow, error)
+	ListFilesByRepo(ctx context.Context, repoID int64) ([]ListFilesByRepoRow, error)
+	ListFilesByWorkspace(ctx context.Context, arg ListFilesByWorkspaceParams) ([]ListFilesByWorkspaceRow, error)
+	ListGitTokens(ctx context.Context, arg ListGitTokensParams) ([]GitToken, error)
+	ListGitTokensToReseal(ctx context.Context, arg ListGitTokensToResealParams) ([]GitToken, error)
+	ListIndexRunsByRepo(ctx context.Context, arg ListIndexRunsByRepoParams) ([]IndexRun, error)
+	ListIndexedRepoIDs(ctx context.Context, arg ListIndexedRepoIDsParams) ([]int64, error)
+	ListMembershipsByAPIKeys(ctx context.Context, apiKeyIds []int64) ([]WorkspaceMember, error)
+	ListRepoIDsByStatus(ctx context.Context, status string) ([]int64, error)
+	ListReposByWorkspace(ctx context.Context, arg ListReposByWorkspaceParams) ([]Repo, error)
+	ListWorkspaceMembers(ctx context.Context, arg ListWorkspaceMembersParams) ([]ListWorkspaceMembersRow, error)
 	ListWorkspaces(ctx context.Context, arg ListWorkspacesParams) ([]Workspace, error)
+	ListWorkspacesByIDs(ctx context.Context, arg ListWorkspacesByIDsParams) ([]Workspace, error)
+	NextGitTokenID(ctx context.Context) (int64, error)
+	NotifyRepoIndexed(ctx context.Context, payload string) error
+	PruneIndexRuns(ctx context.Context, arg PruneIndexRunsParams) (int64, error)
+	PutCachedEmbeddings(ctx context.Context, arg PutCachedEmbeddingsParams) error
+	ReleaseJob(ctx context.Context, arg ReleaseJobParams) (int64, error)
+	RetryJob(ctx context.Context, arg RetryJobParams) (int64, error)
+	SearchChunksFullText(ctx context.Context, arg SearchChunksFullTextParams) ([]SearchChunksFullTextRow, error)
+	SearchChunksTrigram(ctx context.Context, arg SearchChunksTrigramParams) ([]SearchChunksTrigramRow, error)
+	SetIndexRunSnapshot(ctx context.Context, arg SetIndexRunSnapshotParams) error
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error
+	TouchCachedEmbeddings(ctx context.Context, arg TouchCachedEmbeddingsParams) error
+	TransitionRepoStatus(ctx context.Context, arg TransitionRepoStatusParams) (Repo, error)
+	TryAdvisoryXactLock(ctx context.Context, key int64) (bool, error)
+	UpdateGitTokenCiphertext(ctx context.Context, arg UpdateGitTokenCiphertextParams) error
+	UpdateRepoHead(ctx context.Context, arg UpdateRepoHeadParams) (Repo, error)
+	UpdateRepoIndexStats(ctx context.Context, arg UpdateRepoIndexStatsParams) error
 	UpdateWorkspace(ctx context.Context, arg UpdateWorkspaceParams) (Workspace, error)
+	UpsertChunks(ctx context.Context, arg UpsertChunksParams) error
+	UpsertFileContents(ctx context.Context, arg UpsertFileContentsParams) error
+	UpsertFiles(ctx context.Context, arg UpsertFilesParams) ([]UpsertFilesRow, error)
+	UpsertWorkspaceMember(ctx context.Context, arg UpsertWorkspaceMemberParams) (WorkspaceMember, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,33 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, prefix, key_hash, superadmin, expires_at, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, name, prefix, key_hash, superadmin, expires_at, last_used_at, created, updated;
+
+-- name: GetAPIKeyByID :one
+SELECT id, name, prefix, key_hash, superadmin, expires_at, last_used_at, created, updated
+FROM api_keys
+WHERE id = $1;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT id, name, prefix, key_hash, superadmin, expires_at, last_used_at, created, updated
+FROM api_keys
+WHERE prefix = $1;
+
+-- name: ListAPIKeys :many
+SELECT id, name, prefix, key_hash, superadmin, expires_at, last_used_at, created, updated
+FROM api_keys
+ORDER BY created DESC, id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountAPIKeys :one
+SELECT COUNT(*)
+FROM api_keys;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id) AND (last_used_at IS NULL OR last_used_at < sqlc.arg(stale_before));
+
+-- name: DeleteAPIKey :exec
+DELETE FROM api_keys
+WHERE id = $1;
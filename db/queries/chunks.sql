@@ -0,0 +1,61 @@
+-- name: UpsertChunks :exec
+INSERT INTO chunks (id, workspace_id, repo_id, file_id, file_path, language,
+  chunk_type, symbol_name, content, content_hash, chunk_index, start_line, end_line, created)
+SELECT id, workspace_id, repo_id, file_id, file_path, language,
+  chunk_type, NULLIF(symbol_name, ''), content, content_hash, chunk_index, start_line, end_line, sqlc.arg(created)::bigint
+FROM unnest(
+  sqlc.arg(ids)::text[], sqlc.arg(workspace_ids)::bigint[], sqlc.arg(repo_ids)::bigint[], sqlc.arg(file_ids)::bigint[],
+  sqlc.arg(file_paths)::text[], sqlc.arg(languages)::text[], sqlc.arg(chunk_types)::text[], sqlc.arg(symbol_names)::text[],
+  sqlc.arg(contents)::text[], sqlc.arg(content_hashes)::text[], sqlc.arg(chunk_indexes)::int[],
+  sqlc.arg(start_lines)::int[], sqlc.arg(end_lines)::int[]
+) AS t(id, workspace_id, repo_id, file_id, file_path, language,
+  chunk_type, symbol_name, content, content_hash, chunk_index, start_line, end_line)
+ON CONFLICT (id) DO UPDATE SET
+  workspace_id = EXCLUDED.workspace_id,
+  repo_id = EXCLUDED.repo_id,
+  file_id = EXCLUDED.file_id,
+  file_path = EXCLUDED.file_path,
+  language = EXCLUDED.language,
+  chunk_type = EXCLUDED.chunk_type,
+  symbol_name = EXCLUDED.symbol_name,
+  content = EXCLUDED.content,
+  content_hash = EXCLUDED.content_hash,
+  chunk_index = EXCLUDED.chunk_index,
+  start_line = EXCLUDED.start_line,
+  end_line = EXCLUDED.end_line;
+
+-- name: DeleteChunksByRepo :exec
+DELETE FROM chunks
+WHERE repo_id = $1;
+
+-- name: DeleteChunksByWorkspace :exec
+DELETE FROM chunks
+WHERE workspace_id = $1;
+
+-- name: SearchChunksFullText :many
+SELECT id, repo_id, file_path, language, chunk_type, symbol_name, content, start_line, end_line,
+  ts_rank_cd(search_vector, tsq)::real AS score
+FROM chunks, to_tsquery('simple', sqlc.arg(terms)) AS tsq
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND search_vector @@ tsq
+  AND (COALESCE(cardinality(sqlc.arg(repo_ids)::bigint[]), 0) = 0 OR repo_id = ANY(sqlc.arg(repo_ids)::bigint[]))
+  AND (COALESCE(cardinality(sqlc.arg(languages)::text[]), 0) = 0 OR language = ANY(sqlc.arg(languages)::text[]))
+  AND (COALESCE(cardinality(sqlc.arg(chunk_types)::text[]), 0) = 0 OR chunk_type = ANY(sqlc.arg(chunk_types)::text[]))
+ORDER BY score DESC, id
+LIMIT sqlc.arg(limit);
+
+-- name: SearchChunksTrigram :many
+SELECT id, repo_id, file_path, language, chunk_type, symbol_name, content, start_line, end_line,
+  GREATEST(similarity(COALESCE(symbol_name, ''), sqlc.arg(query)), word_similarity(sqlc.arg(query), content))::real AS score
+FROM chunks
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND (symbol_name % sqlc.arg(query) OR content %> sqlc.arg(query))
+  AND (COALESCE(cardinality(sqlc.arg(repo_ids)::bigint[]), 0) = 0 OR repo_id = ANY(sqlc.arg(repo_ids)::bigint[]))
+  AND (COALESCE(cardinality(sqlc.arg(languages)::text[]), 0) = 0 OR language = ANY(sqlc.arg(languages)::text[]))
+  AND (COALESCE(cardinality(sqlc.arg(chunk_types)::text[]), 0) = 0 OR chunk_type = ANY(sqlc.arg(chunk_types)::text[]))
+ORDER BY score DESC, id
+LIMIT sqlc.arg(limit);
+
+-- name: DeleteChunksByFileIDs :exec
+DELETE FROM chunks
+WHERE file_id = ANY(sqlc.arg(file_ids)::bigint[]);
@@ -0,0 +1,36 @@
+-- name: GetCachedEmbeddings :many
+SELECT content_hash, embedding
+FROM embedding_cache
+WHERE model = sqlc.arg(model) AND content_hash = ANY(sqlc.arg(hashes)::text[]);
+
+-- name: TouchCachedEmbeddings :exec
+UPDATE embedding_cache
+SET use_count = use_count + 1,
+    last_used = sqlc.arg(last_used)
+WHERE model = sqlc.arg(model) AND content_hash = ANY(sqlc.arg(hashes)::text[]);
+
+-- name: PutCachedEmbeddings :exec
+INSERT INTO embedding_cache (content_hash, model, embedding, use_count, last_used, created)
+SELECT unnest(sqlc.arg(hashes)::text[]), sqlc.arg(model)::text, unnest(sqlc.arg(embeddings)::bytea[]), 1, sqlc.arg(now)::bigint, sqlc.arg(now)::bigint
+ON CONFLICT (content_hash, model) DO UPDATE
+SET use_count = embedding_cache.use_count + 1,
+    last_used = EXCLUDED.last_used;
+
+-- name: CountCachedEmbeddings :one
+SELECT COUNT(*) FROM embedding_cache;
+
+-- name: DeleteCachedEmbeddingsUnusedSince :execrows
+DELETE FROM embedding_cache
+WHERE last_used < $1;
+
+-- name: DeleteLeastRecentlyUsedEmbeddings :execrows
+DELETE FROM embedding_cache
+WHERE (content_hash, model) IN (
+  SELECT content_hash, model
+  FROM embedding_cache
+  ORDER BY last_used
+  LIMIT $1
+);
+
+-- name: TryAdvisoryXactLock :one
+SELECT pg_try_advisory_xact_lock(sqlc.arg(key));
@@ -0,0 +1,40 @@
+-- name: ListFileContentHashes :many
+SELECT path, content_hash
+FROM file_contents
+WHERE repo_id = $1;
+
+-- name: UpsertFileContents :exec
+INSERT INTO file_contents (repo_id, path, content_hash, content, updated)
+SELECT sqlc.arg(repo_id)::bigint, path, content_hash, content, sqlc.arg(now)::bigint
+FROM unnest(
+  sqlc.arg(paths)::text[], sqlc.arg(content_hashes)::text[], sqlc.arg(contents)::text[]
+) AS t(path, content_hash, content)
+ON CONFLICT (repo_id, path) DO UPDATE SET
+  content_hash = EXCLUDED.content_hash,
+  content = EXCLUDED.content,
+  updated = EXCLUDED.updated;
+
+-- name: DeleteFileContentsByPaths :exec
+DELETE FROM file_contents
+WHERE repo_id = sqlc.arg(repo_id) AND path = ANY(sqlc.arg(paths)::text[]);
+
+-- name: DeleteFileContentsByRepo :exec
+DELETE FROM file_contents
+WHERE repo_id = $1;
+
+-- name: DeleteFileContentsByWorkspace :exec
+DELETE FROM file_contents
+WHERE repo_id IN (SELECT id FROM repos WHERE workspace_id = $1);
+
+-- name: ListFileContentsMatching :many
+SELECT repo_id, path, content
+FROM file_contents
+WHERE repo_id IN (
+    SELECT id FROM repos
+    WHERE workspace_id = sqlc.arg(workspace_id)
+      AND (COALESCE(cardinality(sqlc.arg(repo_ids)::bigint[]), 0) = 0 OR id = ANY(sqlc.arg(repo_ids)::bigint[]))
+  )
+  AND content ILIKE ALL(sqlc.arg(patterns)::text[])
+  AND (repo_id, path) > (sqlc.arg(after_repo_id)::bigint, sqlc.arg(after_path)::text)
+ORDER BY repo_id, path
+LIMIT sqlc.arg(limit);
@@ -0,0 +1,41 @@
+-- name: ListFilesByRepo :many
+SELECT id, path, content_hash, chunk_count
+FROM files
+WHERE repo_id = $1;
+
+-- name: ListFilesByWorkspace :many
+SELECT f.id, f.repo_id, f.path, f.language, f.size_bytes
+FROM files f
+JOIN repos r ON r.id = f.repo_id
+WHERE r.workspace_id = sqlc.arg(workspace_id) AND f.id > sqlc.arg(after_id)
+ORDER BY f.id
+LIMIT sqlc.arg(limit);
+
+-- name: UpsertFiles :many
+INSERT INTO files (repo_id, path, content_hash, language, size_bytes, line_count, chunk_count, indexed_at, created, updated)
+SELECT sqlc.arg(repo_id)::bigint, path, content_hash, language, size_bytes, line_count, chunk_count, sqlc.arg(now)::bigint, sqlc.arg(now)::bigint, sqlc.arg(now)::bigint
+FROM unnest(
+  sqlc.arg(paths)::text[], sqlc.arg(content_hashes)::text[], sqlc.arg(languages)::text[],
+  sqlc.arg(size_bytes)::bigint[], sqlc.arg(line_counts)::int[], sqlc.arg(chunk_counts)::int[]
+) AS t(path, content_hash, language, size_bytes, line_count, chunk_count)
+ON CONFLICT (repo_id, path) DO UPDATE SET
+  content_hash = EXCLUDED.content_hash,
+  language = EXCLUDED.language,
+  size_bytes = EXCLUDED.size_bytes,
+  line_count = EXCLUDED.line_count,
+  chunk_count = EXCLUDED.chunk_count,
+  indexed_at = EXCLUDED.indexed_at,
+  updated = EXCLUDED.updated
+RETURNING id, path;
+
+-- name: DeleteFilesByIDs :exec
+DELETE FROM files
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: DeleteFilesByRepo :exec
+DELETE FROM files
+WHERE repo_id = $1;
+
+-- name: DeleteFilesByWorkspace :exec
+DELETE FROM files
+WHERE repo_id IN (SELECT id FROM repos WHERE workspace_id = $1);
@@ -0,0 +1,48 @@
+-- name: NextGitTokenID :one
+SELECT nextval(pg_get_serial_sequence('git_tokens', 'id'))::bigint AS id;
+
+-- name: CreateGitToken :one
+INSERT INTO git_tokens (id, name, provider, token_encrypted, key_id, token_hint, aad_version, workspace_id, host, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, name, provider, token_encrypted, key_id, token_hint, aad_version, workspace_id, host, created, updated;
+
+-- name: GetGitTokenByID :one
+SELECT id, name, provider, token_encrypted, key_id, token_hint, aad_version, workspace_id, host, created, updated
+FROM git_tokens
+WHERE id = $1;
+
+-- name: ListGitTokens :many
+SELECT id, name, provider, token_encrypted, key_id, token_hint, aad_version, workspace_id, host, created, updated
+FROM git_tokens
+WHERE workspace_id IS NOT DISTINCT FROM sqlc.arg(workspace_id)
+ORDER BY created DESC
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
+
+-- name: CountGitTokens :one
+SELECT COUNT(*)
+FROM git_tokens
+WHERE workspace_id IS NOT DISTINCT FROM sqlc.arg(workspace_id);
+
+-- name: ListGitTokensToReseal :many
+SELECT id, name, provider, token_encrypted, key_id, token_hint, aad_version, workspace_id, host, created, updated
+FROM git_tokens
+WHERE key_id <> $1 OR aad_version < $2
+ORDER BY id
+LIMIT $3
+FOR UPDATE;
+
+-- name: UpdateGitTokenCiphertext :exec
+UPDATE git_tokens
+SET token_encrypted = $2,
+    key_id = $3,
+    aad_version = $4,
+    updated = $5
+WHERE id = $1;
+
+-- name: DeleteGitToken :exec
+DELETE FROM git_tokens
+WHERE id = $1;
+
+-- name: DeleteGitTokensByWorkspace :exec
+DELETE FROM git_tokens
+WHERE workspace_id = $1;
@@ -0,0 +1,77 @@
+-- name: CreateIndexRun :one
+INSERT INTO index_runs (repo_id, status, from_commit, started_at, created)
+VALUES ($1, 'running', $2, $3, $4)
+RETURNING id, repo_id, status, error_message, from_commit, to_commit, commit_message, branch, commits_between, files_total, files_added, files_changed, files_deleted, chunks_created, cache_hits, cache_misses, tokens_used, started_at, completed_at, duration_ms, created;
+
+-- name: GetIndexRunByID :one
+SELECT id, repo_id, status, error_message, from_commit, to_commit, commit_message, branch, commits_between, files_total, files_added, files_changed, files_deleted, chunks_created, cache_hits, cache_misses, tokens_used, started_at, completed_at, duration_ms, created
+FROM index_runs
+WHERE id = $1;
+
+-- name: ListIndexRunsByRepo :many
+SELECT id, repo_id, status, error_message, from_commit, to_commit, commit_message, branch, commits_between, files_total, files_added, files_changed, files_deleted, chunks_created, cache_hits, cache_misses, tokens_used, started_at, completed_at, duration_ms, created
+FROM index_runs
+WHERE repo_id = $1
+ORDER BY created DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountIndexRunsByRepo :one
+SELECT COUNT(*) FROM index_runs
+WHERE repo_id = $1;
+
+-- name: SetIndexRunSnapshot :exec
+UPDATE index_runs
+SET to_commit = $2,
+    commit_message = $3,
+    branch = $4,
+    commits_between = $5
+WHERE id = $1;
+
+-- name: AddIndexRunStats :exec
+UPDATE index_runs
+SET files_total = files_total + sqlc.arg(files_total)::int,
+    files_added = files_added + sqlc.arg(files_added)::int,
+    files_changed = files_changed + sqlc.arg(files_changed)::int,
+    files_deleted = files_deleted + sqlc.arg(files_deleted)::int,
+    chunks_created = chunks_created + sqlc.arg(chunks_created)::int,
+    cache_hits = cache_hits + sqlc.arg(cache_hits)::int,
+    cache_misses = cache_misses + sqlc.arg(cache_misses)::int,
+    tokens_used = tokens_used + sqlc.arg(tokens_used)::bigint
+WHERE id = sqlc.arg(id);
+
+-- name: FinishIndexRun :one
+UPDATE index_runs
+SET status = sqlc.arg(status),
+    error_message = sqlc.arg(error_message),
+    completed_at = sqlc.arg(completed_at),
+    duration_ms = (sqlc.arg(completed_at) - started_at) / 1000000
+WHERE id = sqlc.arg(id) AND status = 'running'
+RETURNING id, repo_id, status, error_message, from_commit, to_commit, commit_message, branch, commits_between, files_total, files_added, files_changed, files_deleted, chunks_created, cache_hits, cache_misses, tokens_used, started_at, completed_at, duration_ms, created;
+
+-- name: FailRunningIndexRunsByRepo :execrows
+UPDATE index_runs
+SET status = 'failed',
+    error_message = sqlc.arg(error_message),
+    completed_at = sqlc.arg(completed_at),
+    duration_ms = (sqlc.arg(completed_at) - started_at) / 1000000
+WHERE repo_id = sqlc.arg(repo_id) AND status = 'running';
+
+-- name: PruneIndexRuns :execrows
+DELETE FROM index_runs
+WHERE repo_id = sqlc.arg(repo_id)
+  AND status <> 'running'
+  AND id NOT IN (
+    SELECT id
+    FROM index_runs
+    WHERE repo_id = sqlc.arg(repo_id)
+    ORDER BY created DESC, id DESC
+    LIMIT sqlc.arg(keep)
+  );
+
+-- name: DeleteIndexRunsByRepo :exec
+DELETE FROM index_runs
+WHERE repo_id = $1;
+
+-- name: DeleteIndexRunsByWorkspace :exec
+DELETE FROM index_runs
+WHERE repo_id IN (SELECT id FROM repos WHERE workspace_id = $1);
@@ -0,0 +1,76 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (kind, payload, dedupe_key, status, max_attempts, run_after, created, updated)
+VALUES ($1, $2, $3, 'queued', $4, $5, $6, $7)
+ON CONFLICT (kind, dedupe_key) WHERE status = 'queued' DO NOTHING
+RETURNING id, kind, payload, dedupe_key, status, attempts, max_attempts, run_after, locked_by, locked_until, last_error, created, updated;
+
+-- name: ClaimJob :one
+UPDATE jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_by = sqlc.arg(worker),
+    locked_until = sqlc.arg(locked_until),
+    updated = sqlc.arg(now)
+WHERE id = (
+  SELECT id
+  FROM jobs
+  WHERE (status = 'queued' AND run_after <= sqlc.arg(now))
+     OR (status = 'running' AND locked_until < sqlc.arg(now))
+  ORDER BY run_after, id
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, kind, payload, dedupe_key, status, attempts, max_attempts, run_after, locked_by, locked_until, last_error, created, updated;
+
+-- name: HeartbeatJob :execrows
+UPDATE jobs
+SET locked_until = $3,
+    updated = $4
+WHERE id = $1 AND locked_by = $2 AND status = 'running';
+
+-- name: CompleteJob :execrows
+UPDATE jobs
+SET status = 'completed',
+    locked_by = NULL,
+    locked_until = NULL,
+    last_error = NULL,
+    updated = $3
+WHERE id = $1 AND locked_by = $2 AND status = 'running';
+
+-- name: RetryJob :execrows
+UPDATE jobs
+SET status = CASE WHEN EXISTS (
+      SELECT 1 FROM jobs queued
+      WHERE queued.kind = jobs.kind AND queued.dedupe_key = jobs.dedupe_key AND queued.status = 'queued'
+    ) THEN 'completed' ELSE 'queued' END,
+    run_after = $3,
+    last_error = $4,
+    locked_by = NULL,
+    locked_until = NULL,
+    updated = $5
+WHERE id = $1 AND locked_by = $2 AND status = 'running';
+
+-- name: FailJob :execrows
+UPDATE jobs
+SET status = 'failed',
+    last_error = $3,
+    locked_by = NULL,
+    locked_until = NULL,
+    updated = $4
+WHERE id = $1 AND locked_by = $2 AND status = 'running';
+
+-- name: ReleaseJob :execrows
+UPDATE jobs
+SET status = CASE WHEN EXISTS (
+      SELECT 1 FROM jobs queued
+      WHERE queued.kind = jobs.kind AND queued.dedupe_key = jobs.dedupe_key AND queued.status = 'queued'
+    ) THEN 'completed' ELSE 'queued' END,
+    attempts = GREATEST(attempts - 1, 0),
+    locked_by = NULL,
+    locked_until = NULL,
+    updated = $3
+WHERE id = $1 AND locked_by = $2 AND status = 'running';
+
+-- name: DeleteFinishedJobs :execrows
+DELETE FROM jobs
+WHERE status IN ('completed', 'failed') AND updated < $1;
@@ -0,0 +1,83 @@
+-- name: CreateRepo :one
+INSERT INTO repos (workspace_id, git_token_id, url, provider, owner, name, status, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, workspace_id, git_token_id, url, provider, owner, name, status, error_message, default_branch, head_commit, file_count, chunk_count, indexed_at, created, updated, head_commit_message, indexed_commit;
+
+-- name: GetRepoByID :one
+SELECT id, workspace_id, git_token_id, url, provider, owner, name, status, error_message, default_branch, head_commit, file_count, chunk_count, indexed_at, created, updated, head_commit_message, indexed_commit
+FROM repos
+WHERE id = $1;
+
+-- name: GetRepoByWorkspaceAndURL :one
+SELECT id, workspace_id, git_token_id, url, provider, owner, name, status, error_message, default_branch, head_commit, file_count, chunk_count, indexed_at, created, updated, head_commit_message, indexed_commit
+FROM repos
+WHERE workspace_id = $1 AND url = $2;
+
+-- name: ListReposByWorkspace :many
+SELECT id, workspace_id, git_token_id, url, provider, owner, name, status, error_message, default_branch, head_commit, file_count, chunk_count, indexed_at, created, updated, head_commit_message, indexed_commit
+FROM repos
+WHERE workspace_id = $1
+ORDER BY name, id
+LIMIT $2 OFFSET $3;
+
+-- name: CountReposByWorkspace :one
+SELECT COUNT(*)
+FROM repos
+WHERE workspace_id = $1;
+
+-- name: ListIndexedRepoIDs :many
+SELECT id
+FROM repos
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND indexed_commit IS NOT NULL
+  AND (cardinality(sqlc.arg(repo_ids)::bigint[]) = 0 OR id = ANY(sqlc.arg(repo_ids)::bigint[]))
+ORDER BY id;
+
+-- name: TransitionRepoStatus :one
+UPDATE repos
+SET status = sqlc.arg(status),
+    error_message = sqlc.arg(error_message),
+    updated = sqlc.arg(updated)
+WHERE id = sqlc.arg(id)
+  AND status = ANY(sqlc.arg(from_statuses)::text[])
+RETURNING id, workspace_id, git_token_id, url, provider, owner, name, status, error_message, default_branch, head_commit, file_count, chunk_count, indexed_at, created, updated, head_commit_message, indexed_commit;
+
+-- name: DeleteRepo :exec
+DELETE FROM repos
+WHERE id = $1;
+
+-- name: DeleteReposByWorkspace :exec
+DELETE FROM repos
+WHERE workspace_id = $1;
+
+-- name: CountReposByGitToken :one
+SELECT COUNT(*)
+FROM repos
+WHERE git_token_id = $1;
+
+-- name: UpdateRepoHead :one
+UPDATE repos
+SET default_branch = $2,
+    head_commit = $3,
+    head_commit_message = $4,
+    updated = $5
+WHERE id = $1
+RETURNING id, workspace_id, git_token_id, url, provider, owner, name, status, error_message, default_branch, head_commit, file_count, chunk_count, indexed_at, created, updated, head_commit_message, indexed_commit;
+
+-- name: ListRepoIDsByStatus :many
+SELECT id
+FROM repos
+WHERE status = $1
+ORDER BY created;
+
+-- name: UpdateRepoIndexStats :exec
+UPDATE repos
+SET file_count = $2,
+    chunk_count = $3,
+    indexed_commit = $4,
+    indexed_at = $5,
+    updated = $6
+WHERE id = $1;
+
+-- name: NotifyRepoIndexed :exec
+SELECT pg_notify('repo_indexed', sqlc.arg(payload)::text);
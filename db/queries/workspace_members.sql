@@ -0,0 +1,42 @@
+-- name: UpsertWorkspaceMember :one
+INSERT INTO workspace_members (workspace_id, api_key_id, role, created, updated)
+VALUES (sqlc.arg(workspace_id), sqlc.arg(api_key_id), sqlc.arg(role), sqlc.arg(now), sqlc.arg(now))
+ON CONFLICT (workspace_id, api_key_id) DO UPDATE
+SET role = EXCLUDED.role, updated = EXCLUDED.updated
+RETURNING workspace_id, api_key_id, role, created, updated;
+
+-- name: GetWorkspaceMember :one
+SELECT workspace_id, api_key_id, role, created, updated
+FROM workspace_members
+WHERE workspace_id = $1 AND api_key_id = $2;
+
+-- name: ListWorkspaceMembers :many
+SELECT m.api_key_id, k.name, k.prefix, m.role, k.expires_at, k.last_used_at, m.created, m.updated
+FROM workspace_members m
+JOIN api_keys k ON k.id = m.api_key_id
+WHERE m.workspace_id = $1
+ORDER BY m.created, m.api_key_id
+LIMIT $2 OFFSET $3;
+
+-- name: CountWorkspaceMembers :one
+SELECT COUNT(*)
+FROM workspace_members
+WHERE workspace_id = $1;
+
+-- name: ListMembershipsByAPIKeys :many
+SELECT workspace_id, api_key_id, role, created, updated
+FROM workspace_members
+WHERE api_key_id = ANY(sqlc.arg(api_key_ids)::bigint[])
+ORDER BY api_key_id, workspace_id;
+
+-- name: DeleteWorkspaceMember :exec
+DELETE FROM workspace_members
+WHERE workspace_id = $1 AND api_key_id = $2;
+
+-- name: DeleteWorkspaceMembersByAPIKey :exec
+DELETE FROM workspace_members
+WHERE api_key_id = $1;
+
+-- name: DeleteWorkspaceMembersByWorkspace :exec
+DELETE FROM workspace_members
+WHERE workspace_id = $1;
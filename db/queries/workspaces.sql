@@ -36,3 +36,15 @@ RETURNING id, name, slug, description, settings, created, updated;
 -- name: DeleteWorkspace :exec
 DELETE FROM workspaces
 WHERE id = $1;
+
+-- name: ListWorkspacesByIDs :many
+SELECT id, name, slug, description, settings, created, updated
+FROM workspaces
+WHERE id = ANY(sqlc.arg(ids)::bigint[])
+ORDER BY created DESC
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
+
+-- name: CountWorkspacesByIDs :one
+SELECT COUNT(*)
+FROM workspaces
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
@@ -0,0 +1,22 @@
+CREATE TABLE repos (
+  id             BIGSERIAL PRIMARY KEY,
+  workspace_id   BIGINT NOT NULL,
+  git_token_id   BIGINT,           -- nullable
+  url            TEXT NOT NULL,
+  provider       TEXT NOT NULL,    -- github, gitlab, bitbucket, generic
+  owner          TEXT NOT NULL,
+  name           TEXT NOT NULL,
+  status         TEXT NOT NULL DEFAULT 'pending',  -- pending, cloning, indexing, completed, failed
+  error_message  TEXT,
+  default_branch TEXT,
+  head_commit    TEXT,
+  file_count     INT NOT NULL DEFAULT 0,
+  chunk_count    INT NOT NULL DEFAULT 0,
+  indexed_at     BIGINT,
+  created        BIGINT NOT NULL,  -- nanoseconds since epoch
+  updated        BIGINT NOT NULL,
+  UNIQUE (workspace_id, url)
+);
+
+CREATE INDEX idx_repos_workspace ON repos(workspace_id);
+CREATE INDEX idx_repos_queue ON repos(status, created) WHERE status = 'pending';
@@ -0,0 +1,13 @@
+CREATE TABLE git_tokens (
+  id              BIGSERIAL PRIMARY KEY,
+  name            TEXT NOT NULL,
+  provider        TEXT NOT NULL,    -- github, gitlab, bitbucket, generic
+  token_encrypted BYTEA NOT NULL,   -- AES-GCM, nonce-prefixed
+  key_id          TEXT NOT NULL,    -- key ring entry that sealed token_encrypted
+  token_hint      TEXT,             -- last 4 chars
+  created         BIGINT NOT NULL,  -- nanoseconds since epoch
+  updated         BIGINT NOT NULL
+);
+
+CREATE INDEX idx_git_tokens_provider ON git_tokens(provider);
+CREATE INDEX idx_git_tokens_key_id ON git_tokens(key_id);
@@ -0,0 +1 @@
+ALTER TABLE repos DROP COLUMN IF EXISTS head_commit_message;
@@ -0,0 +1 @@
+ALTER TABLE repos ADD COLUMN head_commit_message TEXT;
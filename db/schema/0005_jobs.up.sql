@@ -0,0 +1,20 @@
+CREATE TABLE jobs (
+  id           BIGSERIAL PRIMARY KEY,
+  kind         TEXT NOT NULL,                   -- index_repo, ...
+  payload      JSONB NOT NULL DEFAULT '{}',
+  dedupe_key   TEXT,                            -- at most one queued job per (kind, dedupe_key)
+  status       TEXT NOT NULL DEFAULT 'queued',  -- queued, running, completed, failed
+  attempts     INT NOT NULL DEFAULT 0,
+  max_attempts INT NOT NULL,
+  run_after    BIGINT NOT NULL,                 -- nanoseconds since epoch
+  locked_by    TEXT,                            -- worker holding the job
+  locked_until BIGINT,                          -- visibility timeout, extended by heartbeats
+  last_error   TEXT,
+  created      BIGINT NOT NULL,
+  updated      BIGINT NOT NULL
+);
+
+-- Claim: queued jobs that are due, and running jobs whose worker stopped heartbeating
+CREATE INDEX idx_jobs_ready ON jobs(run_after, id) WHERE status = 'queued';
+CREATE INDEX idx_jobs_expired ON jobs(locked_until) WHERE status = 'running';
+CREATE UNIQUE INDEX idx_jobs_dedupe ON jobs(kind, dedupe_key) WHERE status = 'queued';
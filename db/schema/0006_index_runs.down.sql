@@ -0,0 +1 @@
+DROP TABLE IF EXISTS index_runs;
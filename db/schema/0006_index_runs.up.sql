@@ -0,0 +1,25 @@
+CREATE TABLE index_runs (
+  id              BIGSERIAL PRIMARY KEY,
+  repo_id         BIGINT NOT NULL,
+  status          TEXT NOT NULL DEFAULT 'running',  -- running, completed, failed
+  error_message   TEXT,
+  from_commit     TEXT,                             -- previous HEAD (null on first index)
+  to_commit       TEXT,                             -- HEAD that was indexed
+  commit_message  TEXT,
+  branch          TEXT,
+  commits_between INT,                              -- from_commit..to_commit; null when shallow history doesn't reach from_commit
+  files_total     INT NOT NULL DEFAULT 0,
+  files_added     INT NOT NULL DEFAULT 0,
+  files_changed   INT NOT NULL DEFAULT 0,
+  files_deleted   INT NOT NULL DEFAULT 0,
+  chunks_created  INT NOT NULL DEFAULT 0,
+  cache_hits      INT NOT NULL DEFAULT 0,
+  cache_misses    INT NOT NULL DEFAULT 0,
+  tokens_used     BIGINT NOT NULL DEFAULT 0,
+  started_at      BIGINT NOT NULL,                  -- nanoseconds since epoch
+  completed_at    BIGINT,
+  duration_ms     BIGINT,
+  created         BIGINT NOT NULL
+);
+
+CREATE INDEX idx_runs_repo ON index_runs(repo_id, created DESC);
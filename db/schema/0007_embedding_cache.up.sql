@@ -0,0 +1,11 @@
+CREATE TABLE embedding_cache (
+  content_hash TEXT NOT NULL,    -- SHA-256 of the embedded input
+  model        TEXT NOT NULL,    -- vectors from different models are never interchangeable
+  embedding    BYTEA NOT NULL,   -- packed little-endian float32 array
+  use_count    INT NOT NULL DEFAULT 1,
+  last_used    BIGINT NOT NULL,  -- nanoseconds since epoch
+  created      BIGINT NOT NULL,
+  PRIMARY KEY (content_hash, model)
+);
+
+CREATE INDEX idx_cache_last_used ON embedding_cache(last_used);
@@ -0,0 +1,23 @@
+-- Chunks stored in Postgres. The pgvector backend adds the embedding column
+-- and its index at startup, once the extension and vector size are known,
+-- so deployments without pgvector can still apply this migration.
+CREATE TABLE chunks (
+  id           TEXT PRIMARY KEY,  -- point ID derived from (repo_id, file_path, chunk_index)
+  workspace_id BIGINT NOT NULL,
+  repo_id      BIGINT NOT NULL,
+  file_id      BIGINT NOT NULL DEFAULT 0,
+  file_path    TEXT NOT NULL,
+  language     TEXT NOT NULL,
+  chunk_type   TEXT NOT NULL,
+  symbol_name  TEXT,
+  content      TEXT NOT NULL,
+  content_hash TEXT NOT NULL,     -- SHA-256 of content
+  chunk_index  INT NOT NULL,
+  start_line   INT NOT NULL,
+  end_line     INT NOT NULL,
+  created      BIGINT NOT NULL
+);
+
+CREATE INDEX idx_chunks_workspace ON chunks(workspace_id);
+CREATE INDEX idx_chunks_repo ON chunks(repo_id, file_path);
+CREATE INDEX idx_chunks_file ON chunks(file_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_chunks_symbol_trgm;
+DROP INDEX IF EXISTS idx_chunks_content_trgm;
+DROP INDEX IF EXISTS idx_chunks_search;
+ALTER TABLE chunks DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS code_words(TEXT);
@@ -0,0 +1,20 @@
+-- Lexical indexes over chunks for hybrid search. Every vector backend writes
+-- chunk rows, so keyword search works regardless of where vectors live.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- code_words spells out identifiers for full-text search: punctuation becomes
+-- spaces, so "db.Tx1" yields "db" and "Tx1", and camelCase words are added
+-- split, so "ErrAlreadyExists" also yields "Err Already Exists"
+CREATE FUNCTION code_words(t TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE
+RETURN regexp_replace(t, '[^[:alnum:]]+', ' ', 'g') || ' ' ||
+  regexp_replace(regexp_replace(t, '([[:lower:][:digit:]])([[:upper:]])', '\1 \2', 'g'), '[^[:alnum:]]+', ' ', 'g');
+
+ALTER TABLE chunks ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+  setweight(to_tsvector('simple', code_words(COALESCE(symbol_name, ''))), 'A') ||
+  setweight(to_tsvector('simple', code_words(content)), 'B')
+) STORED;
+
+CREATE INDEX idx_chunks_search ON chunks USING GIN (search_vector);
+CREATE INDEX idx_chunks_content_trgm ON chunks USING GIN (content gin_trgm_ops);
+CREATE INDEX idx_chunks_symbol_trgm ON chunks USING GIN (symbol_name gin_trgm_ops);
@@ -0,0 +1,19 @@
+-- Indexed files and their content hashes, so re-indexing only touches files
+-- that changed since the last run
+CREATE TABLE files (
+  id           BIGSERIAL PRIMARY KEY,
+  repo_id      BIGINT NOT NULL,
+  path         TEXT NOT NULL,     -- slash-separated, relative to the checkout root
+  content_hash TEXT NOT NULL,     -- SHA-256 of the file; empty while its chunks are being replaced
+  language     TEXT,
+  size_bytes   BIGINT NOT NULL,
+  line_count   INT,
+  chunk_count  INT NOT NULL DEFAULT 0,
+  indexed_at   BIGINT,
+  created      BIGINT NOT NULL,
+  updated      BIGINT NOT NULL,
+  UNIQUE (repo_id, path)
+);
+
+CREATE INDEX idx_files_repo ON files(repo_id);
+CREATE INDEX idx_files_content_hash ON files(content_hash);
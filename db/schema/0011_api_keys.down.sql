@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS api_key_workspaces;
+DROP TABLE IF EXISTS api_keys;
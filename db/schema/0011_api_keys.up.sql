@@ -0,0 +1,22 @@
+-- API keys authenticate every request except the health check. Only a hash
+-- of each key is stored; the prefix identifies it in lists and logs.
+CREATE TABLE api_keys (
+  id           BIGSERIAL PRIMARY KEY,
+  name         TEXT NOT NULL,
+  prefix       TEXT NOT NULL UNIQUE,  -- sx_ and 12 hex chars, the start of the key
+  key_hash     TEXT NOT NULL,         -- hex SHA-256 of the whole key
+  admin        BOOLEAN NOT NULL DEFAULT FALSE,  -- every workspace, plus keys, git tokens and new workspaces
+  expires_at   BIGINT,                -- nanoseconds since epoch; NULL never expires
+  last_used_at BIGINT,                -- updated at most once a minute
+  created      BIGINT NOT NULL,
+  updated      BIGINT NOT NULL
+);
+
+-- Workspaces a non-admin key may access
+CREATE TABLE api_key_workspaces (
+  api_key_id   BIGINT NOT NULL,
+  workspace_id BIGINT NOT NULL,
+  PRIMARY KEY (api_key_id, workspace_id)
+);
+
+CREATE INDEX idx_api_key_workspaces_workspace ON api_key_workspaces(workspace_id);
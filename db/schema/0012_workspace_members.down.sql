@@ -0,0 +1,16 @@
+ALTER TABLE api_keys RENAME COLUMN superadmin TO admin;
+
+CREATE TABLE api_key_workspaces (
+  api_key_id   BIGINT NOT NULL,
+  workspace_id BIGINT NOT NULL,
+  PRIMARY KEY (api_key_id, workspace_id)
+);
+
+CREATE INDEX idx_api_key_workspaces_workspace ON api_key_workspaces(workspace_id);
+
+-- Every role becomes full access to the workspace
+INSERT INTO api_key_workspaces (api_key_id, workspace_id)
+SELECT api_key_id, workspace_id
+FROM workspace_members;
+
+DROP TABLE IF EXISTS workspace_members;
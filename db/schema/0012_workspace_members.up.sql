@@ -0,0 +1,22 @@
+-- Roles of API keys within workspaces, replacing the all-or-nothing grants.
+-- Admin keys become superadmins, the org-level role above every workspace.
+CREATE TABLE workspace_members (
+  workspace_id BIGINT NOT NULL,
+  api_key_id   BIGINT NOT NULL,
+  role         TEXT NOT NULL,  -- viewer, editor, admin
+  created      BIGINT NOT NULL,
+  updated      BIGINT NOT NULL,
+  PRIMARY KEY (workspace_id, api_key_id)
+);
+
+CREATE INDEX idx_workspace_members_api_key ON workspace_members(api_key_id);
+
+-- Grants gave full access to their workspace
+INSERT INTO workspace_members (workspace_id, api_key_id, role, created, updated)
+SELECT workspace_id, api_key_id, 'admin',
+       (EXTRACT(EPOCH FROM now()) * 1e9)::BIGINT, (EXTRACT(EPOCH FROM now()) * 1e9)::BIGINT
+FROM api_key_workspaces;
+
+DROP TABLE api_key_workspaces;
+
+ALTER TABLE api_keys RENAME COLUMN admin TO superadmin;
@@ -0,0 +1 @@
+ALTER TABLE git_tokens DROP COLUMN IF EXISTS aad_version;
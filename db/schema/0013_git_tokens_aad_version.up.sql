@@ -0,0 +1,3 @@
+-- Version 2 ciphertexts are bound to their row and key; version 1 only to the
+-- table. --rotate-keys re-seals version 1 tokens.
+ALTER TABLE git_tokens ADD COLUMN aad_version SMALLINT NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+-- The commit the index describes; head_commit is only what was last checked out
+ALTER TABLE repos ADD COLUMN indexed_commit TEXT;
+
+UPDATE repos SET indexed_commit = head_commit WHERE status = 'completed';
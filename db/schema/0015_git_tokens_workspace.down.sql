@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_git_tokens_workspace;
+ALTER TABLE git_tokens DROP COLUMN IF EXISTS workspace_id;
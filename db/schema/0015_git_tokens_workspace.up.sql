@@ -0,0 +1,6 @@
+-- Tokens belong to a workspace and are managed by its admins. Tokens added
+-- before this are shared: only superadmins manage them or attach them to a
+-- repository.
+ALTER TABLE git_tokens ADD COLUMN workspace_id BIGINT;
+
+CREATE INDEX idx_git_tokens_workspace ON git_tokens(workspace_id);
@@ -0,0 +1,21 @@
+-- Tokens are only sent to their own host. Existing tokens take the host of a
+-- repository using them over HTTPS, else their provider's public host. Generic
+-- tokens left without one can't be used until re-added.
+ALTER TABLE git_tokens ADD COLUMN host TEXT;
+
+UPDATE git_tokens t
+SET host = (
+  SELECT lower(substring(r.url from '^https://([^/]+)'))
+  FROM repos r
+  WHERE r.git_token_id = t.id AND r.url LIKE 'https://%'
+  ORDER BY r.id
+  LIMIT 1
+);
+
+UPDATE git_tokens
+SET host = CASE provider
+  WHEN 'github' THEN 'github.com'
+  WHEN 'gitlab' THEN 'gitlab.com'
+  WHEN 'bitbucket' THEN 'bitbucket.org'
+END
+WHERE host IS NULL;
@@ -0,0 +1,17 @@
+-- The text of every file in a repository's index, including files the
+-- chunker skips as generated, so grep can find candidates through a trigram
+-- index instead of scanning checkouts. Binary files aren't stored.
+CREATE TABLE file_contents (
+  repo_id      BIGINT NOT NULL,
+  path         TEXT NOT NULL,     -- slash-separated, relative to the checkout root
+  content_hash TEXT NOT NULL,     -- SHA-256 of content
+  content      TEXT NOT NULL,
+  updated      BIGINT NOT NULL,
+  PRIMARY KEY (repo_id, path)
+);
+
+CREATE INDEX idx_file_contents_trgm ON file_contents USING GIN (content gin_trgm_ops);
+
+-- Re-index finished repositories so grep covers them; unchanged files are
+-- not embedded again
+UPDATE repos SET status = 'pending' WHERE status IN ('completed', 'failed');
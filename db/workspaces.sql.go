@@ -23,6 +23,19 @@ func (q *Queries) CountWorkspaces(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countWorkspacesByIDs = `-- name: CountWorkspacesByIDs :one
+SELECT COUNT(*)
+FROM workspaces
+WHERE id = ANY($1::bigint[])
+`
+
+func (q *Queries) CountWorkspacesByIDs(ctx context.Context, ids []int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countWorkspacesByIDs, ids)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createWorkspace = `-- name: CreateWorkspace :one
 INSERT INTO workspaces (name, slug, description, settings, created, updated)
 VALUES ($1, $2, $3, $4, $5, $6)
@@ -152,6 +165,48 @@ func (q *Queries) ListWorkspaces(ctx context.Context, arg ListWorkspacesParams)
 	return items, nil
 }
 
+const listWorkspacesByIDs = `-- name: ListWorkspacesByIDs :many
+SELECT id, name, slug, description, settings, created, updated
+FROM workspaces
+WHERE id = ANY($1::bigint[])
+ORDER BY created DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListWorkspacesByIDsParams struct {
+	Ids    []int64 `json:"ids"`
+	Limit  int32   `json:"limit"`
+	Offset int32   `json:"offset"`
+}
+
+func (q *Queries) ListWorkspacesByIDs(ctx context.Context, arg ListWorkspacesByIDsParams) ([]Workspace, error) {
+	rows, err := q.db.Query(ctx, listWorkspacesByIDs, arg.Ids, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Workspace
+	for rows.Next() {
+		var i Workspace
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Slug,
+			&i.Description,
+			&i.Settings,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateWorkspace = `-- name: UpdateWorkspace :one
 UPDATE workspaces
 SET name = $2,
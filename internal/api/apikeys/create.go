@@ -0,0 +1,73 @@
+package apikeys
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/apikeys"
+	"github.com/gomantics/semantix/internal/domains/members"
+	"go.uber.org/zap"
+)
+
+// CreateRequest is the request body for creating an API key
+type CreateRequest struct {
+	Name       string               `json:"name"`
+	Superadmin bool                 `json:"superadmin"`
+	Workspaces []members.Membership `json:"workspaces"`
+	// ExpiresAt is in nanoseconds since epoch; omitted keys never expire
+	ExpiresAt *int64 `json:"expires_at"`
+}
+
+func (r CreateRequest) validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if len(r.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+	if r.Superadmin && len(r.Workspaces) > 0 {
+		return errors.New("superadmin keys hold every role in every workspace; omit workspaces")
+	}
+	for _, m := range r.Workspaces {
+		if !m.Role.Valid() {
+			return members.ErrInvalidRole
+		}
+	}
+	return nil
+}
+
+// CreateResponse is the created key with the key itself, which is never
+// shown again
+type CreateResponse struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+// Create handles POST /v1/apikeys
+func Create(c web.Context) error {
+	var req CreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+	if err := req.validate(); err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	k, key, err := domain.Create(c.Request().Context(), domain.CreateParams{
+		Name:       req.Name,
+		Superadmin: req.Superadmin,
+		Workspaces: req.Workspaces,
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrNoWorkspaces) || errors.Is(err, domain.ErrWorkspaceNotFound) ||
+			errors.Is(err, domain.ErrExpiresInPast) {
+			return c.BadRequest(err.Error())
+		}
+		c.L.Error("failed to create API key", zap.Error(err))
+		return c.InternalError("failed to create API key")
+	}
+
+	return c.Created(CreateResponse{APIKey: k, Key: key})
+}
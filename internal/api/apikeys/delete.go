@@ -0,0 +1,28 @@
+package apikeys
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/apikeys"
+	"go.uber.org/zap"
+)
+
+// Delete handles DELETE /v1/apikeys/:id
+func Delete(c web.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.NotFound(domain.ErrNotFound.Error())
+	}
+
+	if err := domain.Delete(c.Request().Context(), id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.NotFound(err.Error())
+		}
+		c.L.Error("failed to delete API key", zap.Error(err), zap.Int64("api_key_id", id))
+		return c.InternalError("failed to delete API key")
+	}
+
+	return c.NoContent()
+}
@@ -0,0 +1,35 @@
+package apikeys
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/apikeys"
+	"go.uber.org/zap"
+)
+
+// ListResponse is the response for listing API keys
+type ListResponse struct {
+	APIKeys []domain.APIKey `json:"api_keys"`
+	web.Pagination
+}
+
+// List handles GET /v1/apikeys
+func List(c web.Context) error {
+	page, err := c.Page()
+	if err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	result, err := domain.List(c.Request().Context(), domain.ListParams{
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	})
+	if err != nil {
+		c.L.Error("failed to list API keys", zap.Error(err))
+		return c.InternalError("failed to list API keys")
+	}
+
+	return c.OK(ListResponse{
+		APIKeys:    result.APIKeys,
+		Pagination: page.Paginate(result.Total),
+	})
+}
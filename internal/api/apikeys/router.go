@@ -0,0 +1,15 @@
+package apikeys
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Configure sets up the API key routes, which only superadmin keys may use
+func Configure(e *echo.Echo, l *zap.Logger) {
+	g := e.Group("/v1/apikeys", web.RequireSuperadmin(l))
+	g.GET("", web.Wrap(List, l))
+	g.POST("", web.Wrap(Create, l))
+	g.DELETE("/:id", web.Wrap(Delete, l))
+}
@@ -0,0 +1,63 @@
+package gittokens
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/gittokens"
+	"github.com/gomantics/semantix/pkg/secrets"
+	"go.uber.org/zap"
+)
+
+// CreateRequest is the request body for adding a git token
+type CreateRequest struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	// Host defaults to the provider's public host
+	Host  string        `json:"host"`
+	Token secrets.Value `json:"token"`
+}
+
+func (r CreateRequest) validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if len(r.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+	if strings.TrimSpace(r.Token.Reveal()) == "" {
+		return errors.New("token is required")
+	}
+	return nil
+}
+
+// Create handles POST /v1/gittokens and POST /v1/workspaces/:wid/gittokens
+func Create(c web.Context) error {
+	var req CreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+	if err := req.validate(); err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	token, err := domain.Create(c.Request().Context(), domain.CreateParams{
+		WorkspaceID: scope(c),
+		Name:        req.Name,
+		Provider:    req.Provider,
+		Host:        req.Host,
+		Token:       req.Token,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidProvider), errors.Is(err, domain.ErrInvalidHost),
+			errors.Is(err, domain.ErrHostRequired):
+			return c.BadRequest(err.Error())
+		}
+		c.L.Error("failed to create git token", zap.Error(err))
+		return c.InternalError("failed to create git token")
+	}
+
+	return c.Created(token)
+}
@@ -0,0 +1,32 @@
+package gittokens
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/gittokens"
+	"go.uber.org/zap"
+)
+
+// Delete handles DELETE /v1/gittokens/:id and DELETE /v1/workspaces/:wid/gittokens/:id
+func Delete(c web.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.NotFound(domain.ErrNotFound.Error())
+	}
+
+	err = domain.Delete(c.Request().Context(), scope(c), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return c.NotFound(err.Error())
+		case errors.Is(err, domain.ErrInUse):
+			return c.Conflict(err.Error())
+		}
+		c.L.Error("failed to delete git token", zap.Error(err), zap.Int64("git_token_id", id))
+		return c.InternalError("failed to delete git token")
+	}
+
+	return c.NoContent()
+}
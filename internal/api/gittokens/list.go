@@ -0,0 +1,36 @@
+package gittokens
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/gittokens"
+	"go.uber.org/zap"
+)
+
+// ListResponse is the response for listing git tokens
+type ListResponse struct {
+	GitTokens []domain.GitToken `json:"git_tokens"`
+	web.Pagination
+}
+
+// List handles GET /v1/gittokens and GET /v1/workspaces/:wid/gittokens
+func List(c web.Context) error {
+	page, err := c.Page()
+	if err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	result, err := domain.List(c.Request().Context(), domain.ListParams{
+		WorkspaceID: scope(c),
+		Limit:       page.Limit,
+		Offset:      page.Offset,
+	})
+	if err != nil {
+		c.L.Error("failed to list git tokens", zap.Error(err))
+		return c.InternalError("failed to list git tokens")
+	}
+
+	return c.OK(ListResponse{
+		GitTokens:  result.GitTokens,
+		Pagination: page.Paginate(result.Total),
+	})
+}
@@ -0,0 +1,33 @@
+package gittokens
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/members"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Configure sets up the git token routes. Workspace admins manage their
+// workspace's tokens; the shared tokens under /v1/gittokens are for
+// superadmins only.
+func Configure(e *echo.Echo, l *zap.Logger) {
+	shared := e.Group("/v1/gittokens", web.RequireSuperadmin(l))
+	route(shared, l)
+
+	ws := e.Group("/v1/workspaces/:wid/gittokens", web.RequireWorkspace(l), web.RequireRole(l, members.RoleAdmin))
+	route(ws, l)
+}
+
+func route(g *echo.Group, l *zap.Logger) {
+	g.GET("", web.Wrap(List, l))
+	g.POST("", web.Wrap(Create, l))
+	g.DELETE("/:id", web.Wrap(Delete, l))
+}
+
+// scope returns the workspace the tokens belong to, or nil for shared tokens
+func scope(c web.Context) *int64 {
+	if ws := c.Workspace(); ws != nil {
+		return &ws.ID
+	}
+	return nil
+}
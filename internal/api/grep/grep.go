@@ -0,0 +1,72 @@
+package grep
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/grep"
+	"go.uber.org/zap"
+)
+
+// GrepRequest is the request body for an exact or regex search
+type GrepRequest struct {
+	Pattern      string      `json:"pattern"`
+	Mode         domain.Mode `json:"mode"`
+	IgnoreCase   bool        `json:"ignore_case"`
+	RepoIDs      []int64     `json:"repo_ids"`
+	Include      []string    `json:"include"`
+	Exclude      []string    `json:"exclude"`
+	ContextLines int         `json:"context_lines"`
+	MaxMatches   int         `json:"max_matches"`
+}
+
+// GrepResponse is the response for an exact or regex search
+type GrepResponse struct {
+	Matches       []domain.Match `json:"matches"`
+	FilesSearched int            `json:"files_searched"`
+	// Truncated names the limit that stopped the search, if any
+	Truncated domain.Limit `json:"truncated,omitempty"`
+}
+
+// Grep handles POST /v1/workspaces/:wid/grep
+func Grep(c web.Context) error {
+	var req GrepRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+	if req.MaxMatches == 0 {
+		req.MaxMatches = domain.DefaultMaxMatches
+	}
+	if req.MaxMatches < 1 || req.MaxMatches > domain.MaxMatches {
+		return c.BadRequest(fmt.Sprintf("max_matches must be between 1 and %d", domain.MaxMatches))
+	}
+
+	result, err := domain.Grep(c.Request().Context(), domain.Params{
+		WorkspaceID:  c.Workspace().ID,
+		Pattern:      req.Pattern,
+		Mode:         req.Mode,
+		IgnoreCase:   req.IgnoreCase,
+		RepoIDs:      req.RepoIDs,
+		Include:      req.Include,
+		Exclude:      req.Exclude,
+		ContextLines: req.ContextLines,
+		MaxMatches:   req.MaxMatches,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmptyPattern), errors.Is(err, domain.ErrPatternTooLong),
+			errors.Is(err, domain.ErrInvalidPattern), errors.Is(err, domain.ErrInvalidMode),
+			errors.Is(err, domain.ErrInvalidGlob), errors.Is(err, domain.ErrInvalidContext):
+			return c.BadRequest(err.Error())
+		}
+		c.L.Error("failed to grep", zap.Error(err))
+		return c.InternalError("failed to grep")
+	}
+
+	return c.OK(GrepResponse{
+		Matches:       result.Matches,
+		FilesSearched: result.FilesSearched,
+		Truncated:     result.Truncated,
+	})
+}
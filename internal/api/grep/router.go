@@ -0,0 +1,14 @@
+package grep
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/members"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Configure sets up the workspace-scoped grep routes
+func Configure(e *echo.Echo, l *zap.Logger) {
+	g := e.Group("/v1/workspaces/:wid/grep", web.RequireWorkspace(l), web.RequireRole(l, members.RoleViewer))
+	g.POST("", web.Wrap(Grep, l))
+}
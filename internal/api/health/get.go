@@ -2,13 +2,15 @@ package health
 
 import (
 	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/vectordb"
 	"github.com/gomantics/semantix/db"
 )
 
 // GetResponse is the health check response
 type GetResponse struct {
-	Status   string `json:"status"`
-	Database string `json:"database"`
+	Status      string `json:"status"`
+	Database    string `json:"database"`
+	VectorStore string `json:"vector_store"`
 }
 
 // Get handles GET /v1/health
@@ -24,8 +26,15 @@ func Get(c web.Context) error {
 		dbStatus = "error: " + err.Error()
 	}
 
+	// Check vector store
+	vectorStatus := "ok"
+	if err := vectordb.Default().Health(ctx); err != nil {
+		vectorStatus = "error: " + err.Error()
+	}
+
 	return c.OK(GetResponse{
-		Status:   "ok",
-		Database: dbStatus,
+		Status:      "ok",
+		Database:    dbStatus,
+		VectorStore: vectorStatus,
 	})
 }
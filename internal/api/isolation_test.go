@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/apikeys"
+	"github.com/gomantics/semantix/internal/domains/members"
+	"github.com/gomantics/semantix/internal/domains/repos"
+	"github.com/gomantics/semantix/internal/testdb"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// TestWorkspaceIsolation sends requests through the real middleware and
+// routes with keys holding each role in workspace A only
+func TestWorkspaceIsolation(t *testing.T) {
+	testdb.Connect(t)
+	ctx := context.Background()
+	l := zap.NewNop()
+
+	e := echo.New()
+	e.Use(web.Authenticate(l, "/v1/health"))
+	configureRoutes(e, l)
+
+	a, b := testdb.Workspace(t), testdb.Workspace(t)
+	repoA, err := repos.Create(ctx, repos.CreateParams{WorkspaceID: a.ID, URL: "https://github.com/acme/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repoB, err := repos.Create(ctx, repos.CreateParams{WorkspaceID: b.ID, URL: "https://github.com/acme/b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[members.Role]string{}
+	for _, role := range []members.Role{members.RoleViewer, members.RoleEditor, members.RoleAdmin} {
+		k, secret, err := apikeys.Create(ctx, apikeys.CreateParams{
+			Name:       string(role),
+			Workspaces: []members.Membership{{WorkspaceID: a.ID, Role: role}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = apikeys.Delete(ctx, k.ID) })
+		keys[role] = secret
+	}
+
+	do := func(key, method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+key)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("other workspace is not found", func(t *testing.T) {
+		for _, path := range []string{
+			fmt.Sprintf("/v1/workspaces/%d", b.ID),
+			fmt.Sprintf("/v1/workspaces/%s/repos", b.Slug),
+			fmt.Sprintf("/v1/workspaces/%d/repos", b.ID),
+			fmt.Sprintf("/v1/workspaces/%d/repos/%d", b.ID, repoB.ID),
+			fmt.Sprintf("/v1/workspaces/%d/members", b.ID),
+			fmt.Sprintf("/v1/workspaces/%d/gittokens", b.ID),
+		} {
+			if got := do(keys[members.RoleAdmin], http.MethodGet, path, ""); got != http.StatusNotFound {
+				t.Errorf("GET %s = %d, want 404", path, got)
+			}
+		}
+		for _, path := range []string{
+			fmt.Sprintf("/v1/workspaces/%d/search", b.ID),
+			fmt.Sprintf("/v1/workspaces/%d/search/hybrid", b.ID),
+			fmt.Sprintf("/v1/workspaces/%d/grep", b.ID),
+			fmt.Sprintf("/v1/workspaces/%d/mcp", b.ID),
+		} {
+			if got := do(keys[members.RoleAdmin], http.MethodPost, path, `{}`); got != http.StatusNotFound {
+				t.Errorf("POST %s = %d, want 404", path, got)
+			}
+		}
+	})
+
+	t.Run("other workspace's repository is not found", func(t *testing.T) {
+		path := fmt.Sprintf("/v1/workspaces/%d/repos/%d", a.ID, repoB.ID)
+		if got := do(keys[members.RoleAdmin], http.MethodGet, path, ""); got != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, got)
+		}
+	})
+
+	t.Run("viewer cannot write", func(t *testing.T) {
+		viewer := keys[members.RoleViewer]
+		if got := do(viewer, http.MethodGet, fmt.Sprintf("/v1/workspaces/%d/repos", a.ID), ""); got != http.StatusOK {
+			t.Errorf("viewer listing repositories = %d, want 200", got)
+		}
+		for _, r := range []struct{ method, path string }{
+			{http.MethodPost, fmt.Sprintf("/v1/workspaces/%d/repos", a.ID)},
+			{http.MethodDelete, fmt.Sprintf("/v1/workspaces/%d/repos/%d", a.ID, repoA.ID)},
+			{http.MethodPost, fmt.Sprintf("/v1/workspaces/%d/repos/%d/reindex", a.ID, repoA.ID)},
+			{http.MethodPatch, fmt.Sprintf("/v1/workspaces/%d", a.ID)},
+		} {
+			if got := do(viewer, r.method, r.path, `{}`); got != http.StatusForbidden {
+				t.Errorf("viewer %s %s = %d, want 403", r.method, r.path, got)
+			}
+		}
+	})
+
+	t.Run("editor cannot manage members", func(t *testing.T) {
+		editor := keys[members.RoleEditor]
+		for _, r := range []struct{ method, path string }{
+			{http.MethodGet, fmt.Sprintf("/v1/workspaces/%d/members", a.ID)},
+			{http.MethodPost, fmt.Sprintf("/v1/workspaces/%d/members", a.ID)},
+			{http.MethodPut, fmt.Sprintf("/v1/workspaces/%d/members/1", a.ID)},
+			{http.MethodDelete, fmt.Sprintf("/v1/workspaces/%d/members/1", a.ID)},
+			{http.MethodGet, fmt.Sprintf("/v1/workspaces/%d/gittokens", a.ID)},
+			{http.MethodDelete, fmt.Sprintf("/v1/workspaces/%d", a.ID)},
+		} {
+			if got := do(editor, r.method, r.path, `{}`); got != http.StatusForbidden {
+				t.Errorf("editor %s %s = %d, want 403", r.method, r.path, got)
+			}
+		}
+		if got := do(keys[members.RoleAdmin], http.MethodGet, fmt.Sprintf("/v1/workspaces/%d/members", a.ID), ""); got != http.StatusOK {
+			t.Errorf("admin listing members = %d, want 200", got)
+		}
+	})
+
+	t.Run("local repositories", func(t *testing.T) {
+		cloneDir := t.TempDir()
+		t.Setenv("CONFIG_INDEXING_ALLOW_LOCAL_REPOS", "true")
+		t.Setenv("CONFIG_INDEXING_CLONE_DIR", cloneDir)
+
+		// Another workspace's checkout, and a link to it outside the clone dir
+		checkout := repos.CheckoutDir(b.ID, repoB.ID)
+		if err := os.MkdirAll(checkout, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		link := filepath.Join(t.TempDir(), "mirror")
+		if err := os.Symlink(checkout, link); err != nil {
+			t.Fatal(err)
+		}
+		mirror := t.TempDir()
+
+		k, superadmin, err := apikeys.Create(ctx, apikeys.CreateParams{Name: "superadmin", Superadmin: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = apikeys.Delete(ctx, k.ID) })
+
+		path := fmt.Sprintf("/v1/workspaces/%d/repos", a.ID)
+		body := func(url string) string { return fmt.Sprintf(`{"url": %q}`, url) }
+		if got := do(keys[members.RoleAdmin], http.MethodPost, path, body(mirror)); got != http.StatusForbidden {
+			t.Errorf("admin adding a local repository = %d, want 403", got)
+		}
+		for _, url := range []string{checkout, "file://" + checkout, link, cloneDir} {
+			if got := do(superadmin, http.MethodPost, path, body(url)); got != http.StatusBadRequest {
+				t.Errorf("superadmin adding %s = %d, want 400", url, got)
+			}
+		}
+		if got := do(superadmin, http.MethodPost, path, body(mirror)); got != http.StatusCreated {
+			t.Errorf("superadmin adding a local repository = %d, want 201", got)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package mcp
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	server "github.com/gomantics/semantix/internal/mcp"
+)
+
+type handler struct {
+	t *server.HTTPTransport
+}
+
+// Serve handles POST, GET and DELETE /v1/workspaces/:wid/mcp, the MCP
+// Streamable HTTP transport. The transport writes its own responses.
+func (h *handler) Serve(c web.Context) error {
+	h.t.Serve(c.Response(), c.Request(), c.Workspace(), c.Principal().ID)
+	return nil
+}
@@ -0,0 +1,23 @@
+package mcp
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/members"
+	server "github.com/gomantics/semantix/internal/mcp"
+	"github.com/gomantics/semantix/config"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Configure sets up the workspace-scoped MCP endpoint. Its tools only read,
+// so clients authenticate with a key holding the workspace's viewer role.
+func Configure(e *echo.Echo, l *zap.Logger) {
+	h := &handler{t: server.NewHTTPTransport(l.Named("mcp"), server.HTTPOptions{
+		AllowedOrigins: config.Server.CorsAllowedOrigins(),
+	})}
+
+	g := e.Group("/v1/workspaces/:wid/mcp", web.RequireWorkspace(l), web.RequireRole(l, members.RoleViewer))
+	g.POST("", web.Wrap(h.Serve, l))
+	g.GET("", web.Wrap(h.Serve, l))
+	g.DELETE("", web.Wrap(h.Serve, l))
+}
@@ -0,0 +1,66 @@
+package members
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/apikeys"
+	domain "github.com/gomantics/semantix/internal/domains/members"
+	"go.uber.org/zap"
+)
+
+// CreateRequest is the request body for issuing a key that's a member of
+// the workspace only
+type CreateRequest struct {
+	Name string      `json:"name"`
+	Role domain.Role `json:"role"`
+	// ExpiresAt is in nanoseconds since epoch; omitted keys never expire
+	ExpiresAt *int64 `json:"expires_at"`
+}
+
+func (r CreateRequest) validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if len(r.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+	if !r.Role.Valid() {
+		return domain.ErrInvalidRole
+	}
+	return nil
+}
+
+// CreateResponse is the new key with the key itself, which is never shown
+// again
+type CreateResponse struct {
+	*apikeys.APIKey
+	Key string `json:"key"`
+}
+
+// Create handles POST /v1/workspaces/:wid/members
+func Create(c web.Context) error {
+	var req CreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+	if err := req.validate(); err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	k, key, err := apikeys.Create(c.Request().Context(), apikeys.CreateParams{
+		Name:       req.Name,
+		Workspaces: []domain.Membership{{WorkspaceID: c.Workspace().ID, Role: req.Role}},
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, apikeys.ErrExpiresInPast) {
+			return c.BadRequest(err.Error())
+		}
+		c.L.Error("failed to create API key", zap.Error(err))
+		return c.InternalError("failed to create API key")
+	}
+
+	return c.Created(CreateResponse{APIKey: k, Key: key})
+}
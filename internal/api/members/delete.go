@@ -0,0 +1,28 @@
+package members
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/members"
+	"go.uber.org/zap"
+)
+
+// Delete handles DELETE /v1/workspaces/:wid/members/:kid
+func Delete(c web.Context) error {
+	id, err := strconv.ParseInt(c.Param("kid"), 10, 64)
+	if err != nil {
+		return c.NotFound(domain.ErrNotFound.Error())
+	}
+
+	if err := domain.Remove(c.Request().Context(), c.Workspace().ID, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.NotFound(err.Error())
+		}
+		c.L.Error("failed to remove member", zap.Error(err), zap.Int64("api_key_id", id))
+		return c.InternalError("failed to remove member")
+	}
+
+	return c.NoContent()
+}
@@ -0,0 +1,36 @@
+package members
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/members"
+	"go.uber.org/zap"
+)
+
+// ListResponse is the response for listing a workspace's members
+type ListResponse struct {
+	Members []domain.Member `json:"members"`
+	web.Pagination
+}
+
+// List handles GET /v1/workspaces/:wid/members
+func List(c web.Context) error {
+	page, err := c.Page()
+	if err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	result, err := domain.List(c.Request().Context(), domain.ListParams{
+		WorkspaceID: c.Workspace().ID,
+		Limit:       page.Limit,
+		Offset:      page.Offset,
+	})
+	if err != nil {
+		c.L.Error("failed to list members", zap.Error(err))
+		return c.InternalError("failed to list members")
+	}
+
+	return c.OK(ListResponse{
+		Members:    result.Members,
+		Pagination: page.Paginate(result.Total),
+	})
+}
@@ -0,0 +1,18 @@
+package members
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/members"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Configure sets up the workspace-scoped member routes, which take the
+// workspace's admin role
+func Configure(e *echo.Echo, l *zap.Logger) {
+	g := e.Group("/v1/workspaces/:wid/members", web.RequireWorkspace(l), web.RequireRole(l, domain.RoleAdmin))
+	g.GET("", web.Wrap(List, l))
+	g.POST("", web.Wrap(Create, l))
+	g.PUT("/:kid", web.Wrap(Set, l))
+	g.DELETE("/:kid", web.Wrap(Delete, l))
+}
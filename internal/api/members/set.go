@@ -0,0 +1,43 @@
+package members
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/members"
+	"go.uber.org/zap"
+)
+
+// SetRequest is the request body for setting a key's role
+type SetRequest struct {
+	Role domain.Role `json:"role"`
+}
+
+// Set handles PUT /v1/workspaces/:wid/members/:kid, changing a member's role.
+// Only superadmins may add keys that aren't members yet.
+func Set(c web.Context) error {
+	id, err := strconv.ParseInt(c.Param("kid"), 10, 64)
+	if err != nil {
+		return c.NotFound(domain.ErrAPIKeyNotFound.Error())
+	}
+
+	var req SetRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+	if !req.Role.Valid() {
+		return c.BadRequest(domain.ErrInvalidRole.Error())
+	}
+
+	m, err := domain.Set(c.Request().Context(), c.Workspace().ID, id, req.Role, c.Superadmin())
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return c.NotFound(err.Error())
+		}
+		c.L.Error("failed to set member role", zap.Error(err), zap.Int64("api_key_id", id))
+		return c.InternalError("failed to set member role")
+	}
+
+	return c.OK(m)
+}
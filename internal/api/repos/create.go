@@ -0,0 +1,46 @@
+package repos
+
+import (
+	"errors"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/repos"
+	"go.uber.org/zap"
+)
+
+// CreateRequest is the request body for adding a repository
+type CreateRequest struct {
+	URL        string `json:"url"`
+	GitTokenID *int64 `json:"git_token_id"`
+}
+
+// Create handles POST /v1/workspaces/:wid/repos
+func Create(c web.Context) error {
+	var req CreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+
+	repo, err := domain.Create(c.Request().Context(), domain.CreateParams{
+		WorkspaceID:      c.Workspace().ID,
+		URL:              req.URL,
+		GitTokenID:       req.GitTokenID,
+		AllowSharedToken: c.Superadmin(),
+		AllowLocal:       c.Superadmin(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidURL), errors.Is(err, domain.ErrGitTokenNotFound),
+			errors.Is(err, domain.ErrGitTokenHost):
+			return c.BadRequest(err.Error())
+		case errors.Is(err, domain.ErrLocalForbidden):
+			return c.Forbidden(err.Error())
+		case errors.Is(err, domain.ErrAlreadyExists):
+			return c.Conflict(err.Error())
+		}
+		c.L.Error("failed to create repository", zap.Error(err))
+		return c.InternalError("failed to create repository")
+	}
+
+	return c.Created(repo)
+}
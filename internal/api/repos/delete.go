@@ -0,0 +1,25 @@
+package repos
+
+import (
+	"errors"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/repos"
+	"go.uber.org/zap"
+)
+
+// Delete handles DELETE /v1/workspaces/:wid/repos/:rid
+func Delete(c web.Context) error {
+	repo := c.Repo()
+
+	err := domain.Delete(c.Request().Context(), repo.WorkspaceID, repo.ID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.NotFound(err.Error())
+		}
+		c.L.Error("failed to delete repository", zap.Error(err), zap.Int64("repo_id", repo.ID))
+		return c.InternalError("failed to delete repository")
+	}
+
+	return c.NoContent()
+}
@@ -0,0 +1,10 @@
+package repos
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+)
+
+// Get handles GET /v1/workspaces/:wid/repos/:rid
+func Get(c web.Context) error {
+	return c.OK(c.Repo())
+}
@@ -0,0 +1,52 @@
+package repos
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/files"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// GetFile handles GET /v1/workspaces/:wid/repos/:rid/files?path=
+//
+// start_line and end_line select a range; expand adds that many lines
+// around it, or widens it to the enclosing symbol when set to "symbol".
+func GetFile(c web.Context) error {
+	params := files.ReadParams{Path: c.QueryParam("path")}
+	err := echo.QueryParamsBinder(c.Context).
+		Int("start_line", &params.StartLine).
+		Int("end_line", &params.EndLine).
+		BindError()
+	if err != nil {
+		return c.BadRequest("start_line and end_line must be integers")
+	}
+	if expand := c.QueryParam("expand"); expand == "symbol" {
+		params.ExpandSymbol = true
+	} else if expand != "" {
+		if err := echo.QueryParamsBinder(c.Context).Int("expand", &params.Expand).BindError(); err != nil {
+			return c.BadRequest(`expand must be a number of lines or "symbol"`)
+		}
+	}
+
+	content, err := files.Read(c.Request().Context(), c.Repo(), params)
+	if err != nil {
+		switch {
+		case errors.Is(err, files.ErrInvalidPath), errors.Is(err, files.ErrInvalidRange),
+			errors.Is(err, files.ErrInvalidExpand), errors.Is(err, files.ErrNotAFile):
+			return c.BadRequest(err.Error())
+		case errors.Is(err, files.ErrNotFound):
+			return c.NotFound(err.Error())
+		case errors.Is(err, files.ErrNotIndexed):
+			return c.Conflict(err.Error())
+		case errors.Is(err, files.ErrTooLarge), errors.Is(err, files.ErrBinary):
+			return c.Error(http.StatusUnprocessableEntity, err.Error())
+		}
+		c.L.Error("failed to read file", zap.Error(err), zap.String("path", params.Path))
+		return c.InternalError("failed to read file")
+	}
+
+	return c.OK(content)
+}
@@ -0,0 +1,29 @@
+package repos
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/indexruns"
+	"go.uber.org/zap"
+)
+
+// GetRun handles GET /v1/workspaces/:wid/repos/:rid/runs/:runid
+func GetRun(c web.Context) error {
+	id, err := strconv.ParseInt(c.Param("runid"), 10, 64)
+	if err != nil {
+		return c.NotFound(indexruns.ErrNotFound.Error())
+	}
+
+	run, err := indexruns.Get(c.Request().Context(), c.Repo().ID, id)
+	if err != nil {
+		if errors.Is(err, indexruns.ErrNotFound) {
+			return c.NotFound(err.Error())
+		}
+		c.L.Error("failed to get index run", zap.Error(err), zap.Int64("run_id", id))
+		return c.InternalError("failed to get index run")
+	}
+
+	return c.OK(run)
+}
@@ -0,0 +1,36 @@
+package repos
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/repos"
+	"go.uber.org/zap"
+)
+
+// ListResponse is the response for listing repositories
+type ListResponse struct {
+	Repos []domain.Repo `json:"repos"`
+	web.Pagination
+}
+
+// List handles GET /v1/workspaces/:wid/repos
+func List(c web.Context) error {
+	page, err := c.Page()
+	if err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	result, err := domain.List(c.Request().Context(), domain.ListParams{
+		WorkspaceID: c.Workspace().ID,
+		Limit:       page.Limit,
+		Offset:      page.Offset,
+	})
+	if err != nil {
+		c.L.Error("failed to list repositories", zap.Error(err))
+		return c.InternalError("failed to list repositories")
+	}
+
+	return c.OK(ListResponse{
+		Repos:      result.Repos,
+		Pagination: page.Paginate(result.Total),
+	})
+}
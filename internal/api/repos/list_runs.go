@@ -0,0 +1,36 @@
+package repos
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/indexruns"
+	"go.uber.org/zap"
+)
+
+// ListRunsResponse is the response for listing a repository's index runs
+type ListRunsResponse struct {
+	Runs []indexruns.Run `json:"runs"`
+	web.Pagination
+}
+
+// ListRuns handles GET /v1/workspaces/:wid/repos/:rid/runs
+func ListRuns(c web.Context) error {
+	page, err := c.Page()
+	if err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	result, err := indexruns.List(c.Request().Context(), indexruns.ListParams{
+		RepoID: c.Repo().ID,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	})
+	if err != nil {
+		c.L.Error("failed to list index runs", zap.Error(err))
+		return c.InternalError("failed to list index runs")
+	}
+
+	return c.OK(ListRunsResponse{
+		Runs:       result.Runs,
+		Pagination: page.Paginate(result.Total),
+	})
+}
@@ -0,0 +1,28 @@
+package repos
+
+import (
+	"errors"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/repos"
+	"go.uber.org/zap"
+)
+
+// Reindex handles POST /v1/workspaces/:wid/repos/:rid/reindex
+func Reindex(c web.Context) error {
+	repo := c.Repo()
+
+	updated, err := domain.Reindex(c.Request().Context(), repo.WorkspaceID, repo.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return c.NotFound(err.Error())
+		case errors.Is(err, domain.ErrInvalidTransition):
+			return c.Conflict("repository is already queued or being indexed")
+		}
+		c.L.Error("failed to reindex repository", zap.Error(err), zap.Int64("repo_id", repo.ID))
+		return c.InternalError("failed to reindex repository")
+	}
+
+	return c.Accepted(updated)
+}
@@ -0,0 +1,24 @@
+package repos
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/members"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Configure sets up the workspace-scoped repository routes. Viewers read
+// them; adding, removing and re-indexing repositories takes the editor role.
+func Configure(e *echo.Echo, l *zap.Logger) {
+	g := e.Group("/v1/workspaces/:wid/repos", web.RequireWorkspace(l), web.RequireRole(l, members.RoleViewer))
+	g.GET("", web.Wrap(List, l))
+	g.POST("", web.Wrap(Create, l), web.RequireRole(l, members.RoleEditor))
+
+	r := g.Group("/:rid", web.RequireRepo(l))
+	r.GET("", web.Wrap(Get, l))
+	r.DELETE("", web.Wrap(Delete, l), web.RequireRole(l, members.RoleEditor))
+	r.POST("/reindex", web.Wrap(Reindex, l), web.RequireRole(l, members.RoleEditor))
+	r.GET("/runs", web.Wrap(ListRuns, l))
+	r.GET("/runs/:runid", web.Wrap(GetRun, l))
+	r.GET("/files", web.Wrap(GetFile, l))
+}
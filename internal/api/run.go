@@ -7,7 +7,17 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/gomantics/semantix/internal/api/apikeys"
+	"github.com/gomantics/semantix/internal/api/gittokens"
+	"github.com/gomantics/semantix/internal/api/grep"
 	"github.com/gomantics/semantix/internal/api/health"
+	"github.com/gomantics/semantix/internal/api/mcp"
+	"github.com/gomantics/semantix/internal/api/members"
+	"github.com/gomantics/semantix/internal/api/repos"
+	"github.com/gomantics/semantix/internal/api/search"
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/api/workspaces"
+	server "github.com/gomantics/semantix/internal/mcp"
 	"github.com/gomantics/semantix/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -25,6 +35,7 @@ func Run(lc fx.Lifecycle, l *zap.Logger) error {
 
 	configureMiddleware(e, l)
 	configureRoutes(e, l)
+	watchResources(lc, l)
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf("0.0.0.0:%d", config.Server.Port()),
@@ -55,6 +66,22 @@ func Run(lc fx.Lifecycle, l *zap.Logger) error {
 	return nil
 }
 
+// watchResources relays finished index runs to the MCP sessions subscribed to
+// the repositories' resources
+func watchResources(lc fx.Lifecycle, l *zap.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go server.Watch(ctx, l.Named("mcp"))
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
 func configureMiddleware(e *echo.Echo, l *zap.Logger) {
 	// Request ID must come first
 	e.Use(middleware.RequestID())
@@ -102,12 +129,17 @@ func configureMiddleware(e *echo.Echo, l *zap.Logger) {
 			http.MethodOptions,
 			http.MethodPatch,
 		},
-		AllowHeaders:     []string{"Content-Type", "Authorization", "Origin", "X-Request-ID"},
-		AllowCredentials: true,
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders: []string{"Content-Type", "Authorization", "Origin", "X-Request-ID",
+			server.HeaderSessionID, server.HeaderProtocolVersion},
+		// Keys travel in the Authorization header, never in cookies
+		AllowCredentials: false,
+		ExposeHeaders:    []string{"Content-Length", server.HeaderSessionID},
 		MaxAge:           int((24 * time.Hour).Seconds()),
 	}))
 
+	// Runs after CORS so preflight requests need no key
+	e.Use(web.Authenticate(l, "/v1/health"))
+
 	if config.IsDev() {
 		e.IPExtractor = echo.ExtractIPDirect()
 	} else {
@@ -117,10 +149,12 @@ func configureMiddleware(e *echo.Echo, l *zap.Logger) {
 
 func configureRoutes(e *echo.Echo, l *zap.Logger) {
 	health.Configure(e, l)
-
-	// TODO: Phase 1-3 - Add routes as they are implemented
-	// workspaces.Configure(e, l)
-	// gittokens.Configure(e, l)
-	// repositories.Configure(e, l)
-	// search.Configure(e, l)
+	workspaces.Configure(e, l)
+	members.Configure(e, l)
+	repos.Configure(e, l)
+	gittokens.Configure(e, l)
+	apikeys.Configure(e, l)
+	search.Configure(e, l)
+	grep.Configure(e, l)
+	mcp.Configure(e, l)
 }
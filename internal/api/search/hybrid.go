@@ -0,0 +1,78 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/search"
+	"go.uber.org/zap"
+)
+
+// HybridRequest is the request body for a hybrid search. Omitted weights
+// keep their defaults.
+type HybridRequest struct {
+	Query string `json:"query"`
+	domain.Filters
+	Weights struct {
+		Semantic *float64 `json:"semantic"`
+		FullText *float64 `json:"fulltext"`
+		Trigram  *float64 `json:"trigram"`
+	} `json:"weights"`
+	RRFK  int `json:"rrf_k"`
+	Limit int `json:"limit"`
+}
+
+// HybridResponse is the response for a hybrid search
+type HybridResponse struct {
+	Results []domain.HybridResult `json:"results"`
+}
+
+// Hybrid handles POST /v1/workspaces/:wid/search/hybrid
+func Hybrid(c web.Context) error {
+	var req HybridRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+	if req.Limit == 0 {
+		req.Limit = domain.DefaultLimit
+	}
+	if req.Limit < 1 || req.Limit > domain.MaxLimit {
+		return c.BadRequest(fmt.Sprintf("limit must be between 1 and %d", domain.MaxLimit))
+	}
+
+	weights := domain.DefaultWeights
+	if req.Weights.Semantic != nil {
+		weights.Semantic = *req.Weights.Semantic
+	}
+	if req.Weights.FullText != nil {
+		weights.FullText = *req.Weights.FullText
+	}
+	if req.Weights.Trigram != nil {
+		weights.Trigram = *req.Weights.Trigram
+	}
+
+	results, err := domain.Hybrid(c.Request().Context(), domain.HybridParams{
+		WorkspaceID: c.Workspace().ID,
+		Query:       req.Query,
+		Filters:     req.Filters,
+		Weights:     weights,
+		RRFK:        req.RRFK,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmptyQuery), errors.Is(err, domain.ErrQueryTooLong),
+			errors.Is(err, domain.ErrInvalidGlob), errors.Is(err, domain.ErrInvalidWeights),
+			errors.Is(err, domain.ErrInvalidRRFK):
+			return c.BadRequest(err.Error())
+		case errors.Is(err, domain.ErrUnavailable):
+			return c.Error(http.StatusServiceUnavailable, err.Error())
+		}
+		c.L.Error("failed to run hybrid search", zap.Error(err))
+		return c.InternalError("failed to run hybrid search")
+	}
+
+	return c.OK(HybridResponse{Results: results})
+}
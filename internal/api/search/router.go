@@ -0,0 +1,15 @@
+package search
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/members"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Configure sets up the workspace-scoped search routes
+func Configure(e *echo.Echo, l *zap.Logger) {
+	g := e.Group("/v1/workspaces/:wid/search", web.RequireWorkspace(l), web.RequireRole(l, members.RoleViewer))
+	g.POST("", web.Wrap(Search, l))
+	g.POST("/hybrid", web.Wrap(Hybrid, l))
+}
@@ -0,0 +1,68 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/search"
+	"go.uber.org/zap"
+)
+
+// SearchRequest is the request body for a semantic search
+type SearchRequest struct {
+	Query string `json:"query"`
+	domain.Filters
+	MinScore float32 `json:"min_score"`
+	Limit    int     `json:"limit"`
+	Cursor   string  `json:"cursor"`
+}
+
+// SearchResponse is the response for a semantic search
+type SearchResponse struct {
+	Results    []domain.Result `json:"results"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// Search handles POST /v1/workspaces/:wid/search
+func Search(c web.Context) error {
+	var req SearchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+	if req.Limit == 0 {
+		req.Limit = domain.DefaultLimit
+	}
+	if req.Limit < 1 || req.Limit > domain.MaxLimit {
+		return c.BadRequest(fmt.Sprintf("limit must be between 1 and %d", domain.MaxLimit))
+	}
+	if req.MinScore < 0 {
+		return c.BadRequest("min_score must not be negative")
+	}
+
+	page, err := domain.Search(c.Request().Context(), domain.Params{
+		WorkspaceID: c.Workspace().ID,
+		Query:       req.Query,
+		Filters:     req.Filters,
+		MinScore:    req.MinScore,
+		Limit:       req.Limit,
+		Cursor:      req.Cursor,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmptyQuery), errors.Is(err, domain.ErrQueryTooLong),
+			errors.Is(err, domain.ErrInvalidGlob), errors.Is(err, domain.ErrInvalidCursor):
+			return c.BadRequest(err.Error())
+		case errors.Is(err, domain.ErrUnavailable):
+			return c.Error(http.StatusServiceUnavailable, err.Error())
+		}
+		c.L.Error("failed to search", zap.Error(err))
+		return c.InternalError("failed to search")
+	}
+
+	return c.OK(SearchResponse{
+		Results:    page.Results,
+		NextCursor: page.NextCursor,
+	})
+}
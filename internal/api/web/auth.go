@@ -0,0 +1,102 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gomantics/semantix/internal/domains/apikeys"
+	"github.com/gomantics/semantix/internal/domains/members"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const principalKey = "principal"
+
+// Authenticate resolves the request's bearer API key into the principal
+// returned by Context.Principal, responding 401 when it's missing or invalid.
+// Requests for public paths pass through unauthenticated.
+func Authenticate(l *zap.Logger, public ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return Wrap(func(c Context) error {
+			for _, p := range public {
+				if c.Path() == p {
+					return next(c.Context)
+				}
+			}
+
+			key, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || key == "" {
+				return unauthorized(c)
+			}
+			principal, err := apikeys.Authenticate(c.Request().Context(), key)
+			if err != nil {
+				if errors.Is(err, apikeys.ErrInvalidKey) {
+					return unauthorized(c)
+				}
+				c.L.Error("failed to authenticate API key", zap.Error(err))
+				return c.InternalError("failed to authenticate")
+			}
+
+			c.Set(principalKey, principal)
+			return next(c.Context)
+		}, l)
+	}
+}
+
+func unauthorized(c Context) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="semantix"`)
+	return c.Error(http.StatusUnauthorized, "invalid or missing API key")
+}
+
+// RequireSuperadmin responds 403 unless the request was made with a
+// superadmin key
+func RequireSuperadmin(l *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return Wrap(func(c Context) error {
+			if !c.Superadmin() {
+				return c.Forbidden("a superadmin API key is required")
+			}
+			return next(c.Context)
+		}, l)
+	}
+}
+
+// RequireRole responds 403 unless the principal holds at least the role in
+// the workspace loaded by RequireWorkspace
+func RequireRole(l *zap.Logger, role members.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return Wrap(func(c Context) error {
+			if !c.Allows(role) {
+				return c.Forbidden("the " + string(role) + " role is required in this workspace")
+			}
+			return next(c.Context)
+		}, l)
+	}
+}
+
+// Principal returns the API key resolved by Authenticate
+func (c Context) Principal() *apikeys.APIKey {
+	p, _ := c.Get(principalKey).(*apikeys.APIKey)
+	return p
+}
+
+// Superadmin reports whether the request was made with a superadmin key
+func (c Context) Superadmin() bool {
+	p := c.Principal()
+	return p != nil && p.Superadmin
+}
+
+// CanAccess reports whether the principal may access the workspace. Handlers
+// answer 404 otherwise, so keys can't learn which workspaces exist.
+func (c Context) CanAccess(workspaceID int64) bool {
+	p := c.Principal()
+	return p != nil && p.CanAccess(workspaceID)
+}
+
+// Allows reports whether the principal holds at least the role in the
+// workspace loaded by RequireWorkspace. Superadmins hold every role.
+func (c Context) Allows(role members.Role) bool {
+	p, ws := c.Principal(), c.Workspace()
+	return p != nil && ws != nil && p.Allows(ws.ID, role)
+}
@@ -0,0 +1,58 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomantics/semantix/internal/domains/apikeys"
+	"github.com/gomantics/semantix/internal/domains/members"
+	"github.com/gomantics/semantix/internal/domains/workspaces"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func TestRequireRole(t *testing.T) {
+	const a, b = 1, 2
+	member := func(role members.Role) *apikeys.APIKey {
+		return &apikeys.APIKey{Workspaces: []members.Membership{{WorkspaceID: a, Role: role}}}
+	}
+
+	tests := []struct {
+		name      string
+		principal *apikeys.APIKey
+		workspace int64
+		role      members.Role
+		want      int
+	}{
+		{name: "viewer reads", principal: member(members.RoleViewer), workspace: a, role: members.RoleViewer, want: http.StatusOK},
+		{name: "viewer writes", principal: member(members.RoleViewer), workspace: a, role: members.RoleEditor, want: http.StatusForbidden},
+		{name: "editor writes", principal: member(members.RoleEditor), workspace: a, role: members.RoleEditor, want: http.StatusOK},
+		{name: "editor manages members", principal: member(members.RoleEditor), workspace: a, role: members.RoleAdmin, want: http.StatusForbidden},
+		{name: "admin manages members", principal: member(members.RoleAdmin), workspace: a, role: members.RoleAdmin, want: http.StatusOK},
+		{name: "admin of another workspace", principal: member(members.RoleAdmin), workspace: b, role: members.RoleViewer, want: http.StatusForbidden},
+		{name: "superadmin", principal: &apikeys.APIKey{Superadmin: true}, workspace: b, role: members.RoleAdmin, want: http.StatusOK},
+		{name: "no principal", workspace: a, role: members.RoleViewer, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+			if tt.principal != nil {
+				c.Set(principalKey, tt.principal)
+			}
+			c.Set(workspaceKey, &workspaces.Workspace{ID: tt.workspace})
+
+			h := RequireRole(zap.NewNop(), tt.role)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+			if err := h(c); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -37,10 +37,18 @@ func (c Context) BadRequest(message string) error {
 	return c.Error(http.StatusBadRequest, message)
 }
 
+func (c Context) Forbidden(message string) error {
+	return c.Error(http.StatusForbidden, message)
+}
+
 func (c Context) NotFound(message string) error {
 	return c.Error(http.StatusNotFound, message)
 }
 
+func (c Context) Conflict(message string) error {
+	return c.Error(http.StatusConflict, message)
+}
+
 func (c Context) InternalError(message string) error {
 	return c.Error(http.StatusInternalServerError, message)
 }
@@ -53,6 +61,10 @@ func (c Context) Created(data any) error {
 	return c.JSON(http.StatusCreated, data)
 }
 
+func (c Context) Accepted(data any) error {
+	return c.JSON(http.StatusAccepted, data)
+}
+
 func (c Context) NoContent() error {
 	return c.Context.NoContent(http.StatusNoContent)
 }
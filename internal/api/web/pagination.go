@@ -0,0 +1,52 @@
+package web
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Page holds limit/offset pagination parameters parsed from the query string
+type Page struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// Pagination is the pagination metadata returned with list responses
+type Pagination struct {
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// Page parses the limit and offset query parameters, applying defaults
+func (c Context) Page() (Page, error) {
+	page := Page{Limit: DefaultPageLimit}
+
+	err := echo.QueryParamsBinder(c.Context).
+		Int("limit", &page.Limit).
+		Int("offset", &page.Offset).
+		BindError()
+	if err != nil {
+		return Page{}, fmt.Errorf("invalid pagination parameters")
+	}
+
+	if page.Limit < 1 || page.Limit > MaxPageLimit {
+		return Page{}, fmt.Errorf("limit must be between 1 and %d", MaxPageLimit)
+	}
+	if page.Offset < 0 {
+		return Page{}, fmt.Errorf("offset must not be negative")
+	}
+
+	return page, nil
+}
+
+// Paginate builds the pagination metadata for a page with a known total
+func (p Page) Paginate(total int64) Pagination {
+	return Pagination{Total: total, Limit: p.Limit, Offset: p.Offset}
+}
@@ -0,0 +1,77 @@
+package web
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gomantics/semantix/internal/domains/repos"
+	"github.com/gomantics/semantix/internal/domains/workspaces"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	workspaceKey = "workspace"
+	repoKey      = "repo"
+)
+
+// RequireWorkspace resolves the :wid path parameter (numeric ID or slug) into a
+// workspace for nested routes, responding 404 if it doesn't exist or the
+// principal may not access it
+func RequireWorkspace(l *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return Wrap(func(c Context) error {
+			ws, err := workspaces.Resolve(c.Request().Context(), c.Param("wid"))
+			if err == nil && !c.CanAccess(ws.ID) {
+				err = workspaces.ErrNotFound
+			}
+			if err != nil {
+				if errors.Is(err, workspaces.ErrNotFound) {
+					return c.NotFound(err.Error())
+				}
+				c.L.Error("failed to resolve workspace", zap.Error(err))
+				return c.InternalError("failed to resolve workspace")
+			}
+
+			c.Set(workspaceKey, ws)
+			return next(c.Context)
+		}, l)
+	}
+}
+
+// RequireRepo resolves the :rid path parameter into a repository of the
+// workspace loaded by RequireWorkspace, responding 404 if it doesn't exist
+func RequireRepo(l *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return Wrap(func(c Context) error {
+			id, err := strconv.ParseInt(c.Param("rid"), 10, 64)
+			if err != nil {
+				return c.NotFound(repos.ErrNotFound.Error())
+			}
+
+			repo, err := repos.Get(c.Request().Context(), c.Workspace().ID, id)
+			if err != nil {
+				if errors.Is(err, repos.ErrNotFound) {
+					return c.NotFound(err.Error())
+				}
+				c.L.Error("failed to get repository", zap.Error(err), zap.Int64("repo_id", id))
+				return c.InternalError("failed to get repository")
+			}
+
+			c.Set(repoKey, repo)
+			return next(c.Context)
+		}, l)
+	}
+}
+
+// Workspace returns the workspace resolved by RequireWorkspace
+func (c Context) Workspace() *workspaces.Workspace {
+	ws, _ := c.Get(workspaceKey).(*workspaces.Workspace)
+	return ws
+}
+
+// Repo returns the repository resolved by RequireRepo
+func (c Context) Repo() *repos.Repo {
+	repo, _ := c.Get(repoKey).(*repos.Repo)
+	return repo
+}
@@ -0,0 +1,54 @@
+package workspaces
+
+import (
+	"errors"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/workspaces"
+	"go.uber.org/zap"
+)
+
+// CreateRequest is the request body for creating a workspace
+type CreateRequest struct {
+	Name        string         `json:"name"`
+	Slug        string         `json:"slug"`
+	Description *string        `json:"description"`
+	Settings    map[string]any `json:"settings"`
+}
+
+func (r CreateRequest) validate() error {
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	if err := validateSlug(r.Slug); err != nil {
+		return err
+	}
+	return validateDescription(r.Description)
+}
+
+// Create handles POST /v1/workspaces
+func Create(c web.Context) error {
+	var req CreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+	if err := req.validate(); err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	ws, err := domain.Create(c.Request().Context(), domain.CreateParams{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+		Settings:    req.Settings,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return c.Conflict(err.Error())
+		}
+		c.L.Error("failed to create workspace", zap.Error(err))
+		return c.InternalError("failed to create workspace")
+	}
+
+	return c.Created(ws)
+}
@@ -0,0 +1,22 @@
+package workspaces
+
+import (
+	"errors"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/workspaces"
+	"go.uber.org/zap"
+)
+
+// Delete handles DELETE /v1/workspaces/:wid
+func Delete(c web.Context) error {
+	if err := domain.Delete(c.Request().Context(), c.Workspace().ID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.NotFound(err.Error())
+		}
+		c.L.Error("failed to delete workspace", zap.Error(err))
+		return c.InternalError("failed to delete workspace")
+	}
+
+	return c.NoContent()
+}
@@ -0,0 +1,10 @@
+package workspaces
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+)
+
+// Get handles GET /v1/workspaces/:wid
+func Get(c web.Context) error {
+	return c.OK(c.Workspace())
+}
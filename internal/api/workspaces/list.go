@@ -0,0 +1,44 @@
+package workspaces
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/workspaces"
+	"go.uber.org/zap"
+)
+
+// ListResponse is the response for listing workspaces
+type ListResponse struct {
+	Workspaces []domain.Workspace `json:"workspaces"`
+	web.Pagination
+}
+
+// List handles GET /v1/workspaces
+func List(c web.Context) error {
+	page, err := c.Page()
+	if err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	params := domain.ListParams{
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}
+	// Keys only see the workspaces they're members of
+	if p := c.Principal(); !p.Superadmin {
+		params.IDs = make([]int64, len(p.Workspaces))
+		for i, m := range p.Workspaces {
+			params.IDs[i] = m.WorkspaceID
+		}
+	}
+
+	result, err := domain.List(c.Request().Context(), params)
+	if err != nil {
+		c.L.Error("failed to list workspaces", zap.Error(err))
+		return c.InternalError("failed to list workspaces")
+	}
+
+	return c.OK(ListResponse{
+		Workspaces: result.Workspaces,
+		Pagination: page.Paginate(result.Total),
+	})
+}
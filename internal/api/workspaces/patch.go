@@ -0,0 +1,86 @@
+package workspaces
+
+import (
+	"encoding/json"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/workspaces"
+)
+
+// PatchRequest is the request body for partially updating a workspace.
+// Omitted fields are left unchanged; a null or empty description clears it.
+type PatchRequest struct {
+	Name        *string         `json:"name"`
+	Slug        *string         `json:"slug"`
+	Description nullableString  `json:"description"`
+	Settings    *map[string]any `json:"settings"`
+}
+
+// nullableString tells an omitted field from an explicit null
+type nullableString struct {
+	Set   bool
+	Value *string
+}
+
+func (s *nullableString) UnmarshalJSON(b []byte) error {
+	s.Set = true
+	s.Value = nil
+	if string(b) == "null" {
+		return nil
+	}
+	return json.Unmarshal(b, &s.Value)
+}
+
+func (r PatchRequest) validate() error {
+	if r.Name != nil {
+		if err := validateName(*r.Name); err != nil {
+			return err
+		}
+	}
+	if r.Slug != nil {
+		if err := validateSlug(*r.Slug); err != nil {
+			return err
+		}
+	}
+	return validateDescription(r.Description.Value)
+}
+
+// Patch handles PATCH /v1/workspaces/:wid
+func Patch(c web.Context) error {
+	var req PatchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+	if err := req.validate(); err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	existing := c.Workspace()
+	return update(c, existing.ID, req.apply(existing))
+}
+
+// apply returns the update that makes existing match the request
+func (r PatchRequest) apply(existing *domain.Workspace) domain.UpdateParams {
+	params := domain.UpdateParams{
+		Name:        existing.Name,
+		Slug:        existing.Slug,
+		Description: existing.Description,
+		Settings:    existing.Settings,
+	}
+	if r.Name != nil {
+		params.Name = *r.Name
+	}
+	if r.Slug != nil {
+		params.Slug = *r.Slug
+	}
+	if r.Description.Set {
+		params.Description = r.Description.Value
+		if params.Description != nil && *params.Description == "" {
+			params.Description = nil
+		}
+	}
+	if r.Settings != nil {
+		params.Settings = *r.Settings
+	}
+	return params
+}
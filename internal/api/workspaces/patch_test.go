@@ -0,0 +1,58 @@
+package workspaces
+
+import (
+	"encoding/json"
+	"testing"
+
+	domain "github.com/gomantics/semantix/internal/domains/workspaces"
+)
+
+func TestPatchDescription(t *testing.T) {
+	old := "old"
+	existing := &domain.Workspace{ID: 1, Name: "ws", Slug: "ws", Description: &old}
+
+	tests := []struct {
+		name string
+		body string
+		want *string
+	}{
+		{name: "omitted keeps it", body: `{"name":"renamed"}`, want: &old},
+		{name: "null clears it", body: `{"description":null}`, want: nil},
+		{name: "empty clears it", body: `{"description":""}`, want: nil},
+		{name: "value replaces it", body: `{"description":"new"}`, want: ptr("new")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req PatchRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if err := req.validate(); err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			got := req.apply(existing).Description
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("description = %q, want nil", *got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Errorf("description = %v, want %q", got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchDescriptionTooLong(t *testing.T) {
+	long := make([]byte, maxDescriptionLength+1)
+	for i := range long {
+		long[i] = 'a'
+	}
+	var req PatchRequest
+	if err := json.Unmarshal([]byte(`{"description":"`+string(long)+`"}`), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if req.validate() == nil {
+		t.Error("validate accepted an over-long description")
+	}
+}
+
+func ptr(s string) *string { return &s }
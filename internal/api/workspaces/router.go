@@ -0,0 +1,21 @@
+package workspaces
+
+import (
+	"github.com/gomantics/semantix/internal/api/web"
+	"github.com/gomantics/semantix/internal/domains/members"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Configure sets up the workspace routes. Changing or deleting a workspace
+// takes its admin role.
+func Configure(e *echo.Echo, l *zap.Logger) {
+	e.GET("/v1/workspaces", web.Wrap(List, l))
+	e.POST("/v1/workspaces", web.Wrap(Create, l), web.RequireSuperadmin(l))
+
+	g := e.Group("/v1/workspaces/:wid", web.RequireWorkspace(l))
+	g.GET("", web.Wrap(Get, l), web.RequireRole(l, members.RoleViewer))
+	g.PUT("", web.Wrap(Update, l), web.RequireRole(l, members.RoleAdmin))
+	g.PATCH("", web.Wrap(Patch, l), web.RequireRole(l, members.RoleAdmin))
+	g.DELETE("", web.Wrap(Delete, l), web.RequireRole(l, members.RoleAdmin))
+}
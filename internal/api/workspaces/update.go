@@ -0,0 +1,62 @@
+package workspaces
+
+import (
+	"errors"
+
+	"github.com/gomantics/semantix/internal/api/web"
+	domain "github.com/gomantics/semantix/internal/domains/workspaces"
+	"go.uber.org/zap"
+)
+
+// UpdateRequest is the request body for replacing a workspace
+type UpdateRequest struct {
+	Name        string         `json:"name"`
+	Slug        string         `json:"slug"`
+	Description *string        `json:"description"`
+	Settings    map[string]any `json:"settings"`
+}
+
+func (r UpdateRequest) validate() error {
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	if err := validateSlug(r.Slug); err != nil {
+		return err
+	}
+	return validateDescription(r.Description)
+}
+
+// Update handles PUT /v1/workspaces/:wid
+func Update(c web.Context) error {
+	var req UpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.BadRequest("invalid request body")
+	}
+	if err := req.validate(); err != nil {
+		return c.BadRequest(err.Error())
+	}
+
+	return update(c, c.Workspace().ID, domain.UpdateParams{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+		Settings:    req.Settings,
+	})
+}
+
+// update applies params to the workspace and writes the response
+func update(c web.Context, id int64, params domain.UpdateParams) error {
+	ws, err := domain.Update(c.Request().Context(), id, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return c.NotFound(err.Error())
+		case errors.Is(err, domain.ErrAlreadyExists):
+			return c.Conflict(err.Error())
+		}
+		c.L.Error("failed to update workspace", zap.Error(err), zap.Int64("workspace_id", id))
+		return c.InternalError("failed to update workspace")
+	}
+
+	return c.OK(ws)
+}
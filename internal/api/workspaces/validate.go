@@ -0,0 +1,53 @@
+package workspaces
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxNameLength        = 100
+	maxSlugLength        = 64
+	maxDescriptionLength = 1000
+)
+
+var (
+	slugPattern    = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	numericPattern = regexp.MustCompile(`^[0-9]+$`)
+)
+
+func validateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+	if len(name) > maxNameLength {
+		return fmt.Errorf("name must be at most %d characters", maxNameLength)
+	}
+	return nil
+}
+
+// validateSlug ensures the slug is URL-safe and can't be mistaken for an ID
+func validateSlug(slug string) error {
+	if slug == "" {
+		return errors.New("slug is required")
+	}
+	if len(slug) > maxSlugLength {
+		return fmt.Errorf("slug must be at most %d characters", maxSlugLength)
+	}
+	if !slugPattern.MatchString(slug) {
+		return errors.New("slug must contain only lowercase letters, digits and single hyphens")
+	}
+	if numericPattern.MatchString(slug) {
+		return errors.New("slug must not be purely numeric")
+	}
+	return nil
+}
+
+func validateDescription(description *string) error {
+	if description != nil && len(*description) > maxDescriptionLength {
+		return fmt.Errorf("description must be at most %d characters", maxDescriptionLength)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+// Package apikeys issues and verifies the API keys that authenticate requests
+package apikeys
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/domains/members"
+	"github.com/gomantics/semantix/pkg/pgconv"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrNotFound = errors.New("API key not found")
+	// ErrInvalidKey covers unknown, malformed and expired keys alike, so
+	// callers can't tell which
+	ErrInvalidKey        = errors.New("invalid or expired API key")
+	ErrNoWorkspaces      = errors.New("a key must be a member of at least one workspace unless it's a superadmin")
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrExpiresInPast     = errors.New("expires_at must be in the future")
+)
+
+const (
+	// KeyPrefix starts every key, so leaked keys are easy to scan for
+	KeyPrefix = "sx_"
+
+	// prefixBytes and secretBytes are the random bytes of a key's public
+	// prefix and of its secret
+	prefixBytes = 6
+	secretBytes = 32
+
+	// touchInterval is how stale last_used_at may get before a request
+	// updates it
+	touchInterval = time.Minute
+)
+
+// prefixLen is the length of a key's stored prefix
+var prefixLen = len(KeyPrefix) + hex.EncodedLen(prefixBytes)
+
+// Create stores a new key and returns it with the key itself, which can't be
+// recovered later
+func Create(ctx context.Context, params CreateParams) (*APIKey, string, error) {
+	if !params.Superadmin && len(params.Workspaces) == 0 {
+		return nil, "", ErrNoWorkspaces
+	}
+	now := time.Now().UnixNano()
+	if params.ExpiresAt != nil && *params.ExpiresAt <= now {
+		return nil, "", ErrExpiresInPast
+	}
+	for _, m := range params.Workspaces {
+		if !m.Role.Valid() {
+			return nil, "", members.ErrInvalidRole
+		}
+	}
+	if params.Superadmin {
+		// Superadmins hold every role everywhere; memberships would be misleading
+		params.Workspaces = nil
+	}
+	memberships := dedupe(params.Workspaces)
+
+	key, prefix, err := generate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	dbKey, err := db.Tx1(ctx, func(q *db.Queries) (db.ApiKey, error) {
+		for _, m := range memberships {
+			if _, err := q.GetWorkspaceByID(ctx, m.WorkspaceID); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return db.ApiKey{}, ErrWorkspaceNotFound
+				}
+				return db.ApiKey{}, err
+			}
+		}
+
+		dbKey, err := q.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+			Name:       params.Name,
+			Prefix:     prefix,
+			KeyHash:    hash(key),
+			Superadmin: params.Superadmin,
+			ExpiresAt:  pgconv.ToInt8(params.ExpiresAt),
+			Created:    now,
+			Updated:    now,
+		})
+		if err != nil {
+			return db.ApiKey{}, err
+		}
+
+		for _, m := range memberships {
+			if _, err := members.SetTx(ctx, q, m.WorkspaceID, dbKey.ID, m.Role); err != nil {
+				return db.ApiKey{}, err
+			}
+		}
+		return dbKey, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	k := toAPIKey(dbKey)
+	k.Workspaces = memberships
+	return k, key, nil
+}
+
+// List retrieves API keys with pagination
+func List(ctx context.Context, params ListParams) (*ListResult, error) {
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	type listData struct {
+		keys  []db.ApiKey
+		total int64
+	}
+
+	data, err := db.Tx1(ctx, func(q *db.Queries) (listData, error) {
+		dbKeys, err := q.ListAPIKeys(ctx, db.ListAPIKeysParams{
+			Limit:  int32(params.Limit),
+			Offset: int32(params.Offset),
+		})
+		if err != nil {
+			return listData{}, err
+		}
+
+		total, err := q.CountAPIKeys(ctx)
+		if err != nil {
+			return listData{}, err
+		}
+
+		return listData{keys: dbKeys, total: total}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]APIKey, len(data.keys))
+	for i, dbKey := range data.keys {
+		keys[i] = *toAPIKey(dbKey)
+	}
+	if err := loadMemberships(ctx, keys); err != nil {
+		return nil, err
+	}
+
+	return &ListResult{APIKeys: keys, Total: data.total}, nil
+}
+
+// Delete revokes a key; requests using it fail from then on
+func Delete(ctx context.Context, id int64) error {
+	return db.Tx(ctx, func(q *db.Queries) error {
+		if _, err := q.GetAPIKeyByID(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := q.DeleteWorkspaceMembersByAPIKey(ctx, id); err != nil {
+			return err
+		}
+		return q.DeleteAPIKey(ctx, id)
+	})
+}
+
+// Authenticate returns the stored key matching key, recording its use
+func Authenticate(ctx context.Context, key string) (*APIKey, error) {
+	if len(key) <= prefixLen || !strings.HasPrefix(key, KeyPrefix) {
+		return nil, ErrInvalidKey
+	}
+
+	dbKey, err := db.Query1(ctx, func(q *db.Queries) (db.ApiKey, error) {
+		return q.GetAPIKeyByPrefix(ctx, key[:prefixLen])
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidKey
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hash(key)), []byte(dbKey.KeyHash)) != 1 {
+		return nil, ErrInvalidKey
+	}
+
+	now := time.Now()
+	if dbKey.ExpiresAt.Valid && dbKey.ExpiresAt.Int64 <= now.UnixNano() {
+		return nil, ErrInvalidKey
+	}
+
+	k := toAPIKey(dbKey)
+	if !k.Superadmin {
+		keys := []APIKey{*k}
+		if err := loadMemberships(ctx, keys); err != nil {
+			return nil, err
+		}
+		k = &keys[0]
+	}
+
+	staleBefore := now.Add(-touchInterval).UnixNano()
+	if !dbKey.LastUsedAt.Valid || dbKey.LastUsedAt.Int64 < staleBefore {
+		err := db.Query(ctx, func(q *db.Queries) error {
+			return q.TouchAPIKey(ctx, db.TouchAPIKeyParams{
+				Now:         pgtype.Int8{Int64: now.UnixNano(), Valid: true},
+				ID:          k.ID,
+				StaleBefore: pgtype.Int8{Int64: staleBefore, Valid: true},
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// generate returns a new key and its public prefix
+func generate() (key, prefix string, err error) {
+	b := make([]byte, prefixBytes+secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix = KeyPrefix + hex.EncodeToString(b[:prefixBytes])
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(b[prefixBytes:]), prefix, nil
+}
+
+// hash is the stored form of a key. Keys are random, so a fast hash is as
+// strong as a slow one here.
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadMemberships fills in the workspace roles of keys
+func loadMemberships(ctx context.Context, keys []APIKey) error {
+	ids := make([]int64, len(keys))
+	for i := range keys {
+		ids[i] = keys[i].ID
+	}
+
+	byKey, err := members.ListByAPIKeys(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range keys {
+		keys[i].Workspaces = byKey[keys[i].ID]
+		if keys[i].Workspaces == nil {
+			keys[i].Workspaces = []members.Membership{}
+		}
+	}
+	return nil
+}
+
+// dedupe returns the memberships sorted by workspace, keeping the last role
+// given for each
+func dedupe(ms []members.Membership) []members.Membership {
+	out := []members.Membership{}
+	for i := len(ms) - 1; i >= 0; i-- {
+		if !slices.ContainsFunc(out, func(m members.Membership) bool { return m.WorkspaceID == ms[i].WorkspaceID }) {
+			out = append(out, ms[i])
+		}
+	}
+	slices.SortFunc(out, func(a, b members.Membership) int { return cmp.Compare(a.WorkspaceID, b.WorkspaceID) })
+	return out
+}
+
+func toAPIKey(dbKey db.ApiKey) *APIKey {
+	return &APIKey{
+		ID:         dbKey.ID,
+		Name:       dbKey.Name,
+		Prefix:     dbKey.Prefix,
+		Superadmin: dbKey.Superadmin,
+		ExpiresAt:  pgconv.FromInt8(dbKey.ExpiresAt),
+		LastUsedAt: pgconv.FromInt8(dbKey.LastUsedAt),
+		Created:    dbKey.Created,
+		Updated:    dbKey.Updated,
+	}
+}
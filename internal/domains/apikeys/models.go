@@ -0,0 +1,66 @@
+package apikeys
+
+import "github.com/gomantics/semantix/internal/domains/members"
+
+// APIKey is a stored API key. Only its prefix is kept in the clear; the key
+// itself is returned once, when created.
+type APIKey struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+	// Superadmin keys hold every role in every workspace and manage keys,
+	// git tokens and workspaces themselves
+	Superadmin bool `json:"superadmin"`
+	// Workspaces are the key's roles in the workspaces it's a member of
+	Workspaces []members.Membership `json:"workspaces"`
+	ExpiresAt  *int64               `json:"expires_at,omitempty"`
+	LastUsedAt *int64               `json:"last_used_at,omitempty"`
+	Created    int64                `json:"created"`
+	Updated    int64                `json:"updated"`
+}
+
+// Role returns the key's role in the workspace, if it's a member
+func (k *APIKey) Role(workspaceID int64) (members.Role, bool) {
+	for _, m := range k.Workspaces {
+		if m.WorkspaceID == workspaceID {
+			return m.Role, true
+		}
+	}
+	return "", false
+}
+
+// CanAccess reports whether the key may access the workspace at all
+func (k *APIKey) CanAccess(workspaceID int64) bool {
+	_, ok := k.Role(workspaceID)
+	return k.Superadmin || ok
+}
+
+// Allows reports whether the key holds at least the role in the workspace
+func (k *APIKey) Allows(workspaceID int64, role members.Role) bool {
+	if k.Superadmin {
+		return true
+	}
+	r, ok := k.Role(workspaceID)
+	return ok && r.Includes(role)
+}
+
+// CreateParams are the parameters for creating an API key
+type CreateParams struct {
+	Name       string
+	Superadmin bool
+	Workspaces []members.Membership
+	// ExpiresAt is in nanoseconds since epoch; nil never expires
+	ExpiresAt *int64
+}
+
+// ListParams are the parameters for listing API keys
+type ListParams struct {
+	Limit  int
+	Offset int
+}
+
+// ListResult contains the result of listing API keys
+type ListResult struct {
+	APIKeys []APIKey
+	Total   int64
+}
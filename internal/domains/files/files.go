@@ -0,0 +1,120 @@
+package files
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+)
+
+// ListByRepo returns the indexed state of every file in the repository
+func ListByRepo(ctx context.Context, repoID int64) ([]File, error) {
+	rows, err := db.Query1(ctx, func(q *db.Queries) ([]db.ListFilesByRepoRow, error) {
+		return q.ListFilesByRepo(ctx, repoID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, len(rows))
+	for i, r := range rows {
+		files[i] = File{ID: r.ID, Path: r.Path, ContentHash: r.ContentHash, ChunkCount: r.ChunkCount}
+	}
+	return files, nil
+}
+
+// ListByWorkspace returns a page of the workspace's indexed files in ID
+// order, starting after params.AfterID
+func ListByWorkspace(ctx context.Context, params ListParams) ([]Listed, error) {
+	rows, err := db.Query1(ctx, func(q *db.Queries) ([]db.ListFilesByWorkspaceRow, error) {
+		return q.ListFilesByWorkspace(ctx, db.ListFilesByWorkspaceParams{
+			WorkspaceID: params.WorkspaceID,
+			AfterID:     params.AfterID,
+			Limit:       int32(params.Limit),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]Listed, len(rows))
+	for i, r := range rows {
+		files[i] = Listed{
+			ID:        r.ID,
+			RepoID:    r.RepoID,
+			Path:      r.Path,
+			Language:  r.Language.String,
+			SizeBytes: r.SizeBytes,
+		}
+	}
+	return files, nil
+}
+
+// SaveTx inserts or updates the repository's rows for entries and returns
+// their IDs by path
+func SaveTx(ctx context.Context, q *db.Queries, repoID int64, entries []Entry) (map[string]int64, error) {
+	arg := db.UpsertFilesParams{RepoID: repoID, Now: time.Now().UnixNano()}
+	for _, e := range entries {
+		arg.Paths = append(arg.Paths, e.Path)
+		arg.ContentHashes = append(arg.ContentHashes, e.ContentHash)
+		arg.Languages = append(arg.Languages, e.Language)
+		arg.SizeBytes = append(arg.SizeBytes, e.SizeBytes)
+		arg.LineCounts = append(arg.LineCounts, e.LineCount)
+		arg.ChunkCounts = append(arg.ChunkCounts, e.ChunkCount)
+	}
+
+	rows, err := q.UpsertFiles(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]int64, len(rows))
+	for _, r := range rows {
+		ids[r.Path] = r.ID
+	}
+	return ids, nil
+}
+
+// DeleteTx removes file rows by ID. Their chunks are removed by the caller.
+func DeleteTx(ctx context.Context, q *db.Queries, ids []int64) error {
+	return q.DeleteFilesByIDs(ctx, ids)
+}
+
+// ContentHashes returns the hash of each file's stored text in the
+// repository, by path
+func ContentHashes(ctx context.Context, repoID int64) (map[string]string, error) {
+	rows, err := db.Query1(ctx, func(q *db.Queries) ([]db.ListFileContentHashesRow, error) {
+		return q.ListFileContentHashes(ctx, repoID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, len(rows))
+	for _, r := range rows {
+		hashes[r.Path] = r.ContentHash
+	}
+	return hashes, nil
+}
+
+// SaveContents stores the text of the repository's files for grep
+func SaveContents(ctx context.Context, repoID int64, texts []Text) error {
+	arg := db.UpsertFileContentsParams{RepoID: repoID, Now: time.Now().UnixNano()}
+	for _, t := range texts {
+		arg.Paths = append(arg.Paths, t.Path)
+		arg.ContentHashes = append(arg.ContentHashes, t.ContentHash)
+		arg.Contents = append(arg.Contents, t.Content)
+	}
+	return db.Query(ctx, func(q *db.Queries) error {
+		return q.UpsertFileContents(ctx, arg)
+	})
+}
+
+// DeleteContents removes the stored text of the repository's files at paths
+func DeleteContents(ctx context.Context, repoID int64, paths []string) error {
+	return db.Query(ctx, func(q *db.Queries) error {
+		return q.DeleteFileContentsByPaths(ctx, db.DeleteFileContentsByPathsParams{
+			RepoID: repoID,
+			Paths:  paths,
+		})
+	})
+}
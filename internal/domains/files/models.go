@@ -0,0 +1,73 @@
+package files
+
+// ReadParams selects the part of a file to return
+type ReadParams struct {
+	Path string
+	// StartLine and EndLine are 1-based and inclusive; 0 means the file's
+	// first and last line
+	StartLine int
+	EndLine   int
+	// Expand adds this many lines around the range
+	Expand int
+	// ExpandSymbol widens the range to the declaration or section enclosing it
+	ExpandSymbol bool
+}
+
+// Content is a range of lines from a file at the repository's indexed commit.
+// The range of an empty file is 0 to 0.
+type Content struct {
+	RepoID     int64  `json:"repo_id"`
+	Path       string `json:"path"`
+	Commit     string `json:"commit"`
+	Language   string `json:"language"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	TotalLines int    `json:"total_lines"`
+	Content    string `json:"content"`
+	// SymbolName and ChunkType describe the enclosing symbol when one was requested and found
+	SymbolName *string `json:"symbol_name,omitempty"`
+	ChunkType  *string `json:"chunk_type,omitempty"`
+}
+
+// File is the indexed state of one file in a repository's checkout
+type File struct {
+	ID   int64
+	Path string
+	// ContentHash is the SHA-256 of the content last indexed; empty while
+	// the file's chunks are being replaced
+	ContentHash string
+	ChunkCount  int32
+}
+
+// Entry describes a file whose chunks were just indexed
+type Entry struct {
+	Path        string
+	ContentHash string
+	Language    string
+	SizeBytes   int64
+	LineCount   int32
+	ChunkCount  int32
+}
+
+// Text is the content of a file, stored so grep can search it
+type Text struct {
+	Path        string
+	ContentHash string
+	Content     string
+}
+
+// ListParams selects a page of a workspace's indexed files
+type ListParams struct {
+	WorkspaceID int64
+	AfterID     int64
+	Limit       int
+}
+
+// Listed is an indexed file as returned by ListByWorkspace
+type Listed struct {
+	ID        int64
+	RepoID    int64
+	Path      string
+	Language  string
+	SizeBytes int64
+}
@@ -0,0 +1,154 @@
+// Package files reads and tracks the files of indexed repositories
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gomantics/semantix/internal/domains/repos"
+	"github.com/gomantics/semantix/config"
+	"github.com/gomantics/semantix/pkg/chunking"
+	"github.com/gomantics/semantix/pkg/gitrepo"
+)
+
+var (
+	ErrInvalidPath   = errors.New("path must be relative to the repository root")
+	ErrNotFound      = errors.New("file not found")
+	ErrNotAFile      = errors.New("path is not a file")
+	ErrTooLarge      = errors.New("file is larger than the indexing size limit")
+	ErrBinary        = errors.New("file is binary")
+	ErrInvalidRange  = errors.New("invalid line range")
+	ErrInvalidExpand = fmt.Errorf("expand must be between 0 and %d", MaxExpand)
+	// ErrNotIndexed is returned until the repository's first index completes
+	ErrNotIndexed = errors.New("repository has not been indexed yet")
+)
+
+// MaxExpand bounds the context lines added around a range
+const MaxExpand = 500
+
+// Read returns lines of a file as committed at the repository's indexed
+// commit, which a re-index in progress doesn't move. Files over the indexing
+// size limit aren't served.
+func Read(ctx context.Context, repo *repos.Repo, params ReadParams) (*Content, error) {
+	p, err := cleanPath(params.Path)
+	if err != nil {
+		return nil, err
+	}
+	if params.Expand < 0 || params.Expand > MaxExpand {
+		return nil, ErrInvalidExpand
+	}
+	if params.StartLine < 0 || params.EndLine < 0 ||
+		(params.EndLine > 0 && params.StartLine > params.EndLine) {
+		return nil, ErrInvalidRange
+	}
+	if repo.IndexedCommit == nil {
+		return nil, ErrNotIndexed
+	}
+
+	src, err := gitrepo.ReadFile(ctx, repos.CheckoutDir(repo.WorkspaceID, repo.ID), *repo.IndexedCommit, p,
+		config.Indexing.MaxFileSizeBytes())
+	if err != nil {
+		switch {
+		case errors.Is(err, gitrepo.ErrFileNotFound):
+			return nil, ErrNotFound
+		case errors.Is(err, gitrepo.ErrNotAFile):
+			return nil, ErrNotAFile
+		case errors.Is(err, gitrepo.ErrFileTooLarge):
+			return nil, ErrTooLarge
+		}
+		return nil, err
+	}
+	if chunking.IsBinary(src) {
+		return nil, ErrBinary
+	}
+
+	lines := splitLines(string(src))
+	total := len(lines)
+
+	start, end := params.StartLine, params.EndLine
+	if start == 0 {
+		start = 1
+	}
+	if end == 0 || end > total {
+		end = total
+	}
+	// An empty file has no lines to start at, though it can be read whole
+	if params.StartLine > total {
+		return nil, ErrInvalidRange
+	}
+
+	content := &Content{
+		RepoID:     repo.ID,
+		Path:       p,
+		Commit:     *repo.IndexedCommit,
+		Language:   chunking.Detect(p, src),
+		TotalLines: total,
+	}
+	if total == 0 {
+		return content, nil
+	}
+
+	if params.ExpandSymbol {
+		if sym := enclosingSymbol(p, src, start, end); sym != nil {
+			start, end = sym.StartLine, sym.EndLine
+			content.SymbolName, content.ChunkType = &sym.SymbolName, &sym.ChunkType
+		}
+	}
+	start, end = max(1, start-params.Expand), min(total, end+params.Expand)
+
+	content.StartLine, content.EndLine = start, end
+	content.Content = strings.Join(lines[start-1:end], "\n")
+	return content, nil
+}
+
+// cleanPath normalises a repository-relative path, rejecting anything that
+// could name a file outside the repository
+func cleanPath(p string) (string, error) {
+	if p == "" || strings.ContainsAny(p, "\x00\n\r") {
+		return "", ErrInvalidPath
+	}
+	clean := path.Clean(p)
+	if path.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", ErrInvalidPath
+	}
+	return clean, nil
+}
+
+// splitLines splits text into lines; a trailing newline doesn't start another
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// enclosingSymbol returns the smallest named declaration or section
+// containing lines start..end, or nil if there is none. The file is chunked
+// without a size limit so declarations come back whole.
+func enclosingSymbol(p string, src []byte, start, end int) *chunking.Chunk {
+	reg := chunking.NewRegistry(chunking.Options{
+		MaxTokens:    chunking.EstimateTokens(string(src)) + 1,
+		OverlapLines: -1,
+	})
+	chunks, err := reg.Chunk(p, src)
+	if err != nil {
+		return nil
+	}
+
+	var best *chunking.Chunk
+	for i, c := range chunks {
+		if c.SymbolName == "" || c.ChunkType == chunking.TypeBlock {
+			continue
+		}
+		if c.StartLine > start || c.EndLine < end {
+			continue
+		}
+		if best == nil || c.EndLine-c.StartLine < best.EndLine-best.StartLine {
+			best = &chunks[i]
+		}
+	}
+	return best
+}
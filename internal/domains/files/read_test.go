@@ -0,0 +1,107 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gomantics/semantix/internal/domains/repos"
+)
+
+// checkout commits files to a repository in a fresh clone directory and
+// returns the repository, indexed at that commit
+func checkout(t *testing.T, files map[string]string) *repos.Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("CONFIG_INDEXING_CLONE_DIR", t.TempDir())
+
+	repo := &repos.Repo{ID: 2, WorkspaceID: 1}
+	dir := repos.CheckoutDir(repo.WorkspaceID, repo.ID)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "test")
+	commit := git("rev-parse", "HEAD")
+	repo.IndexedCommit = &commit
+	return repo
+}
+
+func TestRead(t *testing.T) {
+	repo := checkout(t, map[string]string{
+		"empty.txt":   "",
+		"lines.txt":   "one\ntwo\nthree\nfour\nfive\n",
+		"bin/data":    "\x00\x01\x02",
+		"docs/one.md": "# One",
+	})
+
+	tests := []struct {
+		name       string
+		params     ReadParams
+		start, end int
+		content    string
+		wantErr    error
+	}{
+		{name: "whole file", params: ReadParams{Path: "lines.txt"}, start: 1, end: 5, content: "one\ntwo\nthree\nfour\nfive"},
+		{name: "range", params: ReadParams{Path: "lines.txt", StartLine: 2, EndLine: 3}, start: 2, end: 3, content: "two\nthree"},
+		{name: "end past the file", params: ReadParams{Path: "lines.txt", StartLine: 4, EndLine: 10}, start: 4, end: 5, content: "four\nfive"},
+		{name: "expand", params: ReadParams{Path: "lines.txt", StartLine: 3, EndLine: 3, Expand: 1}, start: 2, end: 4, content: "two\nthree\nfour"},
+		{name: "expand clamped", params: ReadParams{Path: "lines.txt", StartLine: 1, EndLine: 1, Expand: 10}, start: 1, end: 5, content: "one\ntwo\nthree\nfour\nfive"},
+		{name: "no trailing newline", params: ReadParams{Path: "docs/one.md"}, start: 1, end: 1, content: "# One"},
+		{name: "start past the file", params: ReadParams{Path: "lines.txt", StartLine: 6}, wantErr: ErrInvalidRange},
+		{name: "start after end", params: ReadParams{Path: "lines.txt", StartLine: 3, EndLine: 2}, wantErr: ErrInvalidRange},
+
+		// An empty file reads as no lines, but has no line to start at
+		{name: "empty file", params: ReadParams{Path: "empty.txt"}},
+		{name: "empty file expanded", params: ReadParams{Path: "empty.txt", Expand: 5, ExpandSymbol: true}},
+		{name: "empty file with end", params: ReadParams{Path: "empty.txt", EndLine: 3}},
+		{name: "empty file with start", params: ReadParams{Path: "empty.txt", StartLine: 1}, wantErr: ErrInvalidRange},
+
+		{name: "missing", params: ReadParams{Path: "nope.txt"}, wantErr: ErrNotFound},
+		{name: "directory", params: ReadParams{Path: "docs"}, wantErr: ErrNotAFile},
+		{name: "binary", params: ReadParams{Path: "bin/data"}, wantErr: ErrBinary},
+		{name: "escaping path", params: ReadParams{Path: "../lines.txt"}, wantErr: ErrInvalidPath},
+		{name: "expand too far", params: ReadParams{Path: "lines.txt", Expand: MaxExpand + 1}, wantErr: ErrInvalidExpand},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(context.Background(), repo, tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Read() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.StartLine != tt.start || got.EndLine != tt.end || got.Content != tt.content {
+				t.Errorf("Read() = lines %d-%d %q, want %d-%d %q",
+					got.StartLine, got.EndLine, got.Content, tt.start, tt.end, tt.content)
+			}
+			if got.StartLine > got.EndLine {
+				t.Errorf("Read() returned the inverted range %d-%d", got.StartLine, got.EndLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package gittokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/pgconv"
+	"github.com/gomantics/semantix/pkg/secrets"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const rotateBatchSize = 100
+
+var (
+	ErrNotFound        = errors.New("git token not found")
+	ErrInUse           = errors.New("git token is used by one or more repositories")
+	ErrInvalidProvider = errors.New("provider must be one of github, gitlab, bitbucket or generic")
+	ErrInvalidHost     = errors.New("host must be a hostname with an optional port")
+	ErrHostRequired    = errors.New("host is required for generic tokens")
+)
+
+// providers maps each provider to the host its tokens are for by default
+var providers = map[string]string{
+	"github":    "github.com",
+	"gitlab":    "gitlab.com",
+	"bitbucket": "bitbucket.org",
+	"generic":   "",
+}
+
+func Create(ctx context.Context, params CreateParams) (*GitToken, error) {
+	host, err := tokenHost(params.Provider, params.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	ring, err := loadKeyring()
+	if err != nil {
+		return nil, fmt.Errorf("token encryption unavailable: %w", err)
+	}
+
+	var hint pgtype.Text
+	if h := params.Token.Hint(); h != "" {
+		hint = pgtype.Text{String: h, Valid: true}
+	}
+
+	now := time.Now().UnixNano()
+	dbToken, err := db.Tx1(ctx, func(q *db.Queries) (db.GitToken, error) {
+		// The ID is part of the additional data, so it's needed before sealing
+		id, err := q.NextGitTokenID(ctx)
+		if err != nil {
+			return db.GitToken{}, err
+		}
+
+		keyID, sealed, err := seal(ring, id, []byte(params.Token.Reveal()))
+		if err != nil {
+			return db.GitToken{}, err
+		}
+
+		return q.CreateGitToken(ctx, db.CreateGitTokenParams{
+			ID:             id,
+			Name:           params.Name,
+			Provider:       params.Provider,
+			TokenEncrypted: sealed,
+			KeyID:          keyID,
+			TokenHint:      hint,
+			AadVersion:     aadVersion,
+			WorkspaceID:    pgconv.ToInt8(params.WorkspaceID),
+			Host:           pgconv.ToText(&host),
+			Created:        now,
+			Updated:        now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toGitToken(dbToken), nil
+}
+
+func GetByID(ctx context.Context, id int64) (*GitToken, error) {
+	dbToken, err := getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toGitToken(dbToken), nil
+}
+
+// Reveal decrypts a stored token for use when cloning.
+// The returned token redacts itself if logged; call Reveal on it only at the point of use.
+func Reveal(ctx context.Context, id int64) (*Credential, error) {
+	dbToken, err := getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ring, err := loadKeyring()
+	if err != nil {
+		return nil, fmt.Errorf("token encryption unavailable: %w", err)
+	}
+
+	plaintext, err := open(ring, dbToken)
+	if err != nil {
+		return nil, fmt.Errorf("git token %d: %w", id, err)
+	}
+
+	return &Credential{
+		Host:  dbToken.Host.String,
+		Token: secrets.Value(plaintext),
+	}, nil
+}
+
+// List retrieves a workspace's git tokens, or the shared ones, with pagination
+func List(ctx context.Context, params ListParams) (*ListResult, error) {
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	type listData struct {
+		tokens []db.GitToken
+		total  int64
+	}
+
+	data, err := db.Tx1(ctx, func(q *db.Queries) (listData, error) {
+		dbTokens, err := q.ListGitTokens(ctx, db.ListGitTokensParams{
+			WorkspaceID: pgconv.ToInt8(params.WorkspaceID),
+			Limit:       int32(params.Limit),
+			Offset:      int32(params.Offset),
+		})
+		if err != nil {
+			return listData{}, err
+		}
+
+		total, err := q.CountGitTokens(ctx, pgconv.ToInt8(params.WorkspaceID))
+		if err != nil {
+			return listData{}, err
+		}
+
+		return listData{tokens: dbTokens, total: total}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]GitToken, len(data.tokens))
+	for i, dbToken := range data.tokens {
+		tokens[i] = *toGitToken(dbToken)
+	}
+
+	return &ListResult{GitTokens: tokens, Total: data.total}, nil
+}
+
+// Delete removes a git token that no repository references. workspaceID is
+// nil for a shared token; tokens of other workspaces are not found.
+func Delete(ctx context.Context, workspaceID *int64, id int64) error {
+	return db.Tx(ctx, func(q *db.Queries) error {
+		dbToken, err := q.GetGitTokenByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if !BelongsTo(dbToken, workspaceID) {
+			return ErrNotFound
+		}
+
+		inUse, err := q.CountReposByGitToken(ctx, pgtype.Int8{Int64: id, Valid: true})
+		if err != nil {
+			return err
+		}
+		if inUse > 0 {
+			return ErrInUse
+		}
+
+		return q.DeleteGitToken(ctx, id)
+	})
+}
+
+// RotateKeys re-encrypts every token not sealed with the active key or not
+// bound to its row. Run it after adding a new key and making it active; the
+// old key can be removed from the ring once this reports no remaining tokens.
+func RotateKeys(ctx context.Context, l *zap.Logger) (int, error) {
+	ring, err := loadKeyring()
+	if err != nil {
+		return 0, err
+	}
+
+	active := ring.ActiveKeyID()
+	rotated := 0
+	for {
+		n, err := db.Tx1(ctx, func(q *db.Queries) (int, error) {
+			dbTokens, err := q.ListGitTokensToReseal(ctx, db.ListGitTokensToResealParams{
+				KeyID:      active,
+				AadVersion: aadVersion,
+				Limit:      rotateBatchSize,
+			})
+			if err != nil {
+				return 0, err
+			}
+
+			now := time.Now().UnixNano()
+			for _, dbToken := range dbTokens {
+				plaintext, err := open(ring, dbToken)
+				if err != nil {
+					return 0, fmt.Errorf("git token %d: %w", dbToken.ID, err)
+				}
+
+				keyID, sealed, err := seal(ring, dbToken.ID, plaintext)
+				if err != nil {
+					return 0, err
+				}
+
+				err = q.UpdateGitTokenCiphertext(ctx, db.UpdateGitTokenCiphertextParams{
+					ID:             dbToken.ID,
+					TokenEncrypted: sealed,
+					KeyID:          keyID,
+					AadVersion:     aadVersion,
+					Updated:        now,
+				})
+				if err != nil {
+					return 0, err
+				}
+
+				l.Info("re-encrypted git token",
+					zap.Int64("git_token_id", dbToken.ID),
+					zap.String("from_key_id", dbToken.KeyID),
+					zap.String("to_key_id", keyID),
+				)
+			}
+
+			return len(dbTokens), nil
+		})
+		if err != nil {
+			return rotated, err
+		}
+
+		rotated += n
+		if n < rotateBatchSize {
+			return rotated, nil
+		}
+	}
+}
+
+// tokenHost returns the host a new token is for: the given one, normalised,
+// or the provider's default
+func tokenHost(provider, host string) (string, error) {
+	fallback, ok := providers[provider]
+	if !ok {
+		return "", ErrInvalidProvider
+	}
+
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" {
+		if fallback == "" {
+			return "", ErrHostRequired
+		}
+		return fallback, nil
+	}
+
+	u, err := url.Parse("https://" + host)
+	if err != nil || u.Host != host || u.Hostname() == "" || u.User != nil {
+		return "", ErrInvalidHost
+	}
+	return host, nil
+}
+
+// BelongsTo reports whether a token is scoped to the workspace, or shared
+// when workspaceID is nil
+func BelongsTo(dbToken db.GitToken, workspaceID *int64) bool {
+	if workspaceID == nil {
+		return !dbToken.WorkspaceID.Valid
+	}
+	return dbToken.WorkspaceID.Valid && dbToken.WorkspaceID.Int64 == *workspaceID
+}
+
+func getByID(ctx context.Context, id int64) (db.GitToken, error) {
+	dbToken, err := db.Query1(ctx, func(q *db.Queries) (db.GitToken, error) {
+		return q.GetGitTokenByID(ctx, id)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GitToken{}, ErrNotFound
+		}
+		return db.GitToken{}, err
+	}
+	return dbToken, nil
+}
+
+func toGitToken(dbToken db.GitToken) *GitToken {
+	hint := strings.Repeat("*", 8)
+	if h := pgconv.FromText(dbToken.TokenHint); h != nil {
+		hint += *h
+	}
+
+	return &GitToken{
+		ID:          dbToken.ID,
+		WorkspaceID: pgconv.FromInt8(dbToken.WorkspaceID),
+		Name:        dbToken.Name,
+		Provider:    dbToken.Provider,
+		Host:        dbToken.Host.String,
+		TokenHint:   hint,
+		Created:     dbToken.Created,
+		Updated:     dbToken.Updated,
+	}
+}
@@ -0,0 +1,56 @@
+package gittokens
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestBelongsTo(t *testing.T) {
+	workspace := db.GitToken{WorkspaceID: pgtype.Int8{Int64: 1, Valid: true}}
+	shared := db.GitToken{}
+	one, two := int64(1), int64(2)
+
+	tests := []struct {
+		name        string
+		token       db.GitToken
+		workspaceID *int64
+		want        bool
+	}{
+		{name: "same workspace", token: workspace, workspaceID: &one, want: true},
+		{name: "other workspace", token: workspace, workspaceID: &two},
+		{name: "workspace token as shared", token: workspace},
+		{name: "shared", token: shared, want: true},
+		{name: "shared token in a workspace", token: shared, workspaceID: &one},
+	}
+	for _, tt := range tests {
+		if got := BelongsTo(tt.token, tt.workspaceID); got != tt.want {
+			t.Errorf("%s: BelongsTo = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTokenHost(t *testing.T) {
+	tests := []struct {
+		provider, host string
+		want           string
+		wantErr        error
+	}{
+		{provider: "github", want: "github.com"},
+		{provider: "github", host: " GitHub.Acme.com ", want: "github.acme.com"},
+		{provider: "gitlab", host: "git.acme.com:8443", want: "git.acme.com:8443"},
+		{provider: "generic", wantErr: ErrHostRequired},
+		{provider: "generic", host: "https://git.acme.com", wantErr: ErrInvalidHost},
+		{provider: "generic", host: "git.acme.com/path", wantErr: ErrInvalidHost},
+		{provider: "generic", host: "user@git.acme.com", wantErr: ErrInvalidHost},
+		{provider: "gitea", wantErr: ErrInvalidProvider},
+	}
+	for _, tt := range tests {
+		got, err := tokenHost(tt.provider, tt.host)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("tokenHost(%q, %q) = %q, %v, want %q, %v", tt.provider, tt.host, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package gittokens
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gomantics/semantix/config"
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/secrets"
+)
+
+// aadVersion is the additional data newly sealed tokens are bound with
+const aadVersion = 2
+
+// legacyAdditionalData bound version 1 ciphertexts to this table only
+var legacyAdditionalData = []byte("git_tokens")
+
+// additionalData binds a ciphertext to its row and key, so it can't be
+// replayed in another row or table
+func additionalData(id int64, keyID string) []byte {
+	return fmt.Appendf(nil, "git_tokens:%d:%s", id, keyID)
+}
+
+// seal encrypts a token for the row with the active key
+func seal(ring *secrets.Keyring, id int64, plaintext []byte) (string, []byte, error) {
+	return ring.Encrypt(plaintext, additionalData(id, ring.ActiveKeyID()))
+}
+
+// open decrypts a stored token with the additional data it was sealed with
+func open(ring *secrets.Keyring, t db.GitToken) ([]byte, error) {
+	aad := additionalData(t.ID, t.KeyID)
+	if t.AadVersion < aadVersion {
+		aad = legacyAdditionalData
+	}
+	return ring.Decrypt(t.KeyID, t.TokenEncrypted, aad)
+}
+
+var loadKeyring = sync.OnceValues(func() (*secrets.Keyring, error) {
+	return secrets.ParseKeyring(config.Encryption.Keys(), config.Encryption.ActiveKeyId())
+})
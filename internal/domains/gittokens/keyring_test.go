@@ -0,0 +1,49 @@
+package gittokens
+
+import (
+	"testing"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/secrets"
+)
+
+func testRing(t *testing.T) *secrets.Keyring {
+	t.Helper()
+	ring, err := secrets.ParseKeyring("k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ring
+}
+
+func TestSealBindsRow(t *testing.T) {
+	ring := testRing(t)
+	keyID, sealed, err := seal(ring, 7, []byte("ghp_secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := db.GitToken{ID: 7, KeyID: keyID, TokenEncrypted: sealed, AadVersion: aadVersion}
+	plaintext, err := open(ring, row)
+	if err != nil || string(plaintext) != "ghp_secret" {
+		t.Fatalf("open own row = %q, %v", plaintext, err)
+	}
+
+	row.ID = 8
+	if _, err := open(ring, row); err == nil {
+		t.Error("ciphertext copied to another row still decrypts")
+	}
+}
+
+func TestOpenLegacy(t *testing.T) {
+	ring := testRing(t)
+	keyID, sealed, err := ring.Encrypt([]byte("ghp_secret"), legacyAdditionalData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := open(ring, db.GitToken{ID: 3, KeyID: keyID, TokenEncrypted: sealed, AadVersion: 1})
+	if err != nil || string(plaintext) != "ghp_secret" {
+		t.Fatalf("open legacy row = %q, %v", plaintext, err)
+	}
+}
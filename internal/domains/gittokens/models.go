@@ -0,0 +1,50 @@
+package gittokens
+
+import "github.com/gomantics/semantix/pkg/secrets"
+
+// GitToken is a stored git credential. The plaintext token is never part of
+// this model; only a masked hint is exposed.
+type GitToken struct {
+	ID int64 `json:"id"`
+	// WorkspaceID is nil for tokens shared across workspaces
+	WorkspaceID *int64 `json:"workspace_id,omitempty"`
+	Name        string `json:"name"`
+	Provider    string `json:"provider"`
+	// Host is the only host the token is sent to; empty for old generic
+	// tokens that can't be used
+	Host      string `json:"host"`
+	TokenHint string `json:"token_hint"`
+	Created   int64  `json:"created"`
+	Updated   int64  `json:"updated"`
+}
+
+// CreateParams are the parameters for storing a git token
+type CreateParams struct {
+	// WorkspaceID is nil for a token shared across workspaces
+	WorkspaceID *int64
+	Name        string
+	Provider    string
+	// Host defaults to the provider's public host; generic tokens need one
+	Host  string
+	Token secrets.Value
+}
+
+// ListParams are the parameters for listing git tokens
+type ListParams struct {
+	// WorkspaceID is nil to list shared tokens
+	WorkspaceID *int64
+	Limit       int
+	Offset      int
+}
+
+// ListResult contains the result of listing git tokens
+type ListResult struct {
+	GitTokens []GitToken
+	Total     int64
+}
+
+// Credential is a revealed token with the host it may be sent to
+type Credential struct {
+	Host  string
+	Token secrets.Value
+}
@@ -0,0 +1,219 @@
+// Package grep searches the indexed text of repositories for exact text and
+// regular expressions
+package grep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/glob"
+)
+
+var (
+	ErrEmptyPattern   = errors.New("pattern is required")
+	ErrPatternTooLong = fmt.Errorf("pattern must be at most %d bytes", MaxPatternLength)
+	ErrInvalidPattern = errors.New("invalid regular expression")
+	ErrInvalidMode    = errors.New("mode must be literal or regex")
+	ErrInvalidGlob    = errors.New("invalid path glob")
+	ErrInvalidContext = fmt.Errorf("context lines must be between 0 and %d", MaxContextLines)
+)
+
+const (
+	DefaultMaxMatches = 100
+	// MaxMatches is the most matches one request can return
+	MaxMatches       = 1000
+	MaxContextLines  = 10
+	MaxPatternLength = 1000
+	// MaxLineBytes bounds each returned line; longer lines are cut
+	MaxLineBytes = 1000
+	// MaxFiles bounds the candidate files read by one request
+	MaxFiles = 5000
+	// Timeout bounds the time one request spends searching
+	Timeout = 10 * time.Second
+
+	// pageSize is the number of candidate files fetched at a time
+	pageSize = 50
+	// maxRangesPerLine bounds the match offsets reported for one line
+	maxRangesPerLine = 20
+)
+
+// Grep searches the text stored for the workspace's repositories at their
+// indexed commits, line by line. That covers every text file the indexer
+// walks, including generated files and lockfiles that aren't chunked. The
+// trigram index on the stored text narrows the search to files containing
+// the pattern's literal parts, and only those files are read. Hitting a
+// limit returns the matches found so far with Truncated set.
+func Grep(ctx context.Context, params Params) (*Result, error) {
+	re, lits, err := compile(params)
+	if err != nil {
+		return nil, err
+	}
+	if params.ContextLines < 0 || params.ContextLines > MaxContextLines {
+		return nil, ErrInvalidContext
+	}
+	if params.MaxMatches <= 0 || params.MaxMatches > MaxMatches {
+		params.MaxMatches = DefaultMaxMatches
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	result := &Result{Matches: []Match{}}
+	patterns := lits.likePatterns()
+	var afterRepoID int64
+	var afterPath string
+	for {
+		limit := min(pageSize, MaxFiles-result.FilesSearched+1)
+		page, err := db.Query1(ctx, func(q *db.Queries) ([]db.ListFileContentsMatchingRow, error) {
+			return q.ListFileContentsMatching(ctx, db.ListFileContentsMatchingParams{
+				WorkspaceID: params.WorkspaceID,
+				RepoIds:     params.RepoIDs,
+				Patterns:    patterns,
+				AfterRepoID: afterRepoID,
+				AfterPath:   afterPath,
+				Limit:       int32(limit),
+			})
+		})
+		if err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				result.Truncated = LimitTime
+				return result, nil
+			}
+			return nil, err
+		}
+
+		for _, f := range page {
+			// Every file read counts, whether or not the globs keep it
+			if result.FilesSearched == MaxFiles {
+				result.Truncated = LimitFiles
+				return result, nil
+			}
+			result.FilesSearched++
+			afterRepoID, afterPath = f.RepoID, f.Path
+
+			if len(params.Include) > 0 && !glob.MatchAny(params.Include, f.Path) {
+				continue
+			}
+			if glob.MatchAny(params.Exclude, f.Path) {
+				continue
+			}
+
+			remaining := params.MaxMatches - len(result.Matches)
+			matches := searchFile(re, f.Content, params.ContextLines, remaining+1)
+			for i := range matches {
+				matches[i].RepoID = f.RepoID
+				matches[i].FilePath = f.Path
+			}
+			if len(matches) > remaining {
+				result.Matches = append(result.Matches, matches[:remaining]...)
+				result.Truncated = LimitMatches
+				return result, nil
+			}
+			result.Matches = append(result.Matches, matches...)
+		}
+
+		if len(page) < limit {
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			result.Truncated = LimitTime
+			return result, nil
+		}
+	}
+}
+
+// compile validates the request and returns its regular expression with the
+// literals every match contains
+func compile(params Params) (*regexp.Regexp, literals, error) {
+	if params.Pattern == "" {
+		return nil, nil, ErrEmptyPattern
+	}
+	if len(params.Pattern) > MaxPatternLength {
+		return nil, nil, ErrPatternTooLong
+	}
+	for _, patterns := range [][]string{params.Include, params.Exclude} {
+		for _, p := range patterns {
+			if err := glob.Validate(p); err != nil {
+				return nil, nil, fmt.Errorf("%w: %q", ErrInvalidGlob, p)
+			}
+		}
+	}
+
+	var expr string
+	switch params.Mode {
+	case ModeLiteral, "":
+		expr = regexp.QuoteMeta(params.Pattern)
+	case ModeRegex:
+		expr = params.Pattern
+	default:
+		return nil, nil, ErrInvalidMode
+	}
+	if params.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+
+	parsed, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidPattern, err.Error())
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidPattern, err.Error())
+	}
+	return re, requiredLiterals(parsed), nil
+}
+
+// searchFile returns up to limit matching lines of content
+func searchFile(re *regexp.Regexp, content string, contextLines, limit int) []Match {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	var matches []Match
+	for i, line := range lines {
+		locs := re.FindAllStringIndex(line, maxRangesPerLine)
+		if locs == nil {
+			continue
+		}
+
+		m := Match{LineNumber: i + 1, Line: truncate(line)}
+		for _, loc := range locs {
+			if loc[0] > len(m.Line) {
+				break
+			}
+			m.Ranges = append(m.Ranges, [2]int{loc[0], min(loc[1], len(m.Line))})
+		}
+		for _, l := range lines[max(0, i-contextLines):i] {
+			m.Before = append(m.Before, truncate(l))
+		}
+		for _, l := range lines[i+1 : min(len(lines), i+1+contextLines)] {
+			m.After = append(m.After, truncate(l))
+		}
+
+		matches = append(matches, m)
+		if len(matches) == limit {
+			break
+		}
+	}
+	return matches
+}
+
+// truncate cuts a line to MaxLineBytes without splitting a character
+func truncate(line string) string {
+	if len(line) <= MaxLineBytes {
+		return line
+	}
+	cut := line[:MaxLineBytes]
+	for !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	return cut
+}
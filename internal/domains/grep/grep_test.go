@@ -0,0 +1,122 @@
+package grep
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gomantics/semantix/internal/domains/files"
+	"github.com/gomantics/semantix/internal/domains/repos"
+	"github.com/gomantics/semantix/internal/testdb"
+)
+
+func TestLikePatterns(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		mode       Mode
+		ignoreCase bool
+		want       []string
+		// matching is content the pattern matches, which must satisfy
+		// every ILIKE pattern
+		matching string
+	}{
+		{pattern: "func (s *Server)", want: []string{"%func (s *Server)%"}, matching: "func (s *Server) Start()"},
+		{pattern: "100%_done", want: []string{`%100\%\_done%`}, matching: "100%_done"},
+		{pattern: `C:\dir`, want: []string{`%C:\\dir%`}, matching: `C:\dir`},
+		{pattern: `func\s+\w+Handler\(`, mode: ModeRegex, want: []string{"%Handler(%", "%func%"}, matching: "func listHandler("},
+		// Alternations have no required literal, so every file is a candidate
+		{pattern: "alpha|beta", mode: ModeRegex, want: []string{}, matching: "beta"},
+		// (?i) pairs k with the Kelvin sign and s with the long s, ILIKE doesn't
+		{pattern: "kelvins", ignoreCase: true, want: []string{"%ELVIN%"}, matching: "\u212aelvin\u017f"},
+		{pattern: "Straße", ignoreCase: true, want: []string{"%TRA%", "%E%"}, matching: "STRAẞE"},
+		{pattern: `(?i:config)\.Load`, mode: ModeRegex, want: []string{"%CONFIG%", "%.Load%"}, matching: "CONFIG.Load"},
+	}
+	for _, tt := range tests {
+		re, lits, err := compile(Params{Pattern: tt.pattern, Mode: tt.mode, IgnoreCase: tt.ignoreCase})
+		if err != nil {
+			t.Fatalf("compile(%q): %v", tt.pattern, err)
+		}
+		got := lits.likePatterns()
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("likePatterns(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+
+		if !re.MatchString(tt.matching) {
+			t.Fatalf("%q doesn't match %q", tt.pattern, tt.matching)
+		}
+		for _, p := range got {
+			if !ilike(tt.matching, p) {
+				t.Errorf("%q matches %q, which fails ILIKE %q", tt.pattern, tt.matching, p)
+			}
+		}
+	}
+}
+
+// ilike reports whether s satisfies a %...% pattern the way Postgres ILIKE
+// compares, lowercasing both sides
+func ilike(s, pattern string) bool {
+	r := strings.NewReplacer(`\\`, `\`, `\%`, `%`, `\_`, `_`)
+	sub := r.Replace(strings.TrimSuffix(strings.TrimPrefix(pattern, "%"), "%"))
+	return strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+}
+
+func TestGrep(t *testing.T) {
+	testdb.Connect(t)
+	ctx := context.Background()
+	ws := testdb.Workspace(t)
+	other := testdb.Workspace(t)
+
+	repo, err := repos.Create(ctx, repos.CreateParams{WorkspaceID: ws.ID, URL: "https://github.com/acme/grep"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRepo, err := repos.Create(ctx, repos.CreateParams{WorkspaceID: other.ID, URL: "https://github.com/acme/grep"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := []files.Text{
+		{Path: "main.go", ContentHash: "a", Content: "package main\n\n// needle in source\n"},
+		{Path: "package-lock.json", ContentHash: "b", Content: `{"name": "NEEDLE"}`},
+		{Path: "docs/notes.md", ContentHash: "c", Content: "nothing here\n"},
+	}
+	if err := files.SaveContents(ctx, repo.ID, texts); err != nil {
+		t.Fatal(err)
+	}
+	secret := []files.Text{{Path: "secret.go", ContentHash: "d", Content: "needle in another workspace"}}
+	if err := files.SaveContents(ctx, otherRepo.ID, secret); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		params Params
+		want   []string
+		// searched is the number of files the index returned
+		searched int
+	}{
+		{params: Params{Pattern: "needle"}, want: []string{"main.go:3"}, searched: 2},
+		{params: Params{Pattern: "needle", IgnoreCase: true}, want: []string{"main.go:3", "package-lock.json:1"}, searched: 2},
+		{params: Params{Pattern: "needle", IgnoreCase: true, Exclude: []string{"*.json"}}, want: []string{"main.go:3"}, searched: 2},
+		{params: Params{Pattern: `no(thing|ne)`, Mode: ModeRegex}, want: []string{"docs/notes.md:1"}, searched: 3},
+		{params: Params{Pattern: "absent"}, want: nil, searched: 0},
+	}
+	for _, tt := range tests {
+		tt.params.WorkspaceID = ws.ID
+		result, err := Grep(ctx, tt.params)
+		if err != nil {
+			t.Fatalf("Grep(%q): %v", tt.params.Pattern, err)
+		}
+		var got []string
+		for _, m := range result.Matches {
+			got = append(got, fmt.Sprintf("%s:%d", m.FilePath, m.LineNumber))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Grep(%q) matched %v, want %v", tt.params.Pattern, got, tt.want)
+		}
+		if result.FilesSearched != tt.searched {
+			t.Errorf("Grep(%q) searched %d files, want %d", tt.params.Pattern, result.FilesSearched, tt.searched)
+		}
+	}
+}
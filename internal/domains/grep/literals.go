@@ -0,0 +1,86 @@
+package grep
+
+import (
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// literal is a string that every match contains
+type literal struct {
+	text string
+	// fold is set for literals matched case-insensitively
+	fold bool
+}
+
+// literals are the strings every match of a pattern contains. They select
+// candidate files through the trigram index; the regular expression still
+// decides what matches.
+type literals []literal
+
+// requiredLiterals returns the literals every match of re contains, longest
+// first. An empty result means every file is a candidate.
+func requiredLiterals(re *syntax.Regexp) literals {
+	lits := literals(collectLiterals(re.Simplify()))
+	sort.SliceStable(lits, func(i, j int) bool { return len(lits[i].text) > len(lits[j].text) })
+	return lits
+}
+
+func collectLiterals(re *syntax.Regexp) []literal {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []literal{{text: string(re.Rune), fold: re.Flags&syntax.FoldCase != 0}}
+	case syntax.OpCapture, syntax.OpPlus:
+		return collectLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return collectLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var lits []literal
+		for _, sub := range re.Sub {
+			lits = append(lits, collectLiterals(sub)...)
+		}
+		return lits
+	}
+	// Alternations and optional parts don't have to appear in a match
+	return nil
+}
+
+// likePatterns returns ILIKE patterns that every file containing a match
+// satisfies. ILIKE only pairs a character with its other case, while (?i)
+// also pairs k with the Kelvin sign, s with the long s and many non-ASCII
+// characters with more than one other form, so case-insensitive literals
+// are cut around such characters.
+func (lits literals) likePatterns() []string {
+	// Never nil: a NULL array would match no file
+	patterns := []string{}
+	for _, l := range lits {
+		if !l.fold {
+			patterns = append(patterns, likePattern(l.text))
+			continue
+		}
+		for _, part := range strings.FieldsFunc(l.text, ambiguousCase) {
+			patterns = append(patterns, likePattern(part))
+		}
+	}
+	return patterns
+}
+
+// ambiguousCase reports whether r case-folds to anything but itself and its
+// ASCII other case
+func ambiguousCase(r rune) bool {
+	if r >= utf8.RuneSelf {
+		return true
+	}
+	other := unicode.SimpleFold(r)
+	return other >= utf8.RuneSelf || unicode.SimpleFold(other) != r
+}
+
+// likePattern matches s anywhere in the text with ILIKE
+func likePattern(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(s) + "%"
+}
@@ -0,0 +1,55 @@
+package grep
+
+// Mode is how a pattern is interpreted
+type Mode string
+
+const (
+	ModeLiteral Mode = "literal"
+	// ModeRegex uses RE2 syntax, as accepted by Go's regexp package
+	ModeRegex Mode = "regex"
+)
+
+// Params are the parameters of a grep within a workspace
+type Params struct {
+	WorkspaceID int64
+	Pattern     string
+	Mode        Mode
+	IgnoreCase  bool
+	RepoIDs     []int64
+	// Include keeps only paths matching at least one glob
+	Include []string
+	// Exclude drops paths matching any glob
+	Exclude []string
+	// ContextLines is the number of lines returned before and after each match
+	ContextLines int
+	MaxMatches   int
+}
+
+// Match is one matching line
+type Match struct {
+	RepoID     int64  `json:"repo_id"`
+	FilePath   string `json:"file_path"`
+	LineNumber int    `json:"line_number"`
+	Line       string `json:"line"`
+	// Ranges are the byte offsets [start, end) of each match within Line
+	Ranges [][2]int `json:"ranges"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// Limit names the limit that cut a grep short
+type Limit string
+
+const (
+	LimitMatches Limit = "matches"
+	LimitFiles   Limit = "files"
+	LimitTime    Limit = "time"
+)
+
+// Result contains the matches of a grep
+type Result struct {
+	Matches       []Match
+	FilesSearched int
+	// Truncated is set when a limit stopped the search early
+	Truncated Limit
+}
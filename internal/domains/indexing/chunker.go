@@ -0,0 +1,95 @@
+package indexing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gomantics/semantix/config"
+	"github.com/gomantics/semantix/pkg/chunking"
+	"go.uber.org/zap"
+)
+
+// chunker is shared by all index jobs; the registry and its chunkers are stateless
+var chunker = sync.OnceValue(func() *chunking.Registry {
+	return chunking.NewRegistry(chunking.Options{
+		MaxTokens:        int(config.Indexing.ChunkMaxTokens()),
+		OverlapLines:     int(config.Indexing.ChunkOverlapLines()),
+		MaxFileSizeBytes: config.Indexing.MaxFileSizeBytes(),
+	})
+})
+
+// sourceFile is a file from the checkout
+type sourceFile struct {
+	Path string // slash-separated, relative to the checkout root
+	Hash string // hex SHA-256 of Src
+	Src  []byte
+}
+
+// walkFiles calls fn for every file under dir within the size limit. The
+// content is hashed so fn can tell whether it changed before chunking it.
+func walkFiles(ctx context.Context, l *zap.Logger, dir string, fn func(file sourceFile) error) error {
+	maxSize := config.Indexing.MaxFileSizeBytes()
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Symlinks could point outside the checkout
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		// Check the size before reading so huge files never hit memory
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if maxSize > 0 && info.Size() > maxSize {
+			l.Debug("skipping file", zap.String("path", rel), zap.Error(chunking.ErrTooLarge))
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(src)
+		return fn(sourceFile{Path: rel, Hash: hex.EncodeToString(sum[:]), Src: src})
+	})
+}
+
+// chunkFile chunks a file, returning false for files that aren't indexed
+// (binary, generated)
+func chunkFile(l *zap.Logger, file sourceFile) ([]chunking.Chunk, bool, error) {
+	chunks, err := chunker().Chunk(file.Path, file.Src)
+	if errors.Is(err, chunking.ErrSkipped) {
+		l.Debug("skipping file", zap.String("path", file.Path), zap.Error(err))
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return chunks, true, nil
+}
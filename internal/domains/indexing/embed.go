@@ -0,0 +1,231 @@
+package indexing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/domains/files"
+	"github.com/gomantics/semantix/internal/domains/repos"
+	"github.com/gomantics/semantix/internal/vectordb"
+	"github.com/gomantics/semantix/pkg/chunking"
+	"github.com/gomantics/semantix/pkg/embeddings"
+	"github.com/gomantics/semantix/pkg/vectorstore"
+)
+
+// embedBatch collects added and changed files so small files share
+// embedding requests, then replaces their vectors and records their hashes.
+// The embedder splits oversized batches itself.
+type embedBatch struct {
+	e     embeddings.Embedder
+	store vectorstore.Store
+	// tx is set when the store lives in Postgres, so a file's vectors and
+	// row change in one transaction
+	tx   vectordb.Transactional
+	repo *repos.Repo
+	size int
+
+	files  []batchFile
+	chunks int
+}
+
+// batchFile is a file waiting in the batch with its new chunks
+type batchFile struct {
+	entry  files.Entry
+	chunks []chunking.Chunk
+}
+
+func newEmbedBatch(e embeddings.Embedder, store vectorstore.Store, repo *repos.Repo, size int) *embedBatch {
+	if size <= 0 {
+		size = 512
+	}
+	tx, _ := store.(vectordb.Transactional)
+	return &embedBatch{e: e, store: store, tx: tx, repo: repo, size: size}
+}
+
+// add queues a file; files that no longer produce chunks still pass through
+// so their old vectors are removed
+func (b *embedBatch) add(ctx context.Context, entry files.Entry, chunks []chunking.Chunk) error {
+	b.files = append(b.files, batchFile{entry: entry, chunks: chunks})
+	b.chunks += len(chunks)
+	if b.chunks < b.size && len(b.files) < b.size {
+		return nil
+	}
+	return b.flush(ctx)
+}
+
+func (b *embedBatch) flush(ctx context.Context) error {
+	if len(b.files) == 0 {
+		return nil
+	}
+
+	var inputs []string
+	for _, f := range b.files {
+		for _, c := range f.chunks {
+			inputs = append(inputs, embedInput(c))
+		}
+	}
+	var vectors [][]float32
+	if len(inputs) > 0 {
+		var err error
+		if vectors, err = b.e.Embed(ctx, inputs); err != nil {
+			return fmt.Errorf("embedding failed: %w", err)
+		}
+	}
+
+	entries := make([]files.Entry, len(b.files))
+	for i, f := range b.files {
+		entries[i] = f.entry
+	}
+
+	var err error
+	if b.tx != nil {
+		err = b.replaceTx(ctx, entries, vectors)
+	} else {
+		err = b.replace(ctx, entries, vectors)
+	}
+	if err != nil {
+		return err
+	}
+
+	b.files = b.files[:0]
+	b.chunks = 0
+	return nil
+}
+
+// replaceTx swaps the files' vectors and records their hashes in one
+// transaction
+func (b *embedBatch) replaceTx(ctx context.Context, entries []files.Entry, vectors [][]float32) error {
+	return db.Tx(ctx, func(q *db.Queries) error {
+		ids, err := files.SaveTx(ctx, q, b.repo.ID, entries)
+		if err != nil {
+			return fmt.Errorf("failed to store files: %w", err)
+		}
+		return b.tx.ReplaceTx(ctx, q, b.filter(ids), b.points(ids, vectors))
+	})
+}
+
+// replace swaps the files' vectors in a store outside Postgres. The rows are
+// saved without a hash first, so a run interrupted between the store and the
+// database leaves the files looking changed and the next run redoes them.
+func (b *embedBatch) replace(ctx context.Context, entries []files.Entry, vectors [][]float32) error {
+	dirty := make([]files.Entry, len(entries))
+	for i, e := range entries {
+		dirty[i] = e
+		dirty[i].ContentHash = ""
+	}
+	ids, err := db.Tx1(ctx, func(q *db.Queries) (map[string]int64, error) {
+		return files.SaveTx(ctx, q, b.repo.ID, dirty)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store files: %w", err)
+	}
+
+	// Changed files may now have fewer chunks, so their old points go first
+	if err := b.store.DeleteByFilter(ctx, b.filter(ids)); err != nil {
+		return err
+	}
+	points := b.points(ids, vectors)
+	if len(points) > 0 {
+		if err := b.store.Upsert(ctx, points); err != nil {
+			return err
+		}
+	}
+
+	return db.Tx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteChunksByFileIDs(ctx, idList(ids)); err != nil {
+			return err
+		}
+		if err := upsertChunkRows(ctx, q, points); err != nil {
+			return err
+		}
+		if _, err := files.SaveTx(ctx, q, b.repo.ID, entries); err != nil {
+			return fmt.Errorf("failed to store files: %w", err)
+		}
+		return nil
+	})
+}
+
+// filter matches the current points of the batch's files
+func (b *embedBatch) filter(ids map[string]int64) vectorstore.Filter {
+	return vectorstore.Filter{RepoIDs: []int64{b.repo.ID}, FileIDs: idList(ids)}
+}
+
+// points pairs the batch's chunks with their vectors, in the order they were
+// embedded
+func (b *embedBatch) points(ids map[string]int64, vectors [][]float32) []vectorstore.Point {
+	points := make([]vectorstore.Point, 0, b.chunks)
+	for _, f := range b.files {
+		for i, c := range f.chunks {
+			payload := b.payload(c, i)
+			payload.FileID = ids[f.entry.Path]
+			points = append(points, vectorstore.Point{
+				ID:      vectorstore.PointID(b.repo.ID, c.FilePath, i),
+				Vector:  vectors[len(points)],
+				Payload: payload,
+			})
+		}
+	}
+	return points
+}
+
+func (b *embedBatch) payload(c chunking.Chunk, index int) vectorstore.Payload {
+	sum := sha256.Sum256([]byte(c.Content))
+	return vectorstore.Payload{
+		WorkspaceID: b.repo.WorkspaceID,
+		RepoID:      b.repo.ID,
+		FilePath:    c.FilePath,
+		Language:    c.Language,
+		Content:     c.Content,
+		ContentHash: hex.EncodeToString(sum[:]),
+		ChunkIndex:  index,
+		StartLine:   c.StartLine,
+		EndLine:     c.EndLine,
+		ChunkType:   c.ChunkType,
+		SymbolName:  c.SymbolName,
+	}
+}
+
+// upsertChunkRows writes the chunks table rows that back keyword search
+func upsertChunkRows(ctx context.Context, q *db.Queries, points []vectorstore.Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	arg := db.UpsertChunksParams{Created: time.Now().UnixNano()}
+	for _, p := range points {
+		arg.Ids = append(arg.Ids, p.ID)
+		arg.WorkspaceIds = append(arg.WorkspaceIds, p.Payload.WorkspaceID)
+		arg.RepoIds = append(arg.RepoIds, p.Payload.RepoID)
+		arg.FileIds = append(arg.FileIds, p.Payload.FileID)
+		arg.FilePaths = append(arg.FilePaths, p.Payload.FilePath)
+		arg.Languages = append(arg.Languages, p.Payload.Language)
+		arg.ChunkTypes = append(arg.ChunkTypes, p.Payload.ChunkType)
+		arg.SymbolNames = append(arg.SymbolNames, p.Payload.SymbolName)
+		arg.Contents = append(arg.Contents, p.Payload.Content)
+		arg.ContentHashes = append(arg.ContentHashes, p.Payload.ContentHash)
+		arg.ChunkIndexes = append(arg.ChunkIndexes, int32(p.Payload.ChunkIndex))
+		arg.StartLines = append(arg.StartLines, int32(p.Payload.StartLine))
+		arg.EndLines = append(arg.EndLines, int32(p.Payload.EndLine))
+	}
+	if err := q.UpsertChunks(ctx, arg); err != nil {
+		return fmt.Errorf("failed to store chunks: %w", err)
+	}
+	return nil
+}
+
+func idList(ids map[string]int64) []int64 {
+	list := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, id)
+	}
+	return list
+}
+
+// embedInput prefixes the chunk with its path, which carries much of the
+// meaning of short chunks
+func embedInput(c chunking.Chunk) string {
+	return "File: " + c.FilePath + "\n\n" + c.Content
+}
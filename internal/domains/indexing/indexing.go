@@ -0,0 +1,395 @@
+// Package indexing processes repository index jobs
+package indexing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/domains/files"
+	"github.com/gomantics/semantix/internal/domains/indexruns"
+	"github.com/gomantics/semantix/internal/domains/jobs"
+	"github.com/gomantics/semantix/internal/domains/repos"
+	"github.com/gomantics/semantix/internal/embedder"
+	"github.com/gomantics/semantix/internal/vectordb"
+	"github.com/gomantics/semantix/config"
+	"github.com/gomantics/semantix/pkg/chunking"
+	"github.com/gomantics/semantix/pkg/embeddings"
+	"github.com/gomantics/semantix/pkg/gitrepo"
+	"github.com/gomantics/semantix/pkg/vectorstore"
+	"go.uber.org/zap"
+)
+
+// statusTimeout bounds status updates made after the job context is cancelled
+const statusTimeout = 10 * time.Second
+
+// HandleIndexRepo clones or updates a repository and indexes it, moving the
+// repository through cloning and indexing to completed.
+// Retryable failures return the repository to pending; the final attempt
+// marks it failed.
+func HandleIndexRepo(ctx context.Context, l *zap.Logger, job *jobs.Job) error {
+	var payload jobs.IndexRepoPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+	l = l.With(zap.Int64("repo_id", payload.RepoID))
+
+	repo, err := repos.GetByID(ctx, payload.RepoID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNotFound) {
+			l.Info("repository deleted before indexing; skipping")
+			return nil
+		}
+		return err
+	}
+
+	// A reclaimed job finds the repository where the crashed worker left it
+	if repo.Status == repos.StatusCloning || repo.Status == repos.StatusIndexing {
+		if _, err := repos.Release(ctx, repo.ID, nil); err != nil {
+			return err
+		}
+	} else if repo.Status != repos.StatusPending {
+		l.Info("repository is not pending; skipping", zap.String("status", string(repo.Status)))
+		return nil
+	}
+
+	// Only one index job runs per repository, so any run still marked running
+	// was abandoned by a crashed worker
+	if n, err := indexruns.FailRunning(ctx, repo.ID, "run abandoned by worker"); err != nil {
+		return err
+	} else if n > 0 {
+		l.Warn("failed abandoned index runs", zap.Int64("count", n))
+	}
+
+	if err := index(ctx, l, repo); err != nil {
+		if errors.Is(err, repos.ErrNotFound) {
+			// Deletion may have run before this job stored its last vectors
+			l.Info("repository deleted during indexing")
+			return repos.DeleteIndex(context.WithoutCancel(ctx), repo.ID)
+		}
+		return settle(ctx, l, job, repo, err)
+	}
+
+	return nil
+}
+
+func index(ctx context.Context, l *zap.Logger, repo *repos.Repo) (err error) {
+	run, err := indexruns.Start(ctx, repo.ID, repo.IndexedCommit)
+	if err != nil {
+		return err
+	}
+	l = l.With(zap.Int64("run_id", run.ID))
+	defer func() {
+		finishRun(ctx, l, run, err)
+	}()
+
+	if _, err := repos.Transition(ctx, repo.ID, repos.StatusCloning, nil); err != nil {
+		return err
+	}
+
+	head, err := repos.Checkout(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("checkout failed: %w", err)
+	}
+	l.Info("repository checked out", zap.String("commit", head.Commit), zap.String("branch", head.Branch))
+
+	snapshot := indexruns.Snapshot{
+		ToCommit:      head.Commit,
+		CommitMessage: head.Message,
+		Branch:        head.Branch,
+	}
+	if repo.IndexedCommit != nil {
+		snapshot.CommitsBetween = commitsBetween(ctx, l, repo, *repo.IndexedCommit, head.Commit)
+	}
+	if err := indexruns.SetSnapshot(ctx, run.ID, snapshot); err != nil {
+		return err
+	}
+
+	if _, err := repos.Transition(ctx, repo.ID, repos.StatusIndexing, nil); err != nil {
+		return err
+	}
+
+	if err := indexFiles(ctx, l, repo, run, head.Commit); err != nil {
+		return err
+	}
+
+	if _, err := repos.Transition(ctx, repo.ID, repos.StatusCompleted, nil); err != nil {
+		return err
+	}
+	// Listeners refresh what they show from the index; a missed event only
+	// leaves them stale
+	if err := repos.NotifyIndexed(ctx, repo); err != nil {
+		l.Warn("failed to announce finished indexing", zap.Error(err))
+	}
+	return nil
+}
+
+const (
+	// statsFlushFiles is how many files are processed between run stat updates
+	statsFlushFiles = 100
+	// deleteBatch bounds the removed files handled per statement
+	deleteBatch = 1000
+)
+
+// indexFiles brings the index up to date with the checkout, recording
+// progress on the run as it goes. Files are compared with the hashes stored
+// by the previous run: only added and changed files are chunked and
+// embedded, and only the vectors of changed and deleted files are removed.
+func indexFiles(ctx context.Context, l *zap.Logger, repo *repos.Repo, run *indexruns.Run, commit string) error {
+	e, err := embedder.Default()
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+
+	var (
+		mu      sync.Mutex
+		pending indexruns.Stats
+		total   int32
+		chunks  int32
+	)
+	flush := func() error {
+		mu.Lock()
+		stats := pending
+		pending = indexruns.Stats{}
+		mu.Unlock()
+
+		if stats == (indexruns.Stats{}) {
+			return nil
+		}
+		return indexruns.Add(ctx, run.ID, stats)
+	}
+
+	// Embedders report usage and cache hits through the context
+	ctx = embeddings.WithRecorder(ctx, func(s embeddings.Stats) {
+		mu.Lock()
+		defer mu.Unlock()
+		pending.TokensUsed += s.Tokens
+		pending.CacheHits += int32(s.CacheHits)
+		pending.CacheMisses += int32(s.CacheMisses)
+	})
+
+	indexed, err := files.ListByRepo(ctx, repo.ID)
+	if err != nil {
+		return err
+	}
+	// Without stored hashes every file is new; clear any vectors that
+	// predate them rather than leaving duplicates behind
+	if len(indexed) == 0 {
+		if err := repos.DeleteIndex(ctx, repo.ID); err != nil {
+			return err
+		}
+	}
+	// stale ends up holding the files no longer in the checkout
+	stale := make(map[string]files.File, len(indexed))
+	for _, f := range indexed {
+		stale[f.Path] = f
+	}
+
+	store := vectordb.Default()
+	batch := newEmbedBatch(e, store, repo, int(config.Embeddings.BatchSize()))
+	texts, err := newTextBatch(ctx, repo.ID)
+	if err != nil {
+		return err
+	}
+
+	dir := repos.CheckoutDir(repo.WorkspaceID, repo.ID)
+	err = walkFiles(ctx, l, dir, func(file sourceFile) error {
+		if err := texts.add(ctx, file); err != nil {
+			return err
+		}
+
+		prev, known := stale[file.Path]
+
+		var delta indexruns.Stats
+		if known && prev.ContentHash == file.Hash {
+			delete(stale, file.Path)
+			total++
+			chunks += prev.ChunkCount
+		} else {
+			fileChunks, ok, err := chunkFile(l, file)
+			if err != nil {
+				return err
+			}
+			// A file that's now skipped stays stale and is removed below
+			if !ok {
+				return nil
+			}
+			delete(stale, file.Path)
+			total++
+			chunks += int32(len(fileChunks))
+
+			if known {
+				delta.FilesChanged = 1
+			} else {
+				delta.FilesAdded = 1
+			}
+			delta.ChunksCreated = int32(len(fileChunks))
+
+			if err := batch.add(ctx, fileEntry(file, fileChunks), fileChunks); err != nil {
+				return err
+			}
+		}
+
+		mu.Lock()
+		pending.FilesTotal++
+		pending.FilesAdded += delta.FilesAdded
+		pending.FilesChanged += delta.FilesChanged
+		pending.ChunksCreated += delta.ChunksCreated
+		due := pending.FilesTotal >= statsFlushFiles
+		mu.Unlock()
+
+		if due {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := batch.flush(ctx); err != nil {
+		return err
+	}
+	if err := texts.finish(ctx); err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, len(stale))
+	for _, f := range stale {
+		ids = append(ids, f.ID)
+	}
+	if err := deleteFiles(ctx, store, repo.ID, ids); err != nil {
+		return err
+	}
+	mu.Lock()
+	pending.FilesDeleted += int32(len(ids))
+	mu.Unlock()
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	return repos.RecordIndexed(ctx, repo.ID, commit, total, chunks)
+}
+
+// fileEntry describes a chunked file for the files table
+func fileEntry(file sourceFile, chunks []chunking.Chunk) files.Entry {
+	lines := bytes.Count(file.Src, []byte("\n"))
+	if len(file.Src) > 0 && file.Src[len(file.Src)-1] != '\n' {
+		lines++
+	}
+	return files.Entry{
+		Path:        file.Path,
+		ContentHash: file.Hash,
+		Language:    chunking.Detect(file.Path, file.Src),
+		SizeBytes:   int64(len(file.Src)),
+		LineCount:   int32(lines),
+		ChunkCount:  int32(len(chunks)),
+	}
+}
+
+// deleteFiles removes the vectors, chunk rows and file rows of files gone
+// from the checkout
+func deleteFiles(ctx context.Context, store vectorstore.Store, repoID int64, ids []int64) error {
+	tx, inDatabase := store.(vectordb.Transactional)
+
+	for start := 0; start < len(ids); start += deleteBatch {
+		batch := ids[start:min(start+deleteBatch, len(ids))]
+		filter := vectorstore.Filter{RepoIDs: []int64{repoID}, FileIDs: batch}
+
+		if inDatabase {
+			err := db.Tx(ctx, func(q *db.Queries) error {
+				if err := tx.ReplaceTx(ctx, q, filter, nil); err != nil {
+					return err
+				}
+				return files.DeleteTx(ctx, q, batch)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to delete removed files: %w", err)
+			}
+			continue
+		}
+
+		// Vectors go first: rows left behind by a failure are retried by
+		// the next run, whereas orphaned vectors would stay searchable
+		if err := store.DeleteByFilter(ctx, filter); err != nil {
+			return fmt.Errorf("failed to delete removed files: %w", err)
+		}
+		err := db.Tx(ctx, func(q *db.Queries) error {
+			if err := q.DeleteChunksByFileIDs(ctx, batch); err != nil {
+				return err
+			}
+			return files.DeleteTx(ctx, q, batch)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete removed files: %w", err)
+		}
+	}
+	return nil
+}
+
+// commitsBetween counts the commits since the previous index, or returns nil
+// when the shallow checkout doesn't reach back that far
+func commitsBetween(ctx context.Context, l *zap.Logger, repo *repos.Repo, from, to string) *int32 {
+	n, err := gitrepo.CommitsBetween(ctx, repos.CheckoutDir(repo.WorkspaceID, repo.ID), from, to)
+	if err != nil {
+		if !errors.Is(err, gitrepo.ErrHistoryUnavailable) {
+			l.Warn("failed to count commits since previous index", zap.Error(err))
+		}
+		return nil
+	}
+	count := int32(n)
+	return &count
+}
+
+// finishRun records the outcome of the run and prunes old runs
+func finishRun(ctx context.Context, l *zap.Logger, run *indexruns.Run, cause error) {
+	// The job context may already be cancelled by shutdown
+	fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusTimeout)
+	defer cancel()
+
+	var err error
+	if cause == nil {
+		run, err = indexruns.Complete(fctx, run.ID)
+	} else {
+		run, err = indexruns.Fail(fctx, run.ID, cause.Error())
+	}
+	if err != nil {
+		l.Error("failed to record index run", zap.Error(err))
+		return
+	}
+	l.Info("index run finished", zap.String("status", string(run.Status)), zap.Int64p("duration_ms", run.DurationMs))
+
+	keep := int(config.Indexing.RunsRetainedPerRepo())
+	if _, err := indexruns.Prune(fctx, run.RepoID, keep); err != nil {
+		l.Error("failed to prune index runs", zap.Error(err))
+	}
+}
+
+// settle records a failed attempt on the repository: back to pending when the
+// job will be retried or released, failed otherwise
+func settle(ctx context.Context, l *zap.Logger, job *jobs.Job, repo *repos.Repo, cause error) error {
+	// The job context may already be cancelled by shutdown
+	sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusTimeout)
+	defer cancel()
+
+	msg := cause.Error()
+	to := repos.StatusPending
+	var err error
+	if ctx.Err() == nil && (job.LastAttempt() || jobs.IsPermanent(cause)) {
+		to = repos.StatusFailed
+		_, err = repos.Transition(sctx, repo.ID, to, &msg)
+	} else {
+		_, err = repos.Release(sctx, repo.ID, &msg)
+	}
+	if err != nil {
+		l.Error("failed to record repository status", zap.String("status", string(to)), zap.Error(err))
+	}
+
+	return cause
+}
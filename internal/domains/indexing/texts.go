@@ -0,0 +1,82 @@
+package indexing
+
+import (
+	"bytes"
+	"context"
+	"unicode/utf8"
+
+	"github.com/gomantics/semantix/internal/domains/files"
+)
+
+// textBatchBytes bounds the content sent in one upsert
+const textBatchBytes = 8 << 20
+
+// textBatch keeps the stored text of a repository's files in step with its
+// checkout for grep. Unlike chunks, it covers every text file walked,
+// including generated files the chunker skips.
+type textBatch struct {
+	repoID int64
+	// stale holds the stored hash of each path not yet seen in the walk
+	stale map[string]string
+
+	texts []files.Text
+	bytes int
+}
+
+func newTextBatch(ctx context.Context, repoID int64) (*textBatch, error) {
+	hashes, err := files.ContentHashes(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	return &textBatch{repoID: repoID, stale: hashes}, nil
+}
+
+// add queues the file's text unless it's stored already. Files Postgres
+// can't hold as text, with NUL bytes or invalid UTF-8, are left out.
+func (b *textBatch) add(ctx context.Context, file sourceFile) error {
+	if bytes.IndexByte(file.Src, 0) >= 0 || !utf8.Valid(file.Src) {
+		return nil
+	}
+
+	hash, known := b.stale[file.Path]
+	delete(b.stale, file.Path)
+	if known && hash == file.Hash {
+		return nil
+	}
+
+	b.texts = append(b.texts, files.Text{Path: file.Path, ContentHash: file.Hash, Content: string(file.Src)})
+	b.bytes += len(file.Src)
+	if b.bytes < textBatchBytes {
+		return nil
+	}
+	return b.flush(ctx)
+}
+
+func (b *textBatch) flush(ctx context.Context) error {
+	if len(b.texts) == 0 {
+		return nil
+	}
+	if err := files.SaveContents(ctx, b.repoID, b.texts); err != nil {
+		return err
+	}
+	b.texts = b.texts[:0]
+	b.bytes = 0
+	return nil
+}
+
+// finish stores the queued text and removes the text of files no longer in
+// the checkout
+func (b *textBatch) finish(ctx context.Context) error {
+	if err := b.flush(ctx); err != nil {
+		return err
+	}
+	if len(b.stale) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(b.stale))
+	for p := range b.stale {
+		paths = append(paths, p)
+	}
+	return files.DeleteContents(ctx, b.repoID, paths)
+}
@@ -0,0 +1,204 @@
+package indexruns
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/pgconv"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNotFound = errors.New("index run not found")
+	// ErrFinished is returned when completing or failing a run that already ended
+	ErrFinished = errors.New("index run already finished")
+)
+
+// Start records a new running index run. fromCommit is the repository's
+// previous head, nil on first index.
+func Start(ctx context.Context, repoID int64, fromCommit *string) (*Run, error) {
+	now := time.Now().UnixNano()
+	dbRun, err := db.Query1(ctx, func(q *db.Queries) (db.IndexRun, error) {
+		return q.CreateIndexRun(ctx, db.CreateIndexRunParams{
+			RepoID:     repoID,
+			FromCommit: pgconv.ToText(fromCommit),
+			StartedAt:  now,
+			Created:    now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toRun(dbRun), nil
+}
+
+// SetSnapshot records the commit the run is indexing
+func SetSnapshot(ctx context.Context, id int64, snapshot Snapshot) error {
+	return db.Query(ctx, func(q *db.Queries) error {
+		return q.SetIndexRunSnapshot(ctx, db.SetIndexRunSnapshotParams{
+			ID:             id,
+			ToCommit:       pgconv.ToText(&snapshot.ToCommit),
+			CommitMessage:  pgconv.ToText(&snapshot.CommitMessage),
+			Branch:         pgconv.ToText(&snapshot.Branch),
+			CommitsBetween: pgconv.ToInt4(snapshot.CommitsBetween),
+		})
+	})
+}
+
+// Add increments the run's counters by delta, so progress is visible while
+// the run is still going
+func Add(ctx context.Context, id int64, delta Stats) error {
+	return db.Query(ctx, func(q *db.Queries) error {
+		return q.AddIndexRunStats(ctx, db.AddIndexRunStatsParams{
+			ID:            id,
+			FilesTotal:    delta.FilesTotal,
+			FilesAdded:    delta.FilesAdded,
+			FilesChanged:  delta.FilesChanged,
+			FilesDeleted:  delta.FilesDeleted,
+			ChunksCreated: delta.ChunksCreated,
+			CacheHits:     delta.CacheHits,
+			CacheMisses:   delta.CacheMisses,
+			TokensUsed:    delta.TokensUsed,
+		})
+	})
+}
+
+// Complete marks a running run as completed and records its duration
+func Complete(ctx context.Context, id int64) (*Run, error) {
+	return finish(ctx, id, StatusCompleted, nil)
+}
+
+// Fail marks a running run as failed with the given message
+func Fail(ctx context.Context, id int64, message string) (*Run, error) {
+	return finish(ctx, id, StatusFailed, &message)
+}
+
+// FailRunning fails any runs of the repository still marked running, such as
+// runs abandoned by a crashed worker
+func FailRunning(ctx context.Context, repoID int64, message string) (int64, error) {
+	now := time.Now().UnixNano()
+	return db.Query1(ctx, func(q *db.Queries) (int64, error) {
+		return q.FailRunningIndexRunsByRepo(ctx, db.FailRunningIndexRunsByRepoParams{
+			RepoID:       repoID,
+			ErrorMessage: pgconv.ToText(&message),
+			CompletedAt:  pgconv.ToInt8(&now),
+		})
+	})
+}
+
+// Get retrieves a run, treating runs of other repositories as not found
+func Get(ctx context.Context, repoID, id int64) (*Run, error) {
+	dbRun, err := db.Query1(ctx, func(q *db.Queries) (db.IndexRun, error) {
+		return q.GetIndexRunByID(ctx, id)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if dbRun.RepoID != repoID {
+		return nil, ErrNotFound
+	}
+	return toRun(dbRun), nil
+}
+
+// List retrieves a repository's runs, newest first, with pagination
+func List(ctx context.Context, params ListParams) (*ListResult, error) {
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	type listData struct {
+		runs  []db.IndexRun
+		total int64
+	}
+
+	data, err := db.Tx1(ctx, func(q *db.Queries) (listData, error) {
+		dbRuns, err := q.ListIndexRunsByRepo(ctx, db.ListIndexRunsByRepoParams{
+			RepoID: params.RepoID,
+			Limit:  int32(params.Limit),
+			Offset: int32(params.Offset),
+		})
+		if err != nil {
+			return listData{}, err
+		}
+
+		total, err := q.CountIndexRunsByRepo(ctx, params.RepoID)
+		if err != nil {
+			return listData{}, err
+		}
+
+		return listData{runs: dbRuns, total: total}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]Run, len(data.runs))
+	for i, dbRun := range data.runs {
+		runs[i] = *toRun(dbRun)
+	}
+
+	return &ListResult{Runs: runs, Total: data.total}, nil
+}
+
+// Prune deletes all but the newest keep finished runs of the repository
+func Prune(ctx context.Context, repoID int64, keep int) (int64, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+	return db.Query1(ctx, func(q *db.Queries) (int64, error) {
+		return q.PruneIndexRuns(ctx, db.PruneIndexRunsParams{
+			RepoID: repoID,
+			Keep:   int32(keep),
+		})
+	})
+}
+
+func finish(ctx context.Context, id int64, status Status, message *string) (*Run, error) {
+	now := time.Now().UnixNano()
+	dbRun, err := db.Query1(ctx, func(q *db.Queries) (db.IndexRun, error) {
+		return q.FinishIndexRun(ctx, db.FinishIndexRunParams{
+			ID:           id,
+			Status:       string(status),
+			ErrorMessage: pgconv.ToText(message),
+			CompletedAt:  pgconv.ToInt8(&now),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFinished
+		}
+		return nil, err
+	}
+	return toRun(dbRun), nil
+}
+
+func toRun(dbRun db.IndexRun) *Run {
+	return &Run{
+		ID:             dbRun.ID,
+		RepoID:         dbRun.RepoID,
+		Status:         Status(dbRun.Status),
+		ErrorMessage:   pgconv.FromText(dbRun.ErrorMessage),
+		FromCommit:     pgconv.FromText(dbRun.FromCommit),
+		ToCommit:       pgconv.FromText(dbRun.ToCommit),
+		CommitMessage:  pgconv.FromText(dbRun.CommitMessage),
+		Branch:         pgconv.FromText(dbRun.Branch),
+		CommitsBetween: pgconv.FromInt4(dbRun.CommitsBetween),
+		FilesTotal:     dbRun.FilesTotal,
+		FilesAdded:     dbRun.FilesAdded,
+		FilesChanged:   dbRun.FilesChanged,
+		FilesDeleted:   dbRun.FilesDeleted,
+		ChunksCreated:  dbRun.ChunksCreated,
+		CacheHits:      dbRun.CacheHits,
+		CacheMisses:    dbRun.CacheMisses,
+		TokensUsed:     dbRun.TokensUsed,
+		StartedAt:      dbRun.StartedAt,
+		CompletedAt:    pgconv.FromInt8(dbRun.CompletedAt),
+		DurationMs:     pgconv.FromInt8(dbRun.DurationMs),
+		Created:        dbRun.Created,
+	}
+}
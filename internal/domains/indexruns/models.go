@@ -0,0 +1,69 @@
+package indexruns
+
+// Status is the outcome of an index run
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// Run records one indexing attempt of a repository and what it cost
+type Run struct {
+	ID             int64   `json:"id"`
+	RepoID         int64   `json:"repo_id"`
+	Status         Status  `json:"status"`
+	ErrorMessage   *string `json:"error_message"`
+	FromCommit     *string `json:"from_commit"`
+	ToCommit       *string `json:"to_commit"`
+	CommitMessage  *string `json:"commit_message"`
+	Branch         *string `json:"branch"`
+	CommitsBetween *int32  `json:"commits_between"`
+	FilesTotal     int32   `json:"files_total"`
+	FilesAdded     int32   `json:"files_added"`
+	FilesChanged   int32   `json:"files_changed"`
+	FilesDeleted   int32   `json:"files_deleted"`
+	ChunksCreated  int32   `json:"chunks_created"`
+	CacheHits      int32   `json:"cache_hits"`
+	CacheMisses    int32   `json:"cache_misses"`
+	TokensUsed     int64   `json:"tokens_used"`
+	StartedAt      int64   `json:"started_at"`
+	CompletedAt    *int64  `json:"completed_at"`
+	DurationMs     *int64  `json:"duration_ms"`
+	Created        int64   `json:"created"`
+}
+
+// Snapshot is the git state an index run covers
+type Snapshot struct {
+	ToCommit      string
+	CommitMessage string
+	Branch        string
+	// CommitsBetween is nil when the count isn't known
+	CommitsBetween *int32
+}
+
+// Stats are counters added to a run as the indexer makes progress
+type Stats struct {
+	FilesTotal    int32
+	FilesAdded    int32
+	FilesChanged  int32
+	FilesDeleted  int32
+	ChunksCreated int32
+	CacheHits     int32
+	CacheMisses   int32
+	TokensUsed    int64
+}
+
+// ListParams are the parameters for listing a repository's runs
+type ListParams struct {
+	RepoID int64
+	Limit  int
+	Offset int
+}
+
+// ListResult contains the result of listing runs
+type ListResult struct {
+	Runs  []Run
+	Total int64
+}
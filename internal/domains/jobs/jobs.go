@@ -0,0 +1,191 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gomantics/semantix/config"
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/pgconv"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrDuplicate is returned by Enqueue when a queued job with the same dedupe key exists
+	ErrDuplicate = errors.New("job with this dedupe key is already queued")
+	// ErrLost is returned when the worker no longer holds the job, because its
+	// visibility timeout expired and another worker reclaimed it
+	ErrLost = errors.New("job lock lost")
+)
+
+// Enqueue adds a job to the queue, runnable immediately
+func Enqueue(ctx context.Context, params EnqueueParams) (*Job, error) {
+	var job *Job
+	err := db.Query(ctx, func(q *db.Queries) error {
+		var err error
+		job, err = EnqueueTx(ctx, q, params)
+		return err
+	})
+	return job, err
+}
+
+// EnqueueTx adds a job using q, so callers can enqueue in the same transaction
+// as the change that makes the job necessary
+func EnqueueTx(ctx context.Context, q *db.Queries, params EnqueueParams) (*Job, error) {
+	payload, err := json.Marshal(params.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	var dedupeKey *string
+	if params.DedupeKey != "" {
+		dedupeKey = &params.DedupeKey
+	}
+
+	now := time.Now().UnixNano()
+	dbJob, err := q.EnqueueJob(ctx, db.EnqueueJobParams{
+		Kind:        string(params.Kind),
+		Payload:     payload,
+		DedupeKey:   pgconv.ToText(dedupeKey),
+		MaxAttempts: int32(max(config.Jobs.MaxAttempts(), 1)),
+		RunAfter:    now,
+		Created:     now,
+		Updated:     now,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDuplicate
+		}
+		return nil, err
+	}
+
+	return toJob(dbJob), nil
+}
+
+// claim locks the next runnable job for worker, skipping rows other workers
+// have locked. Running jobs whose visibility timeout has passed are reclaimed.
+// Returns nil when the queue is empty.
+func claim(ctx context.Context, worker string, visibility time.Duration) (*Job, error) {
+	now := time.Now()
+	dbJob, err := db.Query1(ctx, func(q *db.Queries) (db.Job, error) {
+		return q.ClaimJob(ctx, db.ClaimJobParams{
+			Worker:      pgconv.ToText(&worker),
+			LockedUntil: pgconv.ToInt8(pgconv.Ptr(now.Add(visibility).UnixNano())),
+			Now:         now.UnixNano(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toJob(dbJob), nil
+}
+
+// heartbeat extends the visibility timeout of a job held by worker
+func heartbeat(ctx context.Context, id int64, worker string, visibility time.Duration) error {
+	now := time.Now()
+	return affectOne(ctx, func(q *db.Queries) (int64, error) {
+		return q.HeartbeatJob(ctx, db.HeartbeatJobParams{
+			ID:          id,
+			LockedBy:    pgconv.ToText(&worker),
+			LockedUntil: pgconv.ToInt8(pgconv.Ptr(now.Add(visibility).UnixNano())),
+			Updated:     now.UnixNano(),
+		})
+	})
+}
+
+// complete marks a job held by worker as done
+func complete(ctx context.Context, id int64, worker string) error {
+	return affectOne(ctx, func(q *db.Queries) (int64, error) {
+		return q.CompleteJob(ctx, db.CompleteJobParams{
+			ID:       id,
+			LockedBy: pgconv.ToText(&worker),
+			Updated:  time.Now().UnixNano(),
+		})
+	})
+}
+
+// retry puts a job held by worker back in the queue to run after delay. A
+// queued job with the same dedupe key already covers the work, so the job is
+// completed instead of queued twice.
+func retry(ctx context.Context, id int64, worker string, delay time.Duration, cause error) error {
+	now := time.Now()
+	msg := cause.Error()
+	return affectOne(ctx, func(q *db.Queries) (int64, error) {
+		return q.RetryJob(ctx, db.RetryJobParams{
+			ID:        id,
+			LockedBy:  pgconv.ToText(&worker),
+			RunAfter:  now.Add(delay).UnixNano(),
+			LastError: pgconv.ToText(&msg),
+			Updated:   now.UnixNano(),
+		})
+	})
+}
+
+// fail marks a job held by worker as permanently failed
+func fail(ctx context.Context, id int64, worker string, cause error) error {
+	msg := cause.Error()
+	return affectOne(ctx, func(q *db.Queries) (int64, error) {
+		return q.FailJob(ctx, db.FailJobParams{
+			ID:        id,
+			LockedBy:  pgconv.ToText(&worker),
+			LastError: pgconv.ToText(&msg),
+			Updated:   time.Now().UnixNano(),
+		})
+	})
+}
+
+// release returns an interrupted job to the queue without counting the
+// attempt, or completes it when a queued job with the same dedupe key exists
+func release(ctx context.Context, id int64, worker string) error {
+	return affectOne(ctx, func(q *db.Queries) (int64, error) {
+		return q.ReleaseJob(ctx, db.ReleaseJobParams{
+			ID:       id,
+			LockedBy: pgconv.ToText(&worker),
+			Updated:  time.Now().UnixNano(),
+		})
+	})
+}
+
+// Prune deletes completed and failed jobs last updated before the cutoff
+func Prune(ctx context.Context, before time.Time) (int64, error) {
+	return db.Query1(ctx, func(q *db.Queries) (int64, error) {
+		return q.DeleteFinishedJobs(ctx, before.UnixNano())
+	})
+}
+
+// affectOne runs an update guarded by the worker's lock, mapping zero
+// affected rows to ErrLost
+func affectOne(ctx context.Context, fn func(q *db.Queries) (int64, error)) error {
+	n, err := db.Query1(ctx, fn)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLost
+	}
+	return nil
+}
+
+func toJob(dbJob db.Job) *Job {
+	return &Job{
+		ID:          dbJob.ID,
+		Kind:        Kind(dbJob.Kind),
+		Payload:     dbJob.Payload,
+		DedupeKey:   pgconv.FromText(dbJob.DedupeKey),
+		Status:      Status(dbJob.Status),
+		Attempts:    dbJob.Attempts,
+		MaxAttempts: dbJob.MaxAttempts,
+		RunAfter:    dbJob.RunAfter,
+		LockedBy:    pgconv.FromText(dbJob.LockedBy),
+		LockedUntil: pgconv.FromInt8(dbJob.LockedUntil),
+		LastError:   pgconv.FromText(dbJob.LastError),
+		Created:     dbJob.Created,
+		Updated:     dbJob.Updated,
+	}
+}
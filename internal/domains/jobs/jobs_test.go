@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/testdb"
+)
+
+func TestRequeueWithQueuedTwin(t *testing.T) {
+	testdb.Connect(t)
+	ctx := context.Background()
+	const kind, worker = Kind("test_requeue"), "test-worker"
+
+	tests := []struct {
+		name    string
+		requeue func(id int64) error
+	}{
+		{name: "retry", requeue: func(id int64) error {
+			return retry(ctx, id, worker, time.Minute, errors.New("boom"))
+		}},
+		{name: "release", requeue: func(id int64) error {
+			return release(ctx, id, worker)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := fmt.Sprintf("%s:%d", t.Name(), time.Now().UnixNano())
+			t.Cleanup(func() {
+				_ = db.Query(ctx, func(q *db.Queries) error {
+					_, err := q.Conn().Exec(ctx, "DELETE FROM jobs WHERE kind = $1 AND dedupe_key = $2", kind, key)
+					return err
+				})
+			})
+
+			running := enqueueRunning(t, kind, key, worker)
+			if err := tt.requeue(running.ID); err != nil {
+				t.Fatalf("requeue without a twin: %v", err)
+			}
+			if got := jobStatus(t, running.ID); got != StatusQueued {
+				t.Fatalf("status without a twin = %s, want queued", got)
+			}
+
+			// A running job doesn't hold its dedupe key, so a twin can be
+			// queued while it runs
+			setRunning(t, running.ID, worker)
+			twin, err := Enqueue(ctx, EnqueueParams{Kind: kind, DedupeKey: key})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.requeue(running.ID); err != nil {
+				t.Fatalf("requeue with a queued twin: %v", err)
+			}
+			if got := jobStatus(t, running.ID); got != StatusCompleted {
+				t.Errorf("status with a queued twin = %s, want completed", got)
+			}
+			if got := jobStatus(t, twin.ID); got != StatusQueued {
+				t.Errorf("twin status = %s, want queued", got)
+			}
+		})
+	}
+}
+
+// enqueueRunning adds a job held by worker
+func enqueueRunning(t *testing.T, kind Kind, key, worker string) *Job {
+	t.Helper()
+	job, err := Enqueue(context.Background(), EnqueueParams{Kind: kind, DedupeKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRunning(t, job.ID, worker)
+	return job
+}
+
+func setRunning(t *testing.T, id int64, worker string) {
+	t.Helper()
+	ctx := context.Background()
+	err := db.Query(ctx, func(q *db.Queries) error {
+		_, err := q.Conn().Exec(ctx,
+			"UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_by = $2, locked_until = $3 WHERE id = $1",
+			id, worker, time.Now().Add(time.Minute).UnixNano())
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func jobStatus(t *testing.T, id int64) Status {
+	t.Helper()
+	ctx := context.Background()
+	var status string
+	err := db.Query(ctx, func(q *db.Queries) error {
+		return q.Conn().QueryRow(ctx, "SELECT status FROM jobs WHERE id = $1", id).Scan(&status)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Status(status)
+}
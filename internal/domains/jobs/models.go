@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+)
+
+// Kind identifies the handler that processes a job
+type Kind string
+
+const (
+	KindIndexRepo Kind = "index_repo"
+)
+
+// Status is the position of a job in the queue
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// Job is a unit of background work
+type Job struct {
+	ID          int64           `json:"id"`
+	Kind        Kind            `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	DedupeKey   *string         `json:"dedupe_key,omitempty"`
+	Status      Status          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"max_attempts"`
+	RunAfter    int64           `json:"run_after"`
+	LockedBy    *string         `json:"locked_by,omitempty"`
+	LockedUntil *int64          `json:"locked_until,omitempty"`
+	LastError   *string         `json:"last_error,omitempty"`
+	Created     int64           `json:"created"`
+	Updated     int64           `json:"updated"`
+}
+
+// LastAttempt reports whether a failure of the current attempt is final
+func (j *Job) LastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// EnqueueParams are the parameters for adding a job to the queue
+type EnqueueParams struct {
+	Kind    Kind
+	Payload any
+	// DedupeKey, when set, makes the enqueue a no-op while another queued job
+	// of the same kind has the same key. A running job doesn't block a new one,
+	// so changes made while it runs still get picked up.
+	DedupeKey string
+}
+
+// Handler processes a claimed job. Returning an error retries the job with
+// backoff until its attempts are exhausted; wrap the error with Permanent to
+// fail it immediately. ctx is cancelled when the pool stops before the
+// handler finishes, in which case the job is released back to the queue.
+type Handler func(ctx context.Context, l *zap.Logger, job *Job) error
+
+// IndexRepoPayload is the payload of KindIndexRepo jobs
+type IndexRepoPayload struct {
+	RepoID int64 `json:"repo_id"`
+}
@@ -0,0 +1,352 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// finalizeTimeout bounds the status update after a handler returns; it
+	// runs on a fresh context so shutdown can't leave the job locked
+	finalizeTimeout = 10 * time.Second
+	// releaseGrace is how long Stop waits for interrupted handlers to return
+	// and release their jobs once its context has expired
+	releaseGrace = 5 * time.Second
+	// pruneInterval is how often finished jobs older than the retention are deleted
+	pruneInterval = time.Hour
+
+	minBackoff = 30 * time.Second
+	maxBackoff = 30 * time.Minute
+)
+
+// PoolOptions configures a worker pool
+type PoolOptions struct {
+	// Concurrency is the number of jobs processed at once
+	Concurrency int
+	// VisibilityTimeout is how long a claimed job stays locked without a
+	// heartbeat before other workers may reclaim it
+	VisibilityTimeout time.Duration
+	// PollInterval is how long idle workers wait before checking the queue again
+	PollInterval time.Duration
+	// Retention is how long completed and failed jobs are kept; 0 keeps them forever
+	Retention time.Duration
+}
+
+// Pool runs queued jobs on a fixed number of workers
+type Pool struct {
+	l        *zap.Logger
+	handlers map[Kind]Handler
+	opts     PoolOptions
+	// id identifies this process in locked_by
+	id string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	inflight map[int64]context.CancelFunc
+}
+
+// NewPool creates a pool dispatching jobs to handlers by kind
+func NewPool(l *zap.Logger, handlers map[Kind]Handler, opts PoolOptions) *Pool {
+	opts.Concurrency = max(opts.Concurrency, 1)
+	if opts.VisibilityTimeout <= 0 {
+		opts.VisibilityTimeout = 5 * time.Minute
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 2 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Pool{
+		l:        l,
+		handlers: handlers,
+		opts:     opts,
+		id:       workerID(),
+		ctx:      ctx,
+		cancel:   cancel,
+		inflight: make(map[int64]context.CancelFunc),
+	}
+}
+
+// Start launches the workers
+func (p *Pool) Start() {
+	p.l.Info("starting job workers",
+		zap.String("worker", p.id),
+		zap.Int("concurrency", p.opts.Concurrency),
+	)
+
+	for range p.opts.Concurrency {
+		p.wg.Add(1)
+		go p.work()
+	}
+
+	if p.opts.Retention > 0 {
+		p.wg.Add(1)
+		go p.prune()
+	}
+}
+
+// Stop stops claiming new jobs and waits for in-flight jobs to finish.
+// If ctx expires first, in-flight handlers are cancelled and their jobs
+// released back to the queue for another worker.
+func (p *Pool) Stop(ctx context.Context) error {
+	p.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	p.l.Warn("interrupting in-flight jobs", zap.Int("count", len(p.inflight)))
+	for _, cancel := range p.inflight {
+		cancel()
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(releaseGrace):
+		return errors.New("job workers did not stop in time")
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		if p.ctx.Err() != nil {
+			return
+		}
+
+		job, err := claim(p.ctx, p.id, p.opts.VisibilityTimeout)
+		if err != nil && p.ctx.Err() == nil {
+			p.l.Error("failed to claim job", zap.Error(err))
+		}
+		if job == nil {
+			select {
+			case <-p.ctx.Done():
+				return
+			case <-time.After(p.opts.PollInterval):
+			}
+			continue
+		}
+
+		p.run(job)
+	}
+}
+
+func (p *Pool) run(job *Job) {
+	l := p.l.With(
+		zap.Int64("job_id", job.ID),
+		zap.String("kind", string(job.Kind)),
+		zap.Int32("attempt", job.Attempts),
+	)
+
+	handler, ok := p.handlers[job.Kind]
+	if !ok {
+		p.finalize(l, job, func(ctx context.Context) error {
+			return fail(ctx, job.ID, p.id, fmt.Errorf("no handler for job kind %q", job.Kind))
+		})
+		return
+	}
+
+	// A job reclaimed after its worker died during the final attempt
+	if job.Attempts > job.MaxAttempts {
+		p.finalize(l, job, func(ctx context.Context) error {
+			return fail(ctx, job.ID, p.id, errors.New("exceeded max attempts"))
+		})
+		return
+	}
+
+	// Handlers run detached from the pool context so a graceful stop lets them finish
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.track(job.ID, cancel)
+	defer p.untrack(job.ID)
+
+	var lost atomic.Bool
+	hbDone := make(chan struct{})
+	hbCtx, stopHeartbeat := context.WithCancel(ctx)
+	go func() {
+		defer close(hbDone)
+		if err := p.heartbeat(hbCtx, job); errors.Is(err, ErrLost) {
+			lost.Store(true)
+			cancel()
+		}
+	}()
+
+	l.Info("running job")
+	start := time.Now()
+	err := call(ctx, l, handler, job)
+	stopHeartbeat()
+	<-hbDone
+
+	l = l.With(zap.Duration("duration", time.Since(start)))
+	switch {
+	case lost.Load():
+		l.Warn("job lock lost; another worker may have reclaimed it", zap.Error(err))
+	case err == nil:
+		l.Info("job completed")
+		p.finalize(l, job, func(ctx context.Context) error {
+			return complete(ctx, job.ID, p.id)
+		})
+	case ctx.Err() != nil:
+		l.Info("job interrupted by shutdown; releasing", zap.Error(err))
+		p.finalize(l, job, func(ctx context.Context) error {
+			return release(ctx, job.ID, p.id)
+		})
+	case IsPermanent(err) || job.LastAttempt():
+		l.Error("job failed", zap.Error(err))
+		p.finalize(l, job, func(ctx context.Context) error {
+			return fail(ctx, job.ID, p.id, err)
+		})
+	default:
+		delay := backoff(job.Attempts)
+		l.Warn("job failed; retrying", zap.Error(err), zap.Duration("delay", delay))
+		p.finalize(l, job, func(ctx context.Context) error {
+			return retry(ctx, job.ID, p.id, delay, err)
+		})
+	}
+}
+
+// heartbeat extends the job's lock until ctx is cancelled
+func (p *Pool) heartbeat(ctx context.Context, job *Job) error {
+	ticker := time.NewTicker(p.opts.VisibilityTimeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			err := heartbeat(ctx, job.ID, p.id, p.opts.VisibilityTimeout)
+			if errors.Is(err, ErrLost) {
+				return err
+			}
+			if err != nil && ctx.Err() == nil {
+				p.l.Warn("failed to extend job lock", zap.Int64("job_id", job.ID), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (p *Pool) finalize(l *zap.Logger, job *Job, fn func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), finalizeTimeout)
+	defer cancel()
+
+	if err := fn(ctx); err != nil {
+		if errors.Is(err, ErrLost) {
+			l.Warn("job lock lost before its result was recorded")
+			return
+		}
+		l.Error("failed to record job result", zap.Error(err))
+	}
+}
+
+func (p *Pool) prune() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := Prune(p.ctx, time.Now().Add(-p.opts.Retention))
+		if err != nil && p.ctx.Err() == nil {
+			p.l.Error("failed to prune finished jobs", zap.Error(err))
+		}
+		if n > 0 {
+			p.l.Info("pruned finished jobs", zap.Int64("count", n))
+		}
+
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Pool) track(id int64, cancel context.CancelFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inflight[id] = cancel
+}
+
+func (p *Pool) untrack(id int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.inflight, id)
+}
+
+// call runs the handler, turning panics into errors
+func call(ctx context.Context, l *zap.Logger, handler Handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.Error("job handler panicked", zap.Any("panic", r), zap.ByteString("stack", debug.Stack()))
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, l, job)
+}
+
+// backoff doubles the retry delay with each attempt
+func backoff(attempt int32) time.Duration {
+	delay := minBackoff
+	for i := int32(1); i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+// workerID identifies this process as hostname-pid-random
+func workerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a handler error as not worth retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
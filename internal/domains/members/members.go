@@ -0,0 +1,169 @@
+// Package members manages the roles API keys hold in workspaces
+package members
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/pgconv"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNotFound       = errors.New("member not found")
+	ErrInvalidRole    = errors.New("role must be viewer, editor or admin")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+// List retrieves a workspace's members with pagination
+func List(ctx context.Context, params ListParams) (*ListResult, error) {
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	type listData struct {
+		rows  []db.ListWorkspaceMembersRow
+		total int64
+	}
+
+	data, err := db.Tx1(ctx, func(q *db.Queries) (listData, error) {
+		rows, err := q.ListWorkspaceMembers(ctx, db.ListWorkspaceMembersParams{
+			WorkspaceID: params.WorkspaceID,
+			Limit:       int32(params.Limit),
+			Offset:      int32(params.Offset),
+		})
+		if err != nil {
+			return listData{}, err
+		}
+
+		total, err := q.CountWorkspaceMembers(ctx, params.WorkspaceID)
+		if err != nil {
+			return listData{}, err
+		}
+
+		return listData{rows: rows, total: total}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]Member, len(data.rows))
+	for i, row := range data.rows {
+		members[i] = Member{
+			APIKeyID:   row.ApiKeyID,
+			Name:       row.Name,
+			Prefix:     row.Prefix,
+			Role:       Role(row.Role),
+			ExpiresAt:  pgconv.FromInt8(row.ExpiresAt),
+			LastUsedAt: pgconv.FromInt8(row.LastUsedAt),
+			Created:    row.Created,
+			Updated:    row.Updated,
+		}
+	}
+
+	return &ListResult{Members: members, Total: data.total}, nil
+}
+
+// Set gives a key the role in the workspace. Keys that aren't members yet are
+// only added when add is set; otherwise they aren't found, the same as keys
+// that don't exist, so workspace admins can't probe for other keys.
+func Set(ctx context.Context, workspaceID, apiKeyID int64, role Role, add bool) (*Member, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+
+	return db.Tx1(ctx, func(q *db.Queries) (*Member, error) {
+		if !add {
+			_, err := q.GetWorkspaceMember(ctx, db.GetWorkspaceMemberParams{
+				WorkspaceID: workspaceID,
+				ApiKeyID:    apiKeyID,
+			})
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil, ErrAPIKeyNotFound
+				}
+				return nil, err
+			}
+		}
+
+		k, err := q.GetAPIKeyByID(ctx, apiKeyID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrAPIKeyNotFound
+			}
+			return nil, err
+		}
+
+		m, err := SetTx(ctx, q, workspaceID, apiKeyID, role)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Member{
+			APIKeyID:   k.ID,
+			Name:       k.Name,
+			Prefix:     k.Prefix,
+			Role:       Role(m.Role),
+			ExpiresAt:  pgconv.FromInt8(k.ExpiresAt),
+			LastUsedAt: pgconv.FromInt8(k.LastUsedAt),
+			Created:    m.Created,
+			Updated:    m.Updated,
+		}, nil
+	})
+}
+
+// SetTx gives a key the role in the workspace within the caller's
+// transaction. The caller checks that both exist.
+func SetTx(ctx context.Context, q *db.Queries, workspaceID, apiKeyID int64, role Role) (db.WorkspaceMember, error) {
+	if !role.Valid() {
+		return db.WorkspaceMember{}, ErrInvalidRole
+	}
+	return q.UpsertWorkspaceMember(ctx, db.UpsertWorkspaceMemberParams{
+		WorkspaceID: workspaceID,
+		ApiKeyID:    apiKeyID,
+		Role:        string(role),
+		Now:         time.Now().UnixNano(),
+	})
+}
+
+// Remove takes a key's access to the workspace away. The key itself stays
+// valid for its other workspaces.
+func Remove(ctx context.Context, workspaceID, apiKeyID int64) error {
+	return db.Tx(ctx, func(q *db.Queries) error {
+		_, err := q.GetWorkspaceMember(ctx, db.GetWorkspaceMemberParams{
+			WorkspaceID: workspaceID,
+			ApiKeyID:    apiKeyID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		return q.DeleteWorkspaceMember(ctx, db.DeleteWorkspaceMemberParams{
+			WorkspaceID: workspaceID,
+			ApiKeyID:    apiKeyID,
+		})
+	})
+}
+
+// ListByAPIKeys returns the memberships of each key, by workspace ID
+func ListByAPIKeys(ctx context.Context, apiKeyIDs []int64) (map[int64][]Membership, error) {
+	rows, err := db.Query1(ctx, func(q *db.Queries) ([]db.WorkspaceMember, error) {
+		return q.ListMembershipsByAPIKeys(ctx, apiKeyIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[int64][]Membership, len(apiKeyIDs))
+	for _, row := range rows {
+		out[row.ApiKeyID] = append(out[row.ApiKeyID], Membership{
+			WorkspaceID: row.WorkspaceID,
+			Role:        Role(row.Role),
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,39 @@
+package members
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gomantics/semantix/internal/testdb"
+)
+
+func TestSetOnlyAddsWhenAllowed(t *testing.T) {
+	testdb.Connect(t)
+	ctx := context.Background()
+	ws := testdb.Workspace(t)
+	other := testdb.Workspace(t)
+
+	outsider := testdb.APIKey(t, false)
+	if _, err := Set(ctx, other.ID, outsider.ID, RoleViewer, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// An outsider and a key that doesn't exist must look the same
+	for _, id := range []int64{outsider.ID, -1} {
+		if _, err := Set(ctx, ws.ID, id, RoleViewer, false); !errors.Is(err, ErrAPIKeyNotFound) {
+			t.Errorf("Set(%d) without add = %v, want ErrAPIKeyNotFound", id, err)
+		}
+	}
+
+	if _, err := Set(ctx, ws.ID, outsider.ID, RoleViewer, true); err != nil {
+		t.Fatalf("Set with add: %v", err)
+	}
+	m, err := Set(ctx, ws.ID, outsider.ID, RoleEditor, false)
+	if err != nil {
+		t.Fatalf("Set on a member: %v", err)
+	}
+	if m.Role != RoleEditor {
+		t.Errorf("role = %s, want editor", m.Role)
+	}
+}
@@ -0,0 +1,59 @@
+package members
+
+// Role is what a member may do in a workspace. Each role includes those
+// below it.
+type Role string
+
+const (
+	// RoleViewer searches and reads the workspace
+	RoleViewer Role = "viewer"
+	// RoleEditor also adds, removes and re-indexes repositories
+	RoleEditor Role = "editor"
+	// RoleAdmin also changes and deletes the workspace and manages its
+	// members and their keys
+	RoleAdmin Role = "admin"
+)
+
+var ranks = map[Role]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	_, ok := ranks[r]
+	return ok
+}
+
+// Includes reports whether r allows everything other does
+func (r Role) Includes(other Role) bool {
+	return r.Valid() && ranks[r] >= ranks[other]
+}
+
+// Membership is a key's role in one workspace
+type Membership struct {
+	WorkspaceID int64 `json:"workspace_id"`
+	Role        Role  `json:"role"`
+}
+
+// Member is an API key as a member of a workspace
+type Member struct {
+	APIKeyID   int64  `json:"api_key_id"`
+	Name       string `json:"name"`
+	Prefix     string `json:"prefix"`
+	Role       Role   `json:"role"`
+	ExpiresAt  *int64 `json:"expires_at,omitempty"`
+	LastUsedAt *int64 `json:"last_used_at,omitempty"`
+	Created    int64  `json:"created"`
+	Updated    int64  `json:"updated"`
+}
+
+// ListParams are the parameters for listing a workspace's members
+type ListParams struct {
+	WorkspaceID int64
+	Limit       int
+	Offset      int
+}
+
+// ListResult contains the result of listing members
+type ListResult struct {
+	Members []Member
+	Total   int64
+}
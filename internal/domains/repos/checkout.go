@@ -0,0 +1,95 @@
+package repos
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gomantics/semantix/config"
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/domains/gittokens"
+	"github.com/gomantics/semantix/pkg/gitrepo"
+	"github.com/gomantics/semantix/pkg/pgconv"
+)
+
+// CheckoutDir returns the working copy location: {clone_dir}/{workspace_id}/{repo_id}
+func CheckoutDir(workspaceID, repoID int64) string {
+	return filepath.Join(
+		config.Indexing.CloneDir(),
+		strconv.FormatInt(workspaceID, 10),
+		strconv.FormatInt(repoID, 10),
+	)
+}
+
+// Checkout clones or updates the repository's working copy and records the
+// checked-out HEAD on the repository. The index still describes
+// IndexedCommit until RecordIndexed.
+func Checkout(ctx context.Context, repo *Repo) (*gitrepo.Head, error) {
+	if repo.Provider == ProviderLocal {
+		// The path is checked again in case configuration or links changed
+		if _, err := ParseURL(repo.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := gitrepo.Options{
+		URL:          repo.URL,
+		Dir:          CheckoutDir(repo.WorkspaceID, repo.ID),
+		Provider:     repo.Provider,
+		Depth:        int(config.Indexing.CloneDepth()),
+		Exclude:      config.Indexing.ExcludePatterns(),
+		Timeout:      time.Duration(config.Indexing.CloneTimeoutSeconds()) * time.Second,
+		MaxSizeBytes: config.Indexing.MaxRepoSizeBytes(),
+	}
+
+	if repo.GitTokenID != nil {
+		cred, err := gittokens.Reveal(ctx, *repo.GitTokenID)
+		if err != nil {
+			return nil, err
+		}
+		opts.Token = cred.Token
+		opts.TokenHost = cred.Host
+	}
+
+	head, err := gitrepo.Sync(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := RecordHead(ctx, repo.ID, head); err != nil {
+		return nil, err
+	}
+
+	return head, nil
+}
+
+// RecordHead stores the checked-out branch, commit and message on the repository
+func RecordHead(ctx context.Context, id int64, head *gitrepo.Head) error {
+	return db.Query(ctx, func(q *db.Queries) error {
+		_, err := q.UpdateRepoHead(ctx, db.UpdateRepoHeadParams{
+			ID:                id,
+			DefaultBranch:     pgconv.ToText(&head.Branch),
+			HeadCommit:        pgconv.ToText(&head.Commit),
+			HeadCommitMessage: pgconv.ToText(&head.Message),
+			Updated:           time.Now().UnixNano(),
+		})
+		return err
+	})
+}
+
+// RecordIndexed stores the commit and size of the finished index on the
+// repository. Call it only once the index describes commit in full.
+func RecordIndexed(ctx context.Context, id int64, commit string, files, chunks int32) error {
+	now := time.Now().UnixNano()
+	return db.Query(ctx, func(q *db.Queries) error {
+		return q.UpdateRepoIndexStats(ctx, db.UpdateRepoIndexStatsParams{
+			ID:            id,
+			FileCount:     files,
+			ChunkCount:    chunks,
+			IndexedCommit: pgconv.ToText(&commit),
+			IndexedAt:     pgconv.ToInt8(&now),
+			Updated:       now,
+		})
+	})
+}
@@ -0,0 +1,52 @@
+package repos
+
+// Repo represents a git repository indexed within a workspace
+type Repo struct {
+	ID            int64   `json:"id"`
+	WorkspaceID   int64   `json:"workspace_id"`
+	GitTokenID    *int64  `json:"git_token_id,omitempty"`
+	URL           string  `json:"url"`
+	Provider      string  `json:"provider"`
+	Owner         string  `json:"owner"`
+	Name          string  `json:"name"`
+	Status        Status  `json:"status"`
+	ErrorMessage  *string `json:"error_message,omitempty"`
+	DefaultBranch *string `json:"default_branch,omitempty"`
+	HeadCommit    *string `json:"head_commit,omitempty"`
+	HeadMessage   *string `json:"head_commit_message,omitempty"`
+	// IndexedCommit is the commit the index describes; HeadCommit may be a
+	// newer one whose indexing hasn't finished
+	IndexedCommit *string `json:"indexed_commit,omitempty"`
+	FileCount     int32   `json:"file_count"`
+	ChunkCount    int32   `json:"chunk_count"`
+	IndexedAt     *int64  `json:"indexed_at,omitempty"`
+	Created       int64   `json:"created"`
+	Updated       int64   `json:"updated"`
+}
+
+// CreateParams are the parameters for adding a repository to a workspace
+type CreateParams struct {
+	WorkspaceID int64
+	URL         string
+	// GitTokenID names a token of the workspace, or a shared token when
+	// AllowSharedToken is set
+	GitTokenID *int64
+	// AllowSharedToken is set for superadmins, who manage the shared tokens
+	AllowSharedToken bool
+	// AllowLocal is set for superadmins, who may add repositories on the
+	// server's filesystem
+	AllowLocal bool
+}
+
+// ListParams are the parameters for listing repositories in a workspace
+type ListParams struct {
+	WorkspaceID int64
+	Limit       int
+	Offset      int
+}
+
+// ListResult contains the result of listing repositories
+type ListResult struct {
+	Repos []Repo
+	Total int64
+}
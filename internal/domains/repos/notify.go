@@ -0,0 +1,58 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gomantics/semantix/db"
+)
+
+// indexedChannel is the Postgres notification channel announcing finished
+// index runs
+const indexedChannel = "repo_indexed"
+
+// IndexedEvent announces that a repository's index matches its new head
+type IndexedEvent struct {
+	WorkspaceID int64 `json:"workspace_id"`
+	RepoID      int64 `json:"repo_id"`
+}
+
+// NotifyIndexed tells every listening process that the repository finished
+// indexing
+func NotifyIndexed(ctx context.Context, repo *Repo) error {
+	payload, err := json.Marshal(IndexedEvent{WorkspaceID: repo.WorkspaceID, RepoID: repo.ID})
+	if err != nil {
+		return err
+	}
+	return db.Query(ctx, func(q *db.Queries) error {
+		return q.NotifyRepoIndexed(ctx, string(payload))
+	})
+}
+
+// ListenIndexed calls fn with each IndexedEvent sent by any process until ctx
+// ends or the connection fails. Events sent while no listener is connected
+// are lost.
+func ListenIndexed(ctx context.Context, fn func(IndexedEvent)) error {
+	pooled, err := db.GetPool().Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// A listening connection mustn't go back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+indexedChannel); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var ev IndexedEvent
+		if json.Unmarshal([]byte(n.Payload), &ev) != nil {
+			continue
+		}
+		fn(ev)
+	}
+}
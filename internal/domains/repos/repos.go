@@ -0,0 +1,365 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/domains/gittokens"
+	"github.com/gomantics/semantix/internal/domains/jobs"
+	"github.com/gomantics/semantix/internal/vectordb"
+	"github.com/gomantics/semantix/pkg/pgconv"
+	"github.com/gomantics/semantix/pkg/vectorstore"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNotFound          = errors.New("repository not found")
+	ErrAlreadyExists     = errors.New("repository with this url already exists in the workspace")
+	ErrInvalidTransition = errors.New("invalid repository status transition")
+	ErrGitTokenNotFound  = errors.New("git token not found")
+	ErrGitTokenHost      = errors.New("git token is for a different host than the repository")
+	ErrLocalForbidden    = errors.New("only superadmins can add local repositories")
+)
+
+func Create(ctx context.Context, params CreateParams) (*Repo, error) {
+	info, err := ParseURL(params.URL)
+	if err != nil {
+		return nil, err
+	}
+	if info.Provider == ProviderLocal && !params.AllowLocal {
+		return nil, ErrLocalForbidden
+	}
+
+	now := time.Now().UnixNano()
+
+	dbRepo, err := db.Tx1(ctx, func(q *db.Queries) (db.Repo, error) {
+		_, err := q.GetRepoByWorkspaceAndURL(ctx, db.GetRepoByWorkspaceAndURLParams{
+			WorkspaceID: params.WorkspaceID,
+			Url:         info.URL,
+		})
+		if err == nil {
+			return db.Repo{}, ErrAlreadyExists
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return db.Repo{}, err
+		}
+
+		if params.GitTokenID != nil {
+			token, err := q.GetGitTokenByID(ctx, *params.GitTokenID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.Repo{}, ErrGitTokenNotFound
+			}
+			if err != nil {
+				return db.Repo{}, err
+			}
+			// Tokens the caller can't manage look the same as missing ones
+			usable := gittokens.BelongsTo(token, &params.WorkspaceID) ||
+				(params.AllowSharedToken && gittokens.BelongsTo(token, nil))
+			if !usable {
+				return db.Repo{}, ErrGitTokenNotFound
+			}
+			if !tokenFor(token, info.URL) {
+				return db.Repo{}, ErrGitTokenHost
+			}
+		}
+
+		dbRepo, err := q.CreateRepo(ctx, db.CreateRepoParams{
+			WorkspaceID: params.WorkspaceID,
+			GitTokenID:  pgconv.ToInt8(params.GitTokenID),
+			Url:         info.URL,
+			Provider:    info.Provider,
+			Owner:       info.Owner,
+			Name:        info.Name,
+			Status:      string(StatusPending),
+			Created:     now,
+			Updated:     now,
+		})
+		if err != nil {
+			return db.Repo{}, err
+		}
+
+		_, err = enqueueIndex(ctx, q, dbRepo.ID)
+		return dbRepo, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toRepo(dbRepo), nil
+}
+
+// tokenFor reports whether the token may be sent to the repository: tokens
+// are only sent over HTTPS, and only to their own host
+func tokenFor(token db.GitToken, repoURL string) bool {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	return token.Host.Valid && strings.EqualFold(u.Host, token.Host.String)
+}
+
+// GetByID retrieves a repository regardless of workspace.
+// Use Get when serving workspace-scoped requests.
+func GetByID(ctx context.Context, id int64) (*Repo, error) {
+	dbRepo, err := db.Query1(ctx, func(q *db.Queries) (db.Repo, error) {
+		return q.GetRepoByID(ctx, id)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return toRepo(dbRepo), nil
+}
+
+// Get retrieves a repository, treating repositories of other workspaces as not found
+func Get(ctx context.Context, workspaceID, id int64) (*Repo, error) {
+	repo, err := GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if repo.WorkspaceID != workspaceID {
+		return nil, ErrNotFound
+	}
+	return repo, nil
+}
+
+// List retrieves repositories in a workspace with pagination
+func List(ctx context.Context, params ListParams) (*ListResult, error) {
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	type listData struct {
+		repos []db.Repo
+		total int64
+	}
+
+	data, err := db.Tx1(ctx, func(q *db.Queries) (listData, error) {
+		dbRepos, err := q.ListReposByWorkspace(ctx, db.ListReposByWorkspaceParams{
+			WorkspaceID: params.WorkspaceID,
+			Limit:       int32(params.Limit),
+			Offset:      int32(params.Offset),
+		})
+		if err != nil {
+			return listData{}, err
+		}
+
+		total, err := q.CountReposByWorkspace(ctx, params.WorkspaceID)
+		if err != nil {
+			return listData{}, err
+		}
+
+		return listData{repos: dbRepos, total: total}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repos := make([]Repo, len(data.repos))
+	for i, dbRepo := range data.repos {
+		repos[i] = *toRepo(dbRepo)
+	}
+
+	return &ListResult{Repos: repos, Total: data.total}, nil
+}
+
+// Transition moves a repository to a new status.
+// The update only applies if the current status allows it, so concurrent
+// workers can't both claim the same repository.
+func Transition(ctx context.Context, id int64, to Status, errorMessage *string) (*Repo, error) {
+	return transitionFrom(ctx, id, sourcesOf(to), to, errorMessage)
+}
+
+// Release returns a repository being cloned or indexed to pending, for the
+// job working on it to be retried, or for a reclaimed job to start over
+func Release(ctx context.Context, id int64, errorMessage *string) (*Repo, error) {
+	return transitionFrom(ctx, id, releasable, StatusPending, errorMessage)
+}
+
+func transitionFrom(ctx context.Context, id int64, from []Status, to Status, errorMessage *string) (*Repo, error) {
+	dbRepo, err := db.Query1(ctx, func(q *db.Queries) (db.Repo, error) {
+		return transition(ctx, q, id, from, to, errorMessage)
+	})
+	if err == nil {
+		return toRepo(dbRepo), nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	// Distinguish a missing repository from a disallowed transition
+	current, err := GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, current.Status, to)
+}
+
+// Reindex queues a completed or failed repository for indexing again.
+// Repositories already queued or being indexed get ErrInvalidTransition.
+func Reindex(ctx context.Context, workspaceID, id int64) (*Repo, error) {
+	current, err := Get(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dbRepo, err := db.Tx1(ctx, func(q *db.Queries) (db.Repo, error) {
+		dbRepo, err := transition(ctx, q, id, reindexable, StatusPending, nil)
+		if err != nil {
+			return db.Repo{}, err
+		}
+		_, err = enqueueIndex(ctx, q, id)
+		return dbRepo, err
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, current.Status, StatusPending)
+		}
+		return nil, err
+	}
+
+	return toRepo(dbRepo), nil
+}
+
+// EnqueuePending queues an index job for every pending repository that
+// doesn't have one, such as repositories created before the queue existed.
+// Returns the number of jobs added.
+func EnqueuePending(ctx context.Context) (int, error) {
+	ids, err := db.Query1(ctx, func(q *db.Queries) ([]int64, error) {
+		return q.ListRepoIDsByStatus(ctx, string(StatusPending))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var added int
+	for _, id := range ids {
+		ok, err := db.Query1(ctx, func(q *db.Queries) (bool, error) {
+			return enqueueIndex(ctx, q, id)
+		})
+		if err != nil {
+			return added, err
+		}
+		if ok {
+			added++
+		}
+	}
+
+	return added, nil
+}
+
+// transition moves the repository to a status if it's in one of from
+func transition(ctx context.Context, q *db.Queries, id int64, from []Status, to Status, errorMessage *string) (db.Repo, error) {
+	fromStatuses := make([]string, len(from))
+	for i, s := range from {
+		fromStatuses[i] = string(s)
+	}
+	return q.TransitionRepoStatus(ctx, db.TransitionRepoStatusParams{
+		ID:           id,
+		Status:       string(to),
+		ErrorMessage: pgconv.ToText(errorMessage),
+		Updated:      time.Now().UnixNano(),
+		FromStatuses: fromStatuses,
+	})
+}
+
+// enqueueIndex queues an index job for the repository unless one is already
+// queued, and reports whether a job was added
+func enqueueIndex(ctx context.Context, q *db.Queries, id int64) (bool, error) {
+	_, err := jobs.EnqueueTx(ctx, q, jobs.EnqueueParams{
+		Kind:      jobs.KindIndexRepo,
+		Payload:   jobs.IndexRepoPayload{RepoID: id},
+		DedupeKey: fmt.Sprintf("repo:%d", id),
+	})
+	if errors.Is(err, jobs.ErrDuplicate) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete removes the repository, its vectors and its local checkout
+func Delete(ctx context.Context, workspaceID, id int64) error {
+	if _, err := Get(ctx, workspaceID, id); err != nil {
+		return err
+	}
+
+	// Vectors go first: if the rows can't be deleted afterwards, re-indexing
+	// restores them, whereas orphaned vectors would stay searchable
+	if err := DeleteIndex(ctx, id); err != nil {
+		return err
+	}
+
+	err := db.Tx(ctx, func(q *db.Queries) error {
+		dbRepo, err := q.GetRepoByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if dbRepo.WorkspaceID != workspaceID {
+			return ErrNotFound
+		}
+
+		if err := q.DeleteIndexRunsByRepo(ctx, id); err != nil {
+			return err
+		}
+
+		return q.DeleteRepo(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(CheckoutDir(workspaceID, id))
+}
+
+// DeleteIndex removes every indexed chunk of the repository from the vector
+// store and the keyword index, along with the text kept for grep, and
+// forgets its file hashes so the next run indexes everything
+func DeleteIndex(ctx context.Context, id int64) error {
+	err := vectordb.Default().DeleteByFilter(ctx, vectorstore.Filter{RepoIDs: []int64{id}})
+	if err != nil {
+		return fmt.Errorf("failed to delete repository vectors: %w", err)
+	}
+	return db.Tx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteChunksByRepo(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeleteFileContentsByRepo(ctx, id); err != nil {
+			return err
+		}
+		return q.DeleteFilesByRepo(ctx, id)
+	})
+}
+
+func toRepo(dbRepo db.Repo) *Repo {
+	return &Repo{
+		ID:            dbRepo.ID,
+		WorkspaceID:   dbRepo.WorkspaceID,
+		GitTokenID:    pgconv.FromInt8(dbRepo.GitTokenID),
+		URL:           dbRepo.Url,
+		Provider:      dbRepo.Provider,
+		Owner:         dbRepo.Owner,
+		Name:          dbRepo.Name,
+		Status:        Status(dbRepo.Status),
+		ErrorMessage:  pgconv.FromText(dbRepo.ErrorMessage),
+		DefaultBranch: pgconv.FromText(dbRepo.DefaultBranch),
+		HeadCommit:    pgconv.FromText(dbRepo.HeadCommit),
+		HeadMessage:   pgconv.FromText(dbRepo.HeadCommitMessage),
+		IndexedCommit: pgconv.FromText(dbRepo.IndexedCommit),
+		FileCount:     dbRepo.FileCount,
+		ChunkCount:    dbRepo.ChunkCount,
+		IndexedAt:     pgconv.FromInt8(dbRepo.IndexedAt),
+		Created:       dbRepo.Created,
+		Updated:       dbRepo.Updated,
+	}
+}
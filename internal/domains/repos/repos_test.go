@@ -0,0 +1,86 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/testdb"
+	"github.com/gomantics/semantix/pkg/pgconv"
+)
+
+func TestCreateGitTokenScope(t *testing.T) {
+	testdb.Connect(t)
+	ctx := context.Background()
+	ws := testdb.Workspace(t)
+	other := testdb.Workspace(t)
+
+	own := createToken(t, &ws.ID, "github.com")
+	foreign := createToken(t, &other.ID, "github.com")
+	shared := createToken(t, nil, "github.com")
+	elsewhere := createToken(t, &ws.ID, "git.example.com")
+
+	tests := []struct {
+		name        string
+		token       int64
+		allowShared bool
+		wantErr     error
+	}{
+		{name: "own workspace", token: own},
+		{name: "other workspace", token: foreign, wantErr: ErrGitTokenNotFound},
+		{name: "other workspace as superadmin", token: foreign, allowShared: true, wantErr: ErrGitTokenNotFound},
+		{name: "shared", token: shared, wantErr: ErrGitTokenNotFound},
+		{name: "shared as superadmin", token: shared, allowShared: true},
+		{name: "other host", token: elsewhere, wantErr: ErrGitTokenHost},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Create(ctx, CreateParams{
+				WorkspaceID:      ws.ID,
+				URL:              "https://github.com/acme/repo" + string(rune('a'+i)),
+				GitTokenID:       &tt.token,
+				AllowSharedToken: tt.allowShared,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// createToken stores a token row directly; its ciphertext is never opened
+func createToken(t *testing.T, workspaceID *int64, host string) int64 {
+	t.Helper()
+	ctx := context.Background()
+
+	now := time.Now().UnixNano()
+	token, err := db.Tx1(ctx, func(q *db.Queries) (db.GitToken, error) {
+		id, err := q.NextGitTokenID(ctx)
+		if err != nil {
+			return db.GitToken{}, err
+		}
+		return q.CreateGitToken(ctx, db.CreateGitTokenParams{
+			ID:             id,
+			Name:           t.Name(),
+			Provider:       "github",
+			TokenEncrypted: []byte("sealed"),
+			KeyID:          "test",
+			AadVersion:     2,
+			WorkspaceID:    pgconv.ToInt8(workspaceID),
+			Host:           pgconv.ToText(&host),
+			Created:        now,
+			Updated:        now,
+		})
+	})
+	if err != nil {
+		t.Fatalf("create git token: %v", err)
+	}
+	if workspaceID == nil {
+		t.Cleanup(func() {
+			_ = db.Query(ctx, func(q *db.Queries) error { return q.DeleteGitToken(ctx, token.ID) })
+		})
+	}
+	return token.ID
+}
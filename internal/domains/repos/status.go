@@ -0,0 +1,59 @@
+package repos
+
+// Status is the position of a repository in the indexing queue
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusCloning   Status = "cloning"
+	StatusIndexing  Status = "indexing"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// transitions lists the statuses each status may move to. Cloning and
+// indexing only fall back to pending through Release, when the job working
+// on them gives up or is reclaimed.
+var transitions = map[Status][]Status{
+	StatusPending:   {StatusCloning, StatusFailed},
+	StatusCloning:   {StatusIndexing, StatusFailed},
+	StatusIndexing:  {StatusCompleted, StatusFailed},
+	StatusCompleted: {StatusPending},
+	StatusFailed:    {StatusPending},
+}
+
+var (
+	// reindexable are the statuses Reindex queues again; the others already
+	// have a job queued or running
+	reindexable = []Status{StatusCompleted, StatusFailed}
+	// releasable are the statuses of a job in progress, which Release returns
+	// to pending
+	releasable = []Status{StatusCloning, StatusIndexing}
+)
+
+// Valid reports whether s is a known status
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransition reports whether a repository may move from one status to another
+func CanTransition(from, to Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// sourcesOf returns every status that may transition to the given status
+func sourcesOf(to Status) []Status {
+	var sources []Status
+	for from := range transitions {
+		if CanTransition(from, to) {
+			sources = append(sources, from)
+		}
+	}
+	return sources
+}
@@ -0,0 +1,81 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/testdb"
+)
+
+func TestInFlightStatusesStayOutOfPublicTransitions(t *testing.T) {
+	for _, from := range []Status{StatusCloning, StatusIndexing} {
+		if CanTransition(from, StatusPending) {
+			t.Errorf("%s may transition to pending outside Release", from)
+		}
+		if slices.Contains(reindexable, from) {
+			t.Errorf("Reindex accepts %s", from)
+		}
+	}
+
+	sources := sourcesOf(StatusPending)
+	slices.Sort(sources)
+	if want := []Status{StatusCompleted, StatusFailed}; !slices.Equal(sources, want) {
+		t.Errorf("sources of pending = %v, want %v", sources, want)
+	}
+}
+
+func TestReindexIndexingRepo(t *testing.T) {
+	testdb.Connect(t)
+	ctx := context.Background()
+
+	now := time.Now().UnixNano()
+	ws, err := db.Query1(ctx, func(q *db.Queries) (db.Workspace, error) {
+		return q.CreateWorkspace(ctx, db.CreateWorkspaceParams{
+			Name:     "reindex test",
+			Slug:     fmt.Sprintf("reindex-test-%d", now),
+			Settings: []byte("{}"),
+			Created:  now,
+			Updated:  now,
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := Create(ctx, CreateParams{WorkspaceID: ws.ID, URL: "https://github.com/acme/api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Query(ctx, func(q *db.Queries) error {
+			_, _ = q.Conn().Exec(ctx, "DELETE FROM jobs WHERE dedupe_key = $1", fmt.Sprintf("repo:%d", repo.ID))
+			_ = q.DeleteRepo(ctx, repo.ID)
+			return q.DeleteWorkspace(ctx, ws.ID)
+		})
+	})
+
+	for _, to := range []Status{StatusCloning, StatusIndexing} {
+		if _, err := Transition(ctx, repo.ID, to, nil); err != nil {
+			t.Fatalf("transition to %s: %v", to, err)
+		}
+	}
+
+	if _, err := Reindex(ctx, ws.ID, repo.ID); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("Reindex of an indexing repository = %v, want ErrInvalidTransition", err)
+	}
+
+	if _, err := Release(ctx, repo.ID, nil); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	got, err := GetByID(ctx, repo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusPending {
+		t.Errorf("status after release = %s, want pending", got.Status)
+	}
+}
@@ -0,0 +1,187 @@
+package repos
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gomantics/semantix/config"
+)
+
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderBitbucket = "bitbucket"
+	ProviderGeneric   = "generic"
+	ProviderLocal     = "local"
+)
+
+var ErrInvalidURL = errors.New("invalid repository url")
+
+// scpLikePattern matches scp-style SSH remotes such as git@github.com:owner/name.git
+var scpLikePattern = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+):([^/].*)$`)
+
+// URLInfo is the identity derived from a repository URL
+type URLInfo struct {
+	// URL is the normalised form used for uniqueness and cloning
+	URL      string
+	Provider string
+	Owner    string
+	Name     string
+}
+
+// ParseURL derives the provider, owner and name from a git remote URL.
+// SSH remotes for GitHub, GitLab and Bitbucket are normalised to HTTPS so tokens
+// can be injected when cloning; other remotes keep their scheme.
+func ParseURL(raw string) (*URLInfo, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: url is required", ErrInvalidURL)
+	}
+
+	if strings.HasPrefix(raw, "file://") || filepath.IsAbs(raw) {
+		return parseLocalURL(raw)
+	}
+
+	var scheme, host, repoPath string
+	if m := scpLikePattern.FindStringSubmatch(raw); m != nil && !strings.Contains(raw, "://") {
+		scheme, host, repoPath = "ssh", m[1], m[2]
+	} else {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+		}
+		switch u.Scheme {
+		case "https", "http", "ssh", "git":
+		default:
+			return nil, fmt.Errorf("%w: unsupported scheme %q", ErrInvalidURL, u.Scheme)
+		}
+		if _, hasPassword := u.User.Password(); hasPassword {
+			return nil, fmt.Errorf("%w: credentials must be supplied as a git token, not in the url", ErrInvalidURL)
+		}
+		scheme, host, repoPath = u.Scheme, u.Host, u.Path
+	}
+
+	host = strings.ToLower(host)
+	if host == "" {
+		return nil, fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+
+	repoPath = strings.Trim(repoPath, "/")
+	repoPath = strings.TrimSuffix(repoPath, ".git")
+
+	segments := strings.Split(repoPath, "/")
+	if len(segments) < 2 {
+		return nil, fmt.Errorf("%w: expected owner and repository name in path", ErrInvalidURL)
+	}
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return nil, fmt.Errorf("%w: malformed path", ErrInvalidURL)
+		}
+	}
+
+	info := &URLInfo{
+		Provider: detectProvider(host),
+		Owner:    strings.Join(segments[:len(segments)-1], "/"),
+		Name:     segments[len(segments)-1],
+	}
+
+	switch {
+	case scheme == "http" || scheme == "https":
+		info.URL = fmt.Sprintf("%s://%s/%s/%s", scheme, host, info.Owner, info.Name)
+	case info.Provider != ProviderGeneric:
+		// Known providers are cloned over HTTPS so a token can authenticate
+		hostname := strings.Split(host, ":")[0]
+		info.URL = fmt.Sprintf("https://%s/%s/%s", hostname, info.Owner, info.Name)
+	case scheme == "ssh" && !strings.Contains(raw, "://"):
+		info.URL = fmt.Sprintf("%s:%s/%s.git", strings.SplitN(raw, ":", 2)[0], info.Owner, info.Name)
+	default:
+		info.URL = fmt.Sprintf("%s://%s/%s/%s", scheme, host, info.Owner, info.Name)
+	}
+
+	return info, nil
+}
+
+// parseLocalURL handles file:// URLs and absolute paths to repositories on the
+// server's filesystem, such as mirrors. They are disabled unless configured,
+// and never point into the clone directory. The URL names the path with
+// symlinks resolved.
+func parseLocalURL(raw string) (*URLInfo, error) {
+	if !config.Indexing.AllowLocalRepos() {
+		return nil, fmt.Errorf("%w: local repositories are disabled", ErrInvalidURL)
+	}
+
+	localPath := raw
+	if strings.HasPrefix(raw, "file://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+		}
+		if u.Host != "" && u.Host != "localhost" {
+			return nil, fmt.Errorf("%w: file urls must not name a host", ErrInvalidURL)
+		}
+		localPath = u.Path
+	}
+
+	localPath = filepath.Clean(localPath)
+	if !filepath.IsAbs(localPath) || localPath == "/" {
+		return nil, fmt.Errorf("%w: local path must be an absolute repository path", ErrInvalidURL)
+	}
+
+	// Resolve symlinks so a link can't lead into another repository's checkout
+	resolved, err := filepath.EvalSymlinks(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: local path must exist", ErrInvalidURL)
+	}
+	if insideCloneDir(resolved) {
+		return nil, fmt.Errorf("%w: local path must be outside the clone directory", ErrInvalidURL)
+	}
+	localPath = resolved
+
+	owner := filepath.Base(filepath.Dir(localPath))
+	if owner == "/" || owner == "." {
+		owner = ProviderLocal
+	}
+
+	return &URLInfo{
+		URL:      "file://" + filepath.ToSlash(localPath),
+		Provider: ProviderLocal,
+		Owner:    owner,
+		Name:     strings.TrimSuffix(filepath.Base(localPath), ".git"),
+	}, nil
+}
+
+// insideCloneDir reports whether the resolved path is the clone directory or
+// within it, where the checkouts of every workspace live
+func insideCloneDir(path string) bool {
+	cloneDir, err := filepath.Abs(config.Indexing.CloneDir())
+	if err != nil {
+		return true
+	}
+	if resolved, err := filepath.EvalSymlinks(cloneDir); err == nil {
+		cloneDir = resolved
+	}
+	rel, err := filepath.Rel(cloneDir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// detectProvider maps a host to a known provider
+func detectProvider(host string) string {
+	hostname := strings.Split(host, ":")[0]
+	switch {
+	case hostname == "github.com" || strings.HasSuffix(hostname, ".github.com"):
+		return ProviderGitHub
+	case hostname == "gitlab.com" || strings.HasPrefix(hostname, "gitlab."):
+		return ProviderGitLab
+	case hostname == "bitbucket.org":
+		return ProviderBitbucket
+	default:
+		return ProviderGeneric
+	}
+}
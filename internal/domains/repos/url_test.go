@@ -0,0 +1,66 @@
+package repos
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLocalURL(t *testing.T) {
+	cloneDir := t.TempDir()
+	t.Setenv("CONFIG_INDEXING_CLONE_DIR", cloneDir)
+
+	checkout := CheckoutDir(1, 2)
+	if err := os.MkdirAll(checkout, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	mirror := filepath.Join(t.TempDir(), "acme", "mirror.git")
+	if err := os.MkdirAll(mirror, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	links := t.TempDir()
+	toCheckout := filepath.Join(links, "checkout")
+	toMirror := filepath.Join(links, "mirror.git")
+	for link, target := range map[string]string{toCheckout: checkout, toMirror: mirror} {
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resolvedMirror, err := filepath.EvalSymlinks(mirror)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParseURL(mirror); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("ParseURL with local repositories disabled = %v, want ErrInvalidURL", err)
+	}
+	t.Setenv("CONFIG_INDEXING_ALLOW_LOCAL_REPOS", "true")
+
+	for _, raw := range []string{
+		checkout,
+		"file://" + checkout,
+		toCheckout,
+		cloneDir,
+		filepath.Join(cloneDir, "1"),
+		filepath.Join(t.TempDir(), "missing"),
+		"/",
+	} {
+		if _, err := ParseURL(raw); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("ParseURL(%q) = %v, want ErrInvalidURL", raw, err)
+		}
+	}
+
+	for _, raw := range []string{mirror, "file://" + mirror, toMirror} {
+		info, err := ParseURL(raw)
+		if err != nil {
+			t.Fatalf("ParseURL(%q): %v", raw, err)
+		}
+		if want := "file://" + filepath.ToSlash(resolvedMirror); info.URL != want {
+			t.Errorf("ParseURL(%q).URL = %q, want %q", raw, info.URL, want)
+		}
+		if info.Provider != ProviderLocal || info.Owner != "acme" || info.Name != "mirror" {
+			t.Errorf("ParseURL(%q) = %+v", raw, info)
+		}
+	}
+}
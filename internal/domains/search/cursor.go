@@ -0,0 +1,52 @@
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// cursor is the position after the last result of a page. The offset says
+// where to resume reading the store; the last result lets the next page skip
+// anything it already returned if the ranking shifted in between.
+type cursor struct {
+	// Fingerprint ties the cursor to the query and filters it was issued for
+	Fingerprint string  `json:"f"`
+	Offset      int     `json:"o"`
+	LastScore   float32 `json:"s,omitempty"`
+	LastID      string  `json:"i,omitempty"`
+}
+
+func (c cursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, err
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return cursor{}, err
+	}
+	if c.Offset < 0 {
+		return cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// fingerprint identifies a search, so a cursor can't be replayed against a
+// different query, filter set or workspace
+func fingerprint(workspaceID int64, query string, f Filters, minScore float32) string {
+	b, _ := json.Marshal(struct {
+		WorkspaceID int64   `json:"w"`
+		Query       string  `json:"q"`
+		Filters     Filters `json:"f"`
+		MinScore    float32 `json:"m"`
+	}{workspaceID, query, f, minScore})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
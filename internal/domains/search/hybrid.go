@@ -0,0 +1,274 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/embedder"
+	"github.com/gomantics/semantix/internal/vectordb"
+	"github.com/gomantics/semantix/pkg/pgconv"
+	"github.com/gomantics/semantix/pkg/vectorstore"
+)
+
+var (
+	ErrInvalidWeights = errors.New("weights must not be negative and at least one must be positive")
+	ErrInvalidRRFK    = fmt.Errorf("rrf_k must be between 1 and %d", MaxRRFK)
+)
+
+const (
+	// DefaultRRFK is the constant from the original RRF paper
+	DefaultRRFK = 60
+	MaxRRFK     = 1000
+
+	// maxTerms bounds the words of a full-text query
+	maxTerms = 32
+)
+
+// candidate is one retriever's hit with the retriever's raw score
+type candidate struct {
+	id     string
+	result Result
+	score  float32
+}
+
+// Hybrid runs semantic, full-text and trigram retrieval over the workspace
+// and fuses the rankings with weighted reciprocal rank fusion. Without an
+// embedding provider it falls back to the keyword retrievers.
+func Hybrid(ctx context.Context, params HybridParams) ([]HybridResult, error) {
+	query, err := validate(params.Query, params.Filters)
+	if err != nil {
+		return nil, err
+	}
+	w := params.Weights
+	if w.Semantic < 0 || w.FullText < 0 || w.Trigram < 0 || w.Semantic+w.FullText+w.Trigram == 0 {
+		return nil, ErrInvalidWeights
+	}
+	if params.RRFK == 0 {
+		params.RRFK = DefaultRRFK
+	}
+	if params.RRFK < 1 || params.RRFK > MaxRRFK {
+		return nil, ErrInvalidRRFK
+	}
+	if params.Limit <= 0 || params.Limit > MaxLimit {
+		params.Limit = DefaultLimit
+	}
+
+	// Fusion only sees what each retriever returns, so fetch well past the page
+	depth := min(max(3*params.Limit, 50), 200)
+
+	type retriever struct {
+		name   string
+		weight float64
+		run    func() ([]candidate, error)
+	}
+	retrievers := []retriever{
+		{RetrieverSemantic, w.Semantic, func() ([]candidate, error) {
+			return semanticCandidates(ctx, params.WorkspaceID, query, params.Filters, depth)
+		}},
+		{RetrieverFullText, w.FullText, func() ([]candidate, error) {
+			return fullTextCandidates(ctx, params.WorkspaceID, query, params.Filters, depth)
+		}},
+		{RetrieverTrigram, w.Trigram, func() ([]candidate, error) {
+			return trigramCandidates(ctx, params.WorkspaceID, query, params.Filters, depth)
+		}},
+	}
+
+	var (
+		wg      sync.WaitGroup
+		ranked  = make([][]candidate, len(retrievers))
+		errs    = make([]error, len(retrievers))
+		noEmbed bool
+	)
+	for i, r := range retrievers {
+		if r.weight == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ranked[i], errs[i] = r.run()
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if errors.Is(err, ErrUnavailable) {
+			noEmbed = true
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s retrieval failed: %w", retrievers[i].name, err)
+		}
+	}
+	if noEmbed && w.FullText+w.Trigram == 0 {
+		return nil, ErrUnavailable
+	}
+
+	type fused struct {
+		HybridResult
+		id    string
+		score float64
+	}
+	byID := make(map[string]*fused)
+	for i, r := range retrievers {
+		for rank, c := range ranked[i] {
+			f := byID[c.id]
+			if f == nil {
+				f = &fused{HybridResult: HybridResult{Result: c.result}, id: c.id}
+				byID[c.id] = f
+			}
+			f.score += r.weight / float64(params.RRFK+rank+1)
+			f.Matches = append(f.Matches, Match{Retriever: r.name, Rank: rank + 1, Score: c.score})
+		}
+	}
+
+	all := make([]*fused, 0, len(byID))
+	for _, f := range byID {
+		all = append(all, f)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		return all[i].id < all[j].id
+	})
+
+	results := make([]HybridResult, 0, min(len(all), params.Limit))
+	for _, f := range all[:min(len(all), params.Limit)] {
+		f.Score = float32(f.score)
+		results = append(results, f.HybridResult)
+	}
+	return results, nil
+}
+
+func semanticCandidates(ctx context.Context, workspaceID int64, query string, f Filters, depth int) ([]candidate, error) {
+	e, err := embedder.Default()
+	if err != nil {
+		return nil, ErrUnavailable
+	}
+	vectors, err := e.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	hits, _, err := scan(ctx, vectordb.Default(), vectorstore.SearchParams{
+		Vector: vectors[0],
+		Filter: vectorFilter(workspaceID, f),
+	}, f, depth, cursor{})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]candidate, len(hits))
+	for i, h := range hits {
+		candidates[i] = candidate{id: h.ID, result: toResult(h), score: h.Score}
+	}
+	return candidates, nil
+}
+
+func fullTextCandidates(ctx context.Context, workspaceID int64, query string, f Filters, depth int) ([]candidate, error) {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	rows, err := db.Query1(ctx, func(q *db.Queries) ([]db.SearchChunksFullTextRow, error) {
+		return q.SearchChunksFullText(ctx, db.SearchChunksFullTextParams{
+			// Any word may match; ranking rewards chunks matching more of them
+			Terms:       strings.Join(terms, " | "),
+			WorkspaceID: workspaceID,
+			RepoIds:     f.RepoIDs,
+			Languages:   f.Languages,
+			ChunkTypes:  f.ChunkTypes,
+			Limit:       int32(lexicalLimit(f, depth)),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lexicalCandidates(rows, f, depth), nil
+}
+
+func trigramCandidates(ctx context.Context, workspaceID int64, query string, f Filters, depth int) ([]candidate, error) {
+	rows, err := db.Query1(ctx, func(q *db.Queries) ([]db.SearchChunksTrigramRow, error) {
+		return q.SearchChunksTrigram(ctx, db.SearchChunksTrigramParams{
+			Query:       query,
+			WorkspaceID: workspaceID,
+			RepoIds:     f.RepoIDs,
+			Languages:   f.Languages,
+			ChunkTypes:  f.ChunkTypes,
+			Limit:       int32(lexicalLimit(f, depth)),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]db.SearchChunksFullTextRow, len(rows))
+	for i, r := range rows {
+		converted[i] = db.SearchChunksFullTextRow(r)
+	}
+	return lexicalCandidates(converted, f, depth), nil
+}
+
+// lexicalLimit overfetches when path globs will drop rows after the query
+func lexicalLimit(f Filters, depth int) int {
+	if len(f.Include) > 0 || len(f.Exclude) > 0 {
+		return min(4*depth, maxScanned)
+	}
+	return depth
+}
+
+func lexicalCandidates(rows []db.SearchChunksFullTextRow, f Filters, depth int) []candidate {
+	var candidates []candidate
+	for _, r := range rows {
+		if len(candidates) == depth {
+			break
+		}
+		if !keep(f, r.FilePath) {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			id: r.ID,
+			result: Result{
+				RepoID:     r.RepoID,
+				FilePath:   r.FilePath,
+				StartLine:  int(r.StartLine),
+				EndLine:    int(r.EndLine),
+				Language:   r.Language,
+				ChunkType:  r.ChunkType,
+				SymbolName: pgconv.FromText(r.SymbolName),
+				Snippet:    snippet(r.Content),
+			},
+			score: r.Score,
+		})
+	}
+	return candidates
+}
+
+// queryTerms splits a query into the lowercase words the chunks table's
+// search_vector is built from. Punctuation separates words, matching
+// code_words in the schema.
+func queryTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, word := range strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		word = strings.ToLower(word)
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+		if len(terms) == maxTerms {
+			break
+		}
+	}
+	return terms
+}
@@ -0,0 +1,90 @@
+package search
+
+// Params are the parameters of a semantic search within a workspace
+type Params struct {
+	WorkspaceID int64
+	Query       string
+	Filters     Filters
+	// MinScore drops results scoring lower; 0 keeps everything
+	MinScore float32
+	Limit    int
+	// Cursor continues from a previous page; empty starts at the top
+	Cursor string
+}
+
+// Filters narrow a search. Empty fields don't filter.
+type Filters struct {
+	RepoIDs    []int64  `json:"repo_ids,omitempty"`
+	Languages  []string `json:"languages,omitempty"`
+	ChunkTypes []string `json:"chunk_types,omitempty"`
+	// Include keeps only paths matching at least one glob
+	Include []string `json:"include,omitempty"`
+	// Exclude drops paths matching any glob
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Result is a chunk matching a search
+type Result struct {
+	RepoID     int64   `json:"repo_id"`
+	FilePath   string  `json:"file_path"`
+	StartLine  int     `json:"start_line"`
+	EndLine    int     `json:"end_line"`
+	Language   string  `json:"language"`
+	ChunkType  string  `json:"chunk_type"`
+	SymbolName *string `json:"symbol_name,omitempty"`
+	Snippet    string  `json:"snippet"`
+	Score      float32 `json:"score"`
+}
+
+// Page is one page of search results
+type Page struct {
+	Results []Result
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string
+}
+
+// Retrievers that contribute candidates to a hybrid search
+const (
+	RetrieverSemantic = "semantic"
+	RetrieverFullText = "fulltext"
+	RetrieverTrigram  = "trigram"
+)
+
+// Weights scale each retriever's contribution to the fused score. A zero
+// weight turns the retriever off.
+type Weights struct {
+	Semantic float64 `json:"semantic"`
+	FullText float64 `json:"fulltext"`
+	Trigram  float64 `json:"trigram"`
+}
+
+// DefaultWeights favours meaning and exact words equally over fuzzy matches
+var DefaultWeights = Weights{Semantic: 1, FullText: 1, Trigram: 0.5}
+
+// HybridParams are the parameters of a hybrid search within a workspace
+type HybridParams struct {
+	WorkspaceID int64
+	Query       string
+	Filters     Filters
+	Weights     Weights
+	// RRFK dampens the advantage of top ranks; larger values flatten them
+	RRFK  int
+	Limit int
+}
+
+// Match is one retriever's verdict on a hybrid result
+type Match struct {
+	Retriever string `json:"retriever"`
+	// Rank is the 1-based position in the retriever's own ranking
+	Rank int `json:"rank"`
+	// Score is the retriever's raw score: cosine similarity, ts_rank_cd or
+	// trigram similarity
+	Score float32 `json:"score"`
+}
+
+// HybridResult is a chunk ranked by reciprocal rank fusion. Score is the
+// fused score.
+type HybridResult struct {
+	Result
+	Matches []Match `json:"matches"`
+}
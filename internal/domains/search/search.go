@@ -0,0 +1,236 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gomantics/semantix/internal/embedder"
+	"github.com/gomantics/semantix/internal/vectordb"
+	"github.com/gomantics/semantix/pkg/glob"
+	"github.com/gomantics/semantix/pkg/vectorstore"
+)
+
+var (
+	ErrEmptyQuery    = errors.New("query is required")
+	ErrQueryTooLong  = fmt.Errorf("query must be at most %d characters", MaxQueryLength)
+	ErrInvalidGlob   = errors.New("invalid path glob")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrUnavailable is returned when no embedding provider is configured
+	ErrUnavailable = errors.New("search is unavailable until embeddings are configured")
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+	// MaxQueryLength bounds the text sent to the embedding provider
+	MaxQueryLength = 2000
+	// MaxSnippetBytes bounds each result's snippet
+	MaxSnippetBytes = 2000
+
+	// maxScanned bounds how many store results one page may read through
+	// while applying path globs, so a selective glob can't scan the whole
+	// workspace in a single request
+	maxScanned = 1000
+)
+
+// Search embeds the query and returns the closest chunks in the workspace.
+// The workspace is always part of the vector filter; the other filters only
+// narrow it further.
+func Search(ctx context.Context, params Params) (*Page, error) {
+	query, err := validate(params.Query, params.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if params.Limit <= 0 || params.Limit > MaxLimit {
+		params.Limit = DefaultLimit
+	}
+
+	fp := fingerprint(params.WorkspaceID, query, params.Filters, params.MinScore)
+	cur := cursor{Fingerprint: fp}
+	if params.Cursor != "" {
+		if cur, err = decodeCursor(params.Cursor); err != nil || cur.Fingerprint != fp {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	e, err := embedder.Default()
+	if err != nil {
+		return nil, ErrUnavailable
+	}
+	vectors, err := e.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	hits, next, err := scan(ctx, vectordb.Default(), vectorstore.SearchParams{
+		Vector:   vectors[0],
+		Filter:   vectorFilter(params.WorkspaceID, params.Filters),
+		MinScore: params.MinScore,
+	}, params.Filters, params.Limit, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Results: make([]Result, len(hits)), NextCursor: next}
+	for i, h := range hits {
+		page.Results[i] = toResult(h)
+	}
+	return page, nil
+}
+
+// validate checks the parts of a request shared by every kind of search and
+// returns the trimmed query
+func validate(query string, f Filters) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", ErrEmptyQuery
+	}
+	if utf8.RuneCountInString(query) > MaxQueryLength {
+		return "", ErrQueryTooLong
+	}
+	for _, patterns := range [][]string{f.Include, f.Exclude} {
+		for _, p := range patterns {
+			if err := glob.Validate(p); err != nil {
+				return "", fmt.Errorf("%w: %q", ErrInvalidGlob, p)
+			}
+		}
+	}
+	return query, nil
+}
+
+// vectorFilter always scopes to the workspace; request filters only narrow it
+func vectorFilter(workspaceID int64, f Filters) vectorstore.Filter {
+	return vectorstore.Filter{
+		WorkspaceID: workspaceID,
+		RepoIDs:     f.RepoIDs,
+		Languages:   f.Languages,
+		ChunkTypes:  f.ChunkTypes,
+	}
+}
+
+// scan reads store results from the cursor onwards until limit results pass
+// the path globs, which can't be expressed as store filters, and returns them
+// with the cursor of the following page.
+func scan(ctx context.Context, store vectorstore.Store, sp vectorstore.SearchParams, f Filters, limit int, cur cursor) ([]vectorstore.Result, string, error) {
+	round := limit + 1
+	if len(f.Include) > 0 || len(f.Exclude) > 0 {
+		round = max(4*limit, 50)
+	}
+
+	// hit pairs a kept result with the store offset just past it
+	type hit struct {
+		vectorstore.Result
+		offset int
+	}
+
+	var (
+		hits      []hit
+		offset    = cur.Offset
+		exhausted bool
+	)
+	for scanned := 0; len(hits) <= limit && scanned < maxScanned; {
+		sp.Offset, sp.Limit = offset, round
+		results, err := store.Search(ctx, sp)
+		if err != nil {
+			return nil, "", err
+		}
+		scanned += len(results)
+		// Stores order equal scores arbitrarily; IDs make the order total
+		sort.SliceStable(results, func(i, j int) bool {
+			return before(results[i].Score, results[i].ID, results[j].Score, results[j].ID)
+		})
+
+		for _, r := range results {
+			offset++
+			// Points added since the previous page push seen results down
+			// past the offset; skip anything ranked at or above the cursor
+			if cur.LastID != "" && !before(cur.LastScore, cur.LastID, r.Score, r.ID) {
+				continue
+			}
+			if !keep(f, r.Payload.FilePath) {
+				continue
+			}
+			hits = append(hits, hit{r, offset})
+			if len(hits) > limit {
+				break
+			}
+		}
+		if len(results) < round {
+			exhausted = true
+			break
+		}
+	}
+
+	results := make([]vectorstore.Result, 0, min(len(hits), limit))
+	for _, h := range hits[:min(len(hits), limit)] {
+		results = append(results, h.Result)
+	}
+
+	next := cursor{Fingerprint: cur.Fingerprint, Offset: offset, LastScore: cur.LastScore, LastID: cur.LastID}
+	switch {
+	case len(hits) > limit:
+		// The extra hit only proves there's another page
+		last := hits[limit-1]
+		next.Offset, next.LastScore, next.LastID = last.offset, last.Score, last.ID
+	case exhausted:
+		return results, "", nil
+	case len(hits) > 0:
+		// The scan budget ran out; continue from where it stopped
+		last := hits[len(hits)-1]
+		next.LastScore, next.LastID = last.Score, last.ID
+	}
+	return results, next.encode(), nil
+}
+
+// before reports whether (s1, id1) ranks above (s2, id2)
+func before(s1 float32, id1 string, s2 float32, id2 string) bool {
+	if s1 != s2 {
+		return s1 > s2
+	}
+	return id1 < id2
+}
+
+func keep(f Filters, path string) bool {
+	if len(f.Include) > 0 && !glob.MatchAny(f.Include, path) {
+		return false
+	}
+	return !glob.MatchAny(f.Exclude, path)
+}
+
+func toResult(r vectorstore.Result) Result {
+	var symbol *string
+	if r.Payload.SymbolName != "" {
+		symbol = &r.Payload.SymbolName
+	}
+	return Result{
+		RepoID:     r.Payload.RepoID,
+		FilePath:   r.Payload.FilePath,
+		StartLine:  r.Payload.StartLine,
+		EndLine:    r.Payload.EndLine,
+		Language:   r.Payload.Language,
+		ChunkType:  r.Payload.ChunkType,
+		SymbolName: symbol,
+		Snippet:    snippet(r.Payload.Content),
+		Score:      r.Score,
+	}
+}
+
+// snippet truncates content to MaxSnippetBytes at a line boundary where
+// possible
+func snippet(content string) string {
+	if len(content) <= MaxSnippetBytes {
+		return content
+	}
+	cut := content[:MaxSnippetBytes]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		return cut[:i]
+	}
+	for !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	return cut
+}
@@ -31,6 +31,8 @@ type UpdateParams struct {
 type ListParams struct {
 	Limit  int
 	Offset int
+	// IDs restricts the list to these workspaces unless nil
+	IDs []int64
 }
 
 // ListResult contains the result of listing workspaces
@@ -4,10 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
+	"github.com/gomantics/semantix/config"
 	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/internal/vectordb"
 	"github.com/gomantics/semantix/pkg/pgconv"
+	"github.com/gomantics/semantix/pkg/vectorstore"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -78,6 +85,14 @@ func GetBySlug(ctx context.Context, slug string) (*Workspace, error) {
 	return toWorkspace(dbWorkspace), nil
 }
 
+// Resolve looks up a workspace by numeric ID or, failing that, by slug
+func Resolve(ctx context.Context, ref string) (*Workspace, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return GetByID(ctx, id)
+	}
+	return GetBySlug(ctx, ref)
+}
+
 // List retrieves workspaces with pagination
 func List(ctx context.Context, params ListParams) (*ListResult, error) {
 	if params.Limit <= 0 || params.Limit > 100 {
@@ -90,6 +105,24 @@ func List(ctx context.Context, params ListParams) (*ListResult, error) {
 	}
 
 	data, err := db.Tx1(ctx, func(q *db.Queries) (listData, error) {
+		if params.IDs != nil {
+			dbWorkspaces, err := q.ListWorkspacesByIDs(ctx, db.ListWorkspacesByIDsParams{
+				Ids:    params.IDs,
+				Limit:  int32(params.Limit),
+				Offset: int32(params.Offset),
+			})
+			if err != nil {
+				return listData{}, err
+			}
+
+			total, err := q.CountWorkspacesByIDs(ctx, params.IDs)
+			if err != nil {
+				return listData{}, err
+			}
+
+			return listData{workspaces: dbWorkspaces, total: total}, nil
+		}
+
 		dbWorkspaces, err := q.ListWorkspaces(ctx, db.ListWorkspacesParams{
 			Limit:  int32(params.Limit),
 			Offset: int32(params.Offset),
@@ -156,8 +189,20 @@ func Update(ctx context.Context, id int64, params UpdateParams) (*Workspace, err
 	return toWorkspace(dbWorkspace), nil
 }
 
+// Delete removes the workspace, its repositories, their vectors and their
+// local checkouts
 func Delete(ctx context.Context, id int64) error {
-	return db.Tx(ctx, func(q *db.Queries) error {
+	if _, err := GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	// Vectors go first so a failure never leaves orphans searchable
+	err := vectordb.Default().DeleteByFilter(ctx, vectorstore.Filter{WorkspaceID: id})
+	if err != nil {
+		return fmt.Errorf("failed to delete workspace vectors: %w", err)
+	}
+
+	err = db.Tx(ctx, func(q *db.Queries) error {
 		_, err := q.GetWorkspaceByID(ctx, id)
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
@@ -166,8 +211,36 @@ func Delete(ctx context.Context, id int64) error {
 			return err
 		}
 
+		// No foreign keys, so remove the workspace's repositories explicitly
+		if err := q.DeleteChunksByWorkspace(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeleteFilesByWorkspace(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeleteFileContentsByWorkspace(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeleteIndexRunsByWorkspace(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeleteReposByWorkspace(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeleteWorkspaceMembersByWorkspace(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeleteGitTokensByWorkspace(ctx, id); err != nil {
+			return err
+		}
+
 		return q.DeleteWorkspace(ctx, id)
 	})
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(filepath.Join(config.Indexing.CloneDir(), strconv.FormatInt(id, 10)))
 }
 
 func toWorkspace(dbWorkspace db.Workspace) *Workspace {
@@ -0,0 +1,234 @@
+package embedder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gomantics/semantix/config"
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/embeddings"
+	"go.uber.org/zap"
+)
+
+// evictionLockKey keeps replicas from evicting concurrently ("embcache" in ASCII)
+const evictionLockKey int64 = 0x656d626361636865
+
+// evictionBatch bounds each LRU delete so eviction never holds long locks
+const evictionBatch = 10_000
+
+// Cached serves embeddings from the embedding_cache table, calling the
+// wrapped embedder only for inputs it hasn't seen with the same model.
+// Hits and misses are reported to the context's recorder.
+type Cached struct {
+	next embeddings.Embedder
+}
+
+// NewCached wraps an embedder with the database cache
+func NewCached(next embeddings.Embedder) *Cached {
+	return &Cached{next: next}
+}
+
+func (c *Cached) Model() string {
+	return c.next.Model()
+}
+
+func (c *Cached) Dimensions() int {
+	return c.next.Dimensions()
+}
+
+func (c *Cached) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	hashes := make([]string, len(inputs))
+	unique := make(map[string]int, len(inputs)) // hash -> first input index
+	var keys []string
+	for i, input := range inputs {
+		hashes[i] = ContentHash(input)
+		if _, ok := unique[hashes[i]]; !ok {
+			unique[hashes[i]] = i
+			keys = append(keys, hashes[i])
+		}
+	}
+
+	model := c.Model()
+	cached, err := db.Query1(ctx, func(q *db.Queries) ([]db.GetCachedEmbeddingsRow, error) {
+		return q.GetCachedEmbeddings(ctx, db.GetCachedEmbeddingsParams{
+			Model:  model,
+			Hashes: keys,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding cache: %w", err)
+	}
+
+	found := make(map[string][]float32, len(cached))
+	var hitKeys []string
+	for _, row := range cached {
+		// A vector of the wrong size can only come from a bug or a model
+		// that changed dimensions under the same name; re-embed it
+		v, ok := unpack(row.Embedding, c.Dimensions())
+		if !ok {
+			continue
+		}
+		found[row.ContentHash] = v
+		hitKeys = append(hitKeys, row.ContentHash)
+	}
+
+	var missKeys []string
+	var missInputs []string
+	for _, key := range keys {
+		if _, ok := found[key]; !ok {
+			missKeys = append(missKeys, key)
+			missInputs = append(missInputs, inputs[unique[key]])
+		}
+	}
+
+	now := time.Now().UnixNano()
+	if len(missInputs) > 0 {
+		vectors, err := c.next.Embed(ctx, missInputs)
+		if err != nil {
+			return nil, err
+		}
+
+		packed := make([][]byte, len(vectors))
+		for i, v := range vectors {
+			found[missKeys[i]] = v
+			packed[i] = pack(v)
+		}
+
+		err = db.Query(ctx, func(q *db.Queries) error {
+			return q.PutCachedEmbeddings(ctx, db.PutCachedEmbeddingsParams{
+				Hashes:     missKeys,
+				Model:      model,
+				Embeddings: packed,
+				Now:        now,
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write embedding cache: %w", err)
+		}
+	}
+
+	if len(hitKeys) > 0 {
+		err := db.Query(ctx, func(q *db.Queries) error {
+			return q.TouchCachedEmbeddings(ctx, db.TouchCachedEmbeddingsParams{
+				LastUsed: now,
+				Model:    model,
+				Hashes:   hitKeys,
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update embedding cache: %w", err)
+		}
+	}
+
+	// Hits and misses count inputs, so duplicates within a call are hits
+	misses := int64(len(missKeys))
+	embeddings.Record(ctx, embeddings.Stats{
+		CacheHits:   int64(len(inputs)) - misses,
+		CacheMisses: misses,
+	})
+
+	out := make([][]float32, len(inputs))
+	for i, h := range hashes {
+		out[i] = found[h]
+	}
+	return out, nil
+}
+
+// ContentHash is the cache key of an input: hex SHA-256
+func ContentHash(input string) string {
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
+
+// Evict deletes cache entries unused for ttl, then the least recently used
+// entries beyond maxRows. A zero ttl or maxRows disables that bound.
+// Only one replica evicts at a time; others return immediately.
+func Evict(ctx context.Context, maxRows int64, ttl time.Duration) (int64, error) {
+	return db.Tx1(ctx, func(q *db.Queries) (int64, error) {
+		locked, err := q.TryAdvisoryXactLock(ctx, evictionLockKey)
+		if err != nil || !locked {
+			return 0, err
+		}
+
+		var deleted int64
+		if ttl > 0 {
+			n, err := q.DeleteCachedEmbeddingsUnusedSince(ctx, time.Now().Add(-ttl).UnixNano())
+			if err != nil {
+				return deleted, err
+			}
+			deleted += n
+		}
+
+		if maxRows > 0 {
+			count, err := q.CountCachedEmbeddings(ctx)
+			if err != nil {
+				return deleted, err
+			}
+			for excess := count - maxRows; excess > 0; {
+				n, err := q.DeleteLeastRecentlyUsedEmbeddings(ctx, int32(min(excess, evictionBatch)))
+				if err != nil {
+					return deleted, err
+				}
+				if n == 0 {
+					break
+				}
+				deleted += n
+				excess -= n
+			}
+		}
+
+		return deleted, nil
+	})
+}
+
+// RunEviction evicts on the given interval until ctx is cancelled, using
+// the bounds from config
+func RunEviction(ctx context.Context, l *zap.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ttl := time.Duration(config.Embeddings.CacheTtlDays()) * 24 * time.Hour
+		n, err := Evict(ctx, config.Embeddings.CacheMaxRows(), ttl)
+		if err != nil && ctx.Err() == nil {
+			l.Error("failed to evict embedding cache", zap.Error(err))
+		}
+		if n > 0 {
+			l.Info("evicted embedding cache entries", zap.Int64("count", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func pack(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(x))
+	}
+	return b
+}
+
+func unpack(b []byte, dims int) ([]float32, bool) {
+	if len(b) != 4*dims {
+		return nil, false
+	}
+	v := make([]float32, dims)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v, true
+}
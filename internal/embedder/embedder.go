@@ -0,0 +1,88 @@
+// Package embedder provides the embedding provider selected in config
+package embedder
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gomantics/semantix/config"
+	"github.com/gomantics/semantix/pkg/embeddings"
+	"go.uber.org/zap"
+)
+
+// ErrNotConfigured is returned by Default when no embedding provider is usable
+var ErrNotConfigured = errors.New("no embedding provider configured")
+
+// Providers selectable with embeddings.provider
+const (
+	ProviderOpenAI = "openai"
+	// ProviderLocal embeds offline, for air-gapped deployments and tests
+	ProviderLocal = "local"
+)
+
+var defaultEmbedder embeddings.Embedder
+
+// Init creates the configured embedder. A missing OpenAI API key leaves
+// embeddings disabled rather than failing startup, so the API stays usable.
+func Init(l *zap.Logger) error {
+	e, err := New()
+	if errors.Is(err, ErrNotConfigured) {
+		l.Warn("embeddings disabled; indexing will fail until a provider is configured", zap.Error(err))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+
+	if config.Embeddings.CacheEnabled() {
+		e = NewCached(e)
+	}
+
+	defaultEmbedder = e
+	l.Info("embedder initialized",
+		zap.String("provider", config.Embeddings.Provider()),
+		zap.String("model", e.Model()),
+		zap.Int("dimensions", e.Dimensions()),
+		zap.Bool("cache", config.Embeddings.CacheEnabled()),
+	)
+	return nil
+}
+
+// Default returns the embedder created by Init
+func Default() (embeddings.Embedder, error) {
+	if defaultEmbedder == nil {
+		return nil, ErrNotConfigured
+	}
+	return defaultEmbedder, nil
+}
+
+// New creates an embedder from config
+func New() (embeddings.Embedder, error) {
+	switch provider := config.Embeddings.Provider(); provider {
+	case ProviderOpenAI:
+		apiKey := config.Openai.ApiKey()
+		baseURL := config.Embeddings.BaseUrl()
+		// Self-hosted compatible servers often need no key; OpenAI itself does
+		if apiKey == "" && (baseURL == "" || baseURL == embeddings.DefaultBaseURL) {
+			return nil, fmt.Errorf("%w: openai.api_key is empty", ErrNotConfigured)
+		}
+
+		return embeddings.NewOpenAI(embeddings.OpenAIOptions{
+			APIKey:         apiKey,
+			BaseURL:        baseURL,
+			Model:          config.Embeddings.Model(),
+			Dimensions:     int(config.Embeddings.Dimensions()),
+			MaxBatchInputs: int(config.Embeddings.BatchSize()),
+			MaxRetries:     int(config.Embeddings.MaxRetries()),
+			HTTPClient: &http.Client{
+				Timeout: time.Duration(config.Embeddings.TimeoutSeconds()) * time.Second,
+			},
+		})
+	case ProviderLocal:
+		return embeddings.NewLocal(int(config.Embeddings.Dimensions())), nil
+	default:
+		return nil, fmt.Errorf("unknown embeddings provider %q", provider)
+	}
+}
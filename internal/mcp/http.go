@@ -0,0 +1,474 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomantics/semantix/internal/domains/workspaces"
+	"go.uber.org/zap"
+)
+
+// Headers of the Streamable HTTP transport
+const (
+	HeaderSessionID       = "Mcp-Session-Id"
+	HeaderProtocolVersion = "Mcp-Protocol-Version"
+)
+
+const (
+	// SessionIdleTimeout is how long an unused session is kept
+	SessionIdleTimeout = time.Hour
+	// MaxSessions bounds the sessions one process keeps
+	MaxSessions = 10000
+
+	// keepAliveInterval spaces the comments that stop proxies from closing a
+	// quiet stream
+	keepAliveInterval = 15 * time.Second
+)
+
+var errTooManySessions = errors.New("too many sessions")
+
+// HTTPOptions configures the Streamable HTTP transport
+type HTTPOptions struct {
+	// AllowedOrigins are the browser origins allowed to connect. Requests
+	// without an Origin header, from non-browser clients, are always allowed.
+	AllowedOrigins []string
+}
+
+// HTTPTransport serves MCP's Streamable HTTP transport for any workspace.
+// POST carries client messages; tool calls from clients accepting
+// text/event-stream are answered with a stream carrying progress
+// notifications before the result. GET opens the session's one standalone
+// stream, which carries resource updates while Watch runs.
+//
+// Sessions live in memory, so replicas behind a load balancer need affinity
+// on the Mcp-Session-Id header. A session belongs to the workspace and API
+// key that initialized it; requests naming it with another key are answered
+// as if it didn't exist.
+type HTTPTransport struct {
+	l    *zap.Logger
+	opts HTTPOptions
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// session is one client's connection to a workspace
+type session struct {
+	workspaceID int64
+	apiKeyID    int64
+	lastUsed    time.Time
+	// inflight cancels the session's running requests by JSON-RPC ID
+	inflight map[string]context.CancelFunc
+	sub      *subscriber
+}
+
+// idle reports whether the session has gone unused past SessionIdleTimeout
+func (s *session) idle(now time.Time) bool {
+	return len(s.inflight) == 0 && now.Sub(s.lastUsed) > SessionIdleTimeout
+}
+
+// caller is the workspace and API key a request was authorised for
+type caller struct {
+	workspaceID int64
+	apiKeyID    int64
+}
+
+// owns reports whether the session was created by the caller
+func (s *session) owns(c caller) bool {
+	return s.workspaceID == c.workspaceID && s.apiKeyID == c.apiKeyID
+}
+
+// streamID tracks the standalone stream among the session's requests. It
+// can't clash with a JSON-RPC ID, which is always valid JSON.
+var streamID = json.RawMessage("stream")
+
+func NewHTTPTransport(l *zap.Logger, opts HTTPOptions) *HTTPTransport {
+	return &HTTPTransport{l: l, opts: opts, sessions: make(map[string]*session)}
+}
+
+// Serve handles one HTTP request for the workspace, made with the API key
+// apiKeyID
+func (t *HTTPTransport) Serve(w http.ResponseWriter, r *http.Request, ws *workspaces.Workspace, apiKeyID int64) {
+	// Browsers on other sites, or on rebound DNS names, mustn't reach the server
+	if origin := r.Header.Get("Origin"); origin != "" && !slices.Contains(t.opts.AllowedOrigins, origin) {
+		writeJSON(w, http.StatusForbidden, errorResponse(nullID, codeInvalidRequest, "origin not allowed"))
+		return
+	}
+	if v := r.Header.Get(HeaderProtocolVersion); v != "" && !slices.Contains(supportedVersions, v) {
+		writeJSON(w, http.StatusBadRequest, errorResponse(nullID, codeInvalidRequest, "unsupported protocol version: "+v))
+		return
+	}
+
+	c := caller{workspaceID: ws.ID, apiKeyID: apiKeyID}
+	switch r.Method {
+	case http.MethodPost:
+		t.post(w, r, ws, c)
+	case http.MethodGet:
+		t.listen(w, r, c)
+	case http.MethodDelete:
+		t.delete(w, r, c)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse(nullID, codeInvalidRequest, "method not allowed"))
+	}
+}
+
+func (t *HTTPTransport) post(w http.ResponseWriter, r *http.Request, ws *workspaces.Workspace, c caller) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxMessageBytes+1))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(nullID, codeParseError, "failed to read request body"))
+		return
+	}
+	if len(body) > MaxMessageBytes {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse(nullID, codeInvalidRequest, "message too large"))
+		return
+	}
+
+	req, resp := parse(body)
+	if resp != nil {
+		writeJSON(w, http.StatusBadRequest, resp)
+		return
+	}
+
+	s := NewServer(t.l, ws)
+	if req.Method == "initialize" {
+		t.initialize(w, r, s, req, c)
+		return
+	}
+
+	id := r.Header.Get(HeaderSessionID)
+	if id == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse(req.ID, codeInvalidRequest, "missing "+HeaderSessionID+" header"))
+		return
+	}
+	sub := t.touch(id, c)
+	if sub == nil {
+		// 404 tells the client to initialize a new session
+		writeJSON(w, http.StatusNotFound, errorResponse(req.ID, codeInvalidRequest, "session not found"))
+		return
+	}
+
+	if req.isNotification() {
+		if req.Method == "notifications/cancelled" {
+			t.cancel(id, req.Params)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(withSubscriber(r.Context(), sub))
+	t.track(id, req.ID, cancel)
+	defer func() {
+		t.untrack(id, req.ID)
+		cancel()
+	}()
+
+	if req.Method != "tools/call" {
+		writeJSON(w, http.StatusOK, s.handle(ctx, req))
+		return
+	}
+
+	// Tool calls may outlast the server's write timeout; their time is
+	// bounded by the domains they call instead
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		t.l.Debug("failed to clear write deadline", zap.Error(err))
+	}
+	if accepts(r, "text/event-stream") {
+		t.stream(ctx, w, s, req)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.handle(ctx, req))
+}
+
+func (t *HTTPTransport) initialize(w http.ResponseWriter, r *http.Request, s *Server, req *request, c caller) {
+	resp := s.handle(r.Context(), req)
+	if _, ok := resp.Result.(*initializeResult); ok {
+		id, err := t.create(c)
+		if err != nil {
+			if errors.Is(err, errTooManySessions) {
+				writeJSON(w, http.StatusServiceUnavailable, errorResponse(req.ID, codeInternalError, err.Error()))
+				return
+			}
+			t.l.Error("failed to create session", zap.Error(err))
+			writeJSON(w, http.StatusInternalServerError, errorResponse(req.ID, codeInternalError, "failed to create session"))
+			return
+		}
+		w.Header().Set(HeaderSessionID, id)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// stream answers a request with server-sent events: any notifications sent
+// while it runs, then the response
+func (t *HTTPTransport) stream(ctx context.Context, w http.ResponseWriter, s *Server, req *request) {
+	write := t.writer(w)
+	message := func(b []byte) {
+		write("event: message\ndata: " + string(b) + "\n\n")
+	}
+	write(": stream opened\n\n")
+
+	ctx = withNotifier(ctx, t.notifier(message))
+
+	done := make(chan *response, 1)
+	go func() {
+		done <- s.handle(ctx, req)
+	}()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case resp := <-done:
+			message(s.encode(resp))
+			return
+		case <-ticker.C:
+			write(": keepalive\n\n")
+		}
+	}
+}
+
+// listen holds the session's standalone stream open until the client
+// disconnects, sending its resource updates
+func (t *HTTPTransport) listen(w http.ResponseWriter, r *http.Request, c caller) {
+	if !accepts(r, "text/event-stream") {
+		writeJSON(w, http.StatusNotAcceptable, errorResponse(nullID, codeInvalidRequest, "GET needs Accept: text/event-stream"))
+		return
+	}
+	id := r.Header.Get(HeaderSessionID)
+	if id == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse(nullID, codeInvalidRequest, "missing "+HeaderSessionID+" header"))
+		return
+	}
+	sub := t.touch(id, c)
+	if sub == nil {
+		writeJSON(w, http.StatusNotFound, errorResponse(nullID, codeInvalidRequest, "session not found"))
+		return
+	}
+
+	// The writer sends its headers on first use, leaving a conflict to be
+	// answered with JSON
+	write := t.writer(w)
+	send := t.notifier(func(b []byte) {
+		write("event: message\ndata: " + string(b) + "\n\n")
+	})
+	if !sub.attach(send) {
+		writeJSON(w, http.StatusConflict, errorResponse(nullID, codeInvalidRequest, "session already has a stream open"))
+		return
+	}
+	// Tracking the stream keeps the session alive and lets DELETE close it
+	ctx, cancel := context.WithCancel(r.Context())
+	t.track(id, streamID, cancel)
+	defer func() {
+		sub.detach()
+		t.untrack(id, streamID)
+		cancel()
+	}()
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		t.l.Debug("failed to clear write deadline", zap.Error(err))
+	}
+	write(": stream opened\n\n")
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			write(": keepalive\n\n")
+		}
+	}
+}
+
+// writer starts an event stream on w and returns a function writing raw
+// events to it. Writes are serialised and flushed at once.
+func (t *HTTPTransport) writer(w http.ResponseWriter) func(event string) {
+	rc := http.NewResponseController(w)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	// Stops nginx from buffering the stream
+	h.Set("X-Accel-Buffering", "no")
+
+	var (
+		mu      sync.Mutex
+		started bool
+	)
+	return func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !started {
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if _, err := io.WriteString(w, event); err != nil {
+			// The client went away; the request context ends the stream
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			t.l.Debug("failed to flush stream", zap.Error(err))
+		}
+	}
+}
+
+// notifier encodes notifications for message
+func (t *HTTPTransport) notifier(message func(b []byte)) notifier {
+	return func(n notification) {
+		b, err := json.Marshal(n)
+		if err != nil {
+			t.l.Error("failed to encode notification", zap.Error(err))
+			return
+		}
+		message(b)
+	}
+}
+
+func (t *HTTPTransport) delete(w http.ResponseWriter, r *http.Request, c caller) {
+	id := r.Header.Get(HeaderSessionID)
+
+	t.mu.Lock()
+	sess, ok := t.sessions[id]
+	ok = ok && sess.owns(c)
+	if ok {
+		for _, cancel := range sess.inflight {
+			cancel()
+		}
+		t.drop(id)
+	}
+	t.mu.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse(nullID, codeInvalidRequest, "session not found"))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// create starts a session for the caller, first dropping idle ones
+func (t *HTTPTransport) create(c caller) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(b)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for sid, sess := range t.sessions {
+		if sess.idle(now) {
+			t.drop(sid)
+		}
+	}
+	if len(t.sessions) >= MaxSessions {
+		return "", errTooManySessions
+	}
+
+	sess := &session{
+		workspaceID: c.workspaceID,
+		apiKeyID:    c.apiKeyID,
+		lastUsed:    now,
+		inflight:    make(map[string]context.CancelFunc),
+		sub:         newSubscriber(c.workspaceID, nil),
+	}
+	t.sessions[id] = sess
+	register(sess.sub)
+	return id, nil
+}
+
+// drop forgets a session; t.mu must be held
+func (t *HTTPTransport) drop(id string) {
+	if sess, ok := t.sessions[id]; ok {
+		unregister(sess.sub)
+		delete(t.sessions, id)
+	}
+}
+
+// touch marks the caller's session used and returns its subscriber, or nil
+// when the caller has no such session
+func (t *HTTPTransport) touch(id string, c caller) *subscriber {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sess, ok := t.sessions[id]
+	if !ok || !sess.owns(c) {
+		return nil
+	}
+	now := time.Now()
+	if sess.idle(now) {
+		t.drop(id)
+		return nil
+	}
+	sess.lastUsed = now
+	return sess.sub
+}
+
+func (t *HTTPTransport) track(id string, requestID json.RawMessage, cancel context.CancelFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sess, ok := t.sessions[id]; ok {
+		sess.inflight[string(requestID)] = cancel
+	}
+}
+
+func (t *HTTPTransport) untrack(id string, requestID json.RawMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sess, ok := t.sessions[id]; ok {
+		delete(sess.inflight, string(requestID))
+		sess.lastUsed = time.Now()
+	}
+}
+
+// cancel stops the request named by a notifications/cancelled message
+func (t *HTTPTransport) cancel(id string, params json.RawMessage) {
+	var p struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if json.Unmarshal(params, &p) != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sess, ok := t.sessions[id]; ok {
+		if cancel := sess.inflight[string(p.RequestID)]; cancel != nil {
+			cancel()
+		}
+	}
+}
+
+// accepts reports whether the request's Accept header lists the media type
+func accepts(r *http.Request, mediaType string) bool {
+	for _, v := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(v, ",") {
+			if t, _, _ := strings.Cut(strings.TrimSpace(part), ";"); strings.EqualFold(t, mediaType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		b = fmt.Appendf(nil, `{"jsonrpc":"2.0","id":null,"error":{"code":%d,"message":"failed to encode response"}}`, codeInternalError)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+const (
+	pingMessage       = `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	initializeMessage = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`
+)
+
+// httpCall is one request to the transport, made with an API key for a
+// workspace
+type httpCall struct {
+	method      string
+	workspaceID int64
+	apiKeyID    int64
+	headers     map[string]string
+	body        string
+}
+
+func serveHTTP(tr *HTTPTransport, c httpCall) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(c.method, "/mcp", strings.NewReader(c.body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "application/json, text/event-stream")
+	for k, v := range c.headers {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	tr.Serve(w, r, testWorkspace(c.workspaceID), c.apiKeyID)
+	return w
+}
+
+// initializeSession starts a session for the workspace and key
+func initializeSession(t *testing.T, tr *HTTPTransport, workspaceID, apiKeyID int64) string {
+	t.Helper()
+	w := serveHTTP(tr, httpCall{method: http.MethodPost, workspaceID: workspaceID, apiKeyID: apiKeyID, body: initializeMessage})
+	id := w.Header().Get(HeaderSessionID)
+	if w.Code != http.StatusOK || id == "" {
+		t.Fatalf("initialize = %d with session %q: %s", w.Code, id, w.Body)
+	}
+	t.Cleanup(func() {
+		serveHTTP(tr, httpCall{method: http.MethodDelete, workspaceID: workspaceID, apiKeyID: apiKeyID,
+			headers: map[string]string{HeaderSessionID: id}})
+	})
+	return id
+}
+
+func TestHTTPHeaders(t *testing.T) {
+	tr := NewHTTPTransport(zap.NewNop(), HTTPOptions{AllowedOrigins: []string{"https://app.example.com"}})
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    int
+	}{
+		{name: "no origin", method: http.MethodPost, want: http.StatusOK},
+		{name: "allowed origin", method: http.MethodPost, headers: map[string]string{"Origin": "https://app.example.com"}, want: http.StatusOK},
+		{name: "other origin", method: http.MethodPost, headers: map[string]string{"Origin": "https://evil.example.com"}, want: http.StatusForbidden},
+		{name: "null origin", method: http.MethodPost, headers: map[string]string{"Origin": "null"}, want: http.StatusForbidden},
+		{name: "supported version", method: http.MethodPost, headers: map[string]string{HeaderProtocolVersion: "2025-03-26"}, want: http.StatusOK},
+		{name: "unsupported version", method: http.MethodPost, headers: map[string]string{HeaderProtocolVersion: "2099-01-01"}, want: http.StatusBadRequest},
+		{name: "other method", method: http.MethodPut, want: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveHTTP(tr, httpCall{method: tt.method, workspaceID: 1, apiKeyID: 1, headers: tt.headers, body: initializeMessage})
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			// Rejected requests start no session
+			if id := w.Header().Get(HeaderSessionID); (id != "") != (tt.want == http.StatusOK) {
+				t.Errorf("session %q started with status %d", id, w.Code)
+			}
+			if id := w.Header().Get(HeaderSessionID); id != "" {
+				serveHTTP(tr, httpCall{method: http.MethodDelete, workspaceID: 1, apiKeyID: 1, headers: map[string]string{HeaderSessionID: id}})
+			}
+		})
+	}
+}
+
+func TestHTTPSessions(t *testing.T) {
+	tr := NewHTTPTransport(zap.NewNop(), HTTPOptions{})
+	id := initializeSession(t, tr, 1, 10)
+	session := map[string]string{HeaderSessionID: id}
+
+	tests := []struct {
+		name string
+		call httpCall
+		want int
+	}{
+		{name: "ping", call: httpCall{method: http.MethodPost, workspaceID: 1, apiKeyID: 10, headers: session, body: pingMessage}, want: http.StatusOK},
+		{name: "notification", call: httpCall{method: http.MethodPost, workspaceID: 1, apiKeyID: 10, headers: session,
+			body: `{"jsonrpc":"2.0","method":"notifications/initialized"}`}, want: http.StatusAccepted},
+		{name: "malformed", call: httpCall{method: http.MethodPost, workspaceID: 1, apiKeyID: 10, headers: session, body: `{`}, want: http.StatusBadRequest},
+		{name: "no session", call: httpCall{method: http.MethodPost, workspaceID: 1, apiKeyID: 10, body: pingMessage}, want: http.StatusBadRequest},
+		{name: "unknown session", call: httpCall{method: http.MethodPost, workspaceID: 1, apiKeyID: 10,
+			headers: map[string]string{HeaderSessionID: "0123"}, body: pingMessage}, want: http.StatusNotFound},
+
+		// The session is invisible to other workspaces and other keys
+		{name: "other workspace", call: httpCall{method: http.MethodPost, workspaceID: 2, apiKeyID: 10, headers: session, body: pingMessage}, want: http.StatusNotFound},
+		{name: "other key", call: httpCall{method: http.MethodPost, workspaceID: 1, apiKeyID: 11, headers: session, body: pingMessage}, want: http.StatusNotFound},
+		{name: "other key listening", call: httpCall{method: http.MethodGet, workspaceID: 1, apiKeyID: 11, headers: session}, want: http.StatusNotFound},
+		{name: "other key deleting", call: httpCall{method: http.MethodDelete, workspaceID: 1, apiKeyID: 11, headers: session}, want: http.StatusNotFound},
+		{name: "other workspace deleting", call: httpCall{method: http.MethodDelete, workspaceID: 2, apiKeyID: 10, headers: session}, want: http.StatusNotFound},
+
+		{name: "still usable", call: httpCall{method: http.MethodPost, workspaceID: 1, apiKeyID: 10, headers: session, body: pingMessage}, want: http.StatusOK},
+		{name: "delete", call: httpCall{method: http.MethodDelete, workspaceID: 1, apiKeyID: 10, headers: session}, want: http.StatusNoContent},
+		{name: "deleted", call: httpCall{method: http.MethodPost, workspaceID: 1, apiKeyID: 10, headers: session, body: pingMessage}, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := serveHTTP(tr, tt.call); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+func TestHTTPToolStream(t *testing.T) {
+	addWaitTool(t)
+	tr := NewHTTPTransport(zap.NewNop(), HTTPOptions{})
+	id := initializeSession(t, tr, 1, 10)
+
+	// Clients accepting event streams get the progress, then the result
+	w := serveHTTP(tr, httpCall{method: http.MethodPost, workspaceID: 1, apiKeyID: 10, headers: map[string]string{HeaderSessionID: id},
+		body: `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"wait","_meta":{"progressToken":"p"}}}`})
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q: %s", ct, w.Body)
+	}
+	body := w.Body.String()
+	progress := strings.Index(body, `"method":"notifications/progress"`)
+	result := strings.Index(body, `"id":5,"result"`)
+	if progress < 0 || result < progress {
+		t.Errorf("stream doesn't carry progress then the result:\n%s", body)
+	}
+
+	// Others get one JSON response
+	w = serveHTTP(tr, httpCall{method: http.MethodPost, workspaceID: 1, apiKeyID: 10,
+		headers: map[string]string{HeaderSessionID: id, "Accept": "application/json"},
+		body:    `{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"wait","_meta":{"progressToken":"p"}}}`})
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" || !strings.Contains(w.Body.String(), `"id":6,"result"`) {
+		t.Errorf("response = %q %s", ct, w.Body)
+	}
+}
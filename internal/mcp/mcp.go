@@ -0,0 +1,256 @@
+// Package mcp serves the Model Context Protocol, letting AI assistants search
+// and read the indexed code of one workspace
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime/debug"
+	"slices"
+
+	"github.com/gomantics/semantix/internal/domains/workspaces"
+	"go.uber.org/zap"
+)
+
+// ProtocolVersion is the newest protocol revision the server speaks
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions are the revisions offered back to clients, newest first
+var supportedVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// request is a JSON-RPC request, or a notification when ID is absent
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+func (r *request) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// nullID answers messages whose ID couldn't be read
+var nullID = json.RawMessage("null")
+
+// notification is a JSON-RPC message sent without expecting a response
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// notifier sends a notification to the client while a request is handled.
+// Transports put one on the request's context.
+type notifier func(n notification)
+
+type notifierKey struct{}
+
+func withNotifier(ctx context.Context, n notifier) context.Context {
+	return context.WithValue(ctx, notifierKey{}, n)
+}
+
+type progressKey struct{}
+
+// reportProgress sends notifications/progress when the client asked for
+// progress on the request being handled. progress must increase with each
+// call.
+func reportProgress(ctx context.Context, progress, total float64, message string) {
+	n, _ := ctx.Value(notifierKey{}).(notifier)
+	token, _ := ctx.Value(progressKey{}).(json.RawMessage)
+	if n == nil || token == nil {
+		return
+	}
+	n(notification{JSONRPC: "2.0", Method: "notifications/progress", Params: map[string]any{
+		"progressToken": token,
+		"progress":      progress,
+		"total":         total,
+		"message":       message,
+	}})
+}
+
+// Server answers MCP requests for one workspace. It holds no per-session
+// state, so one Server can serve any number of sessions; transports keep
+// each session's subscriptions.
+type Server struct {
+	l         *zap.Logger
+	workspace *workspaces.Workspace
+}
+
+func NewServer(l *zap.Logger, workspace *workspaces.Workspace) *Server {
+	return &Server{
+		l:         l.With(zap.Int64("workspace_id", workspace.ID)),
+		workspace: workspace,
+	}
+}
+
+// Handle processes one JSON-RPC message and returns the encoded response,
+// or nil when the message was a notification
+func (s *Server) Handle(ctx context.Context, msg []byte) []byte {
+	req, resp := parse(msg)
+	if resp == nil {
+		resp = s.handle(ctx, req)
+	}
+	if resp == nil {
+		return nil
+	}
+	return s.encode(resp)
+}
+
+func (s *Server) encode(resp *response) []byte {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		s.l.Error("failed to encode response", zap.Error(err))
+		b, _ = json.Marshal(errorResponse(resp.ID, codeInternalError, "failed to encode response"))
+	}
+	return b
+}
+
+// parse decodes a message, returning the error response for malformed ones
+func parse(msg []byte) (*request, *response) {
+	var req request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, errorResponse(nullID, codeParseError, "parse error")
+		}
+		// Batches were removed from the protocol in 2025-06-18
+		return nil, errorResponse(nullID, codeInvalidRequest, "invalid request")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		id := req.ID
+		if len(id) == 0 {
+			id = nullID
+		}
+		return nil, errorResponse(id, codeInvalidRequest, "invalid request")
+	}
+	return &req, nil
+}
+
+// handle dispatches a request; notifications get no response
+func (s *Server) handle(ctx context.Context, req *request) *response {
+	if req.isNotification() {
+		// initialized and cancellation need no answer here; transports
+		// act on cancellation themselves
+		return nil
+	}
+
+	var (
+		result any
+		err    error
+	)
+	switch req.Method {
+	case "initialize":
+		result, err = s.initialize(req.Params)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = map[string]any{"tools": tools}
+	case "tools/call":
+		result, err = s.callTool(ctx, req.Params)
+	case "resources/list":
+		result, err = s.listResources(ctx, req.Params)
+	case "resources/templates/list":
+		result = s.resourceTemplates()
+	case "resources/read":
+		result, err = s.readResource(ctx, req.Params)
+	case "resources/subscribe":
+		result, err = s.subscribe(ctx, req.Params, true)
+	case "resources/unsubscribe":
+		result, err = s.subscribe(ctx, req.Params, false)
+	case "prompts/list":
+		result = map[string]any{"prompts": prompts}
+	case "prompts/get":
+		result, err = s.getPrompt(ctx, req.Params)
+	default:
+		err = &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			s.l.Error("failed to handle request", zap.String("method", req.Method), zap.Error(err))
+			rpcErr = &rpcError{Code: codeInternalError, Message: "internal error"}
+		}
+		return errorResponse(req.ID, rpcErr.Code, rpcErr.Message)
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
+
+type initializeResult struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ServerInfo      serverInfo     `json:"serverInfo"`
+	Instructions    string         `json:"instructions"`
+}
+
+type serverInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// initialize agrees on a protocol version: the client's when supported,
+// otherwise the newest the server speaks
+func (s *Server) initialize(params json.RawMessage) (*initializeResult, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "invalid initialize params"}
+	}
+	version := ProtocolVersion
+	if slices.Contains(supportedVersions, p.ProtocolVersion) {
+		version = p.ProtocolVersion
+	}
+
+	return &initializeResult{
+		ProtocolVersion: version,
+		Capabilities: map[string]any{
+			"tools":     map[string]any{"listChanged": false},
+			"resources": map[string]any{"subscribe": true, "listChanged": false},
+			"prompts":   map[string]any{"listChanged": false},
+		},
+		ServerInfo: serverInfo{Name: "semantix", Version: serverVersion()},
+		Instructions: "Tools search and read the indexed repositories of the " + s.workspace.Name +
+			" workspace. Start with get_context or search, then use get_file to read more of a file. " +
+			"Repositories and files are also resources, updated when their repository is re-indexed.",
+	}, nil
+}
+
+// serverVersion is the module version the binary was built from
+func serverVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
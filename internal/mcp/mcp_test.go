@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/gomantics/semantix/internal/domains/workspaces"
+	"go.uber.org/zap"
+)
+
+func testWorkspace(id int64) *workspaces.Workspace {
+	return &workspaces.Workspace{ID: id, Name: "Test", Slug: "test"}
+}
+
+// addWaitTool adds a tool that needs no database for the duration of the
+// test. It reports progress, then returns at once or, given {"wait": true},
+// when its request is cancelled.
+func addWaitTool(t *testing.T) {
+	t.Helper()
+	saved := tools
+	tools = append(slices.Clone(tools), &tool{
+		Name: "wait",
+		call: func(s *Server, ctx context.Context, raw json.RawMessage) *toolResult {
+			var args struct {
+				Wait bool `json:"wait"`
+			}
+			if res := decodeArgs(raw, &args); res != nil {
+				return res
+			}
+			reportProgress(ctx, 0, 1, "waiting")
+			if args.Wait {
+				<-ctx.Done()
+			}
+			return textResult("done", nil)
+		},
+	})
+	t.Cleanup(func() { tools = saved })
+}
+
+// testResponse is a response with its result left encoded
+type testResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func decodeResponse(t *testing.T, b []byte) testResponse {
+	t.Helper()
+	var resp testResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", b, err)
+	}
+	return resp
+}
+
+func TestHandle(t *testing.T) {
+	addWaitTool(t)
+	s := NewServer(zap.NewNop(), testWorkspace(1))
+
+	tests := []struct {
+		name string
+		msg  string
+		id   string
+		// code is the expected error code, or 0 for a result
+		code int
+	}{
+		{name: "parse error", msg: `{"jsonrpc":`, id: "null", code: codeParseError},
+		{name: "batch", msg: `[{"jsonrpc":"2.0","id":1,"method":"ping"}]`, id: "null", code: codeInvalidRequest},
+		{name: "wrong version", msg: `{"jsonrpc":"1.0","id":1,"method":"ping"}`, id: "1", code: codeInvalidRequest},
+		{name: "no method", msg: `{"jsonrpc":"2.0","id":"a"}`, id: `"a"`, code: codeInvalidRequest},
+		{name: "unknown method", msg: `{"jsonrpc":"2.0","id":2,"method":"tools/run"}`, id: "2", code: codeMethodNotFound},
+		{name: "ping", msg: `{"jsonrpc":"2.0","id":3,"method":"ping"}`, id: "3"},
+		{name: "invalid initialize params", msg: `{"jsonrpc":"2.0","id":4,"method":"initialize","params":[]}`, id: "4", code: codeInvalidParams},
+		{name: "invalid tools/call params", msg: `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":"search"}`, id: "5", code: codeInvalidParams},
+		{name: "unknown tool", msg: `{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"grep"}}`, id: "6", code: codeInvalidParams},
+		{name: "tool call", msg: `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"wait"}}`, id: "7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := decodeResponse(t, s.Handle(context.Background(), []byte(tt.msg)))
+			if string(resp.ID) != tt.id {
+				t.Errorf("id = %s, want %s", resp.ID, tt.id)
+			}
+			switch {
+			case tt.code == 0 && resp.Error != nil:
+				t.Errorf("error = %v, want a result", resp.Error)
+			case tt.code != 0 && (resp.Error == nil || resp.Error.Code != tt.code):
+				t.Errorf("error = %v, want code %d", resp.Error, tt.code)
+			}
+		})
+	}
+
+	// Notifications, known or not, get no response
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","method":"notifications/unknown"}`,
+	} {
+		if b := s.Handle(context.Background(), []byte(msg)); b != nil {
+			t.Errorf("notification %s answered with %s", msg, b)
+		}
+	}
+}
+
+func TestInitialize(t *testing.T) {
+	s := NewServer(zap.NewNop(), testWorkspace(1))
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: ProtocolVersion, want: ProtocolVersion},
+		{version: "2024-11-05", want: "2024-11-05"},
+		{version: "2099-01-01", want: ProtocolVersion},
+		{version: "", want: ProtocolVersion},
+	}
+	for _, tt := range tests {
+		msg := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"` + tt.version + `"}}`
+		resp := decodeResponse(t, s.Handle(context.Background(), []byte(msg)))
+		var result initializeResult
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.ProtocolVersion != tt.want {
+			t.Errorf("initialize(%q) agreed on %q, want %q", tt.version, result.ProtocolVersion, tt.want)
+		}
+	}
+}
+
+func TestToolArguments(t *testing.T) {
+	s := NewServer(zap.NewNop(), testWorkspace(1))
+
+	// Arguments are checked before any repository is touched
+	tests := []struct {
+		name string
+		args string
+	}{
+		{name: "search", args: `{"query":"webhooks","limt":5}`},
+		{name: "search", args: `{"query":"webhooks","limit":500}`},
+		{name: "search", args: `{"query":["webhooks"]}`},
+		{name: "get_file", args: `{"repo_id":"1","path":"main.go"}`},
+		{name: "get_file", args: `{"path":"main.go"}`},
+		{name: "get_file", args: `{"repo_id":1,"path":"main.go","line":3}`},
+		{name: "list_repos", args: `{"workspace_id":2}`},
+		{name: "get_context", args: `{"query":"webhooks","context_lines":51}`},
+		{name: "get_context", args: `{"query":"webhooks","repo":"api"}`},
+	}
+	for _, tt := range tests {
+		params, _ := json.Marshal(map[string]any{"name": tt.name, "arguments": json.RawMessage(tt.args)})
+		msg, _ := json.Marshal(request{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: "tools/call", Params: params})
+		resp := decodeResponse(t, s.Handle(context.Background(), msg))
+
+		var result struct {
+			IsError           bool                 `json:"isError"`
+			StructuredContent map[string]toolError `json:"structuredContent"`
+		}
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			t.Fatalf("%s(%s): %v", tt.name, tt.args, err)
+		}
+		if code := result.StructuredContent["error"].Code; !result.IsError || code != errInvalidArguments {
+			t.Errorf("%s(%s) = error %v with code %q, want %q", tt.name, tt.args, result.IsError, code, errInvalidArguments)
+		}
+	}
+}
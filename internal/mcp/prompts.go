@@ -0,0 +1,278 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gomantics/semantix/internal/domains/files"
+	"github.com/gomantics/semantix/internal/domains/grep"
+	"github.com/gomantics/semantix/internal/domains/repos"
+)
+
+const (
+	// maxModuleFiles bounds the files listed by explain_module
+	maxModuleFiles = 200
+	// maxUsages bounds the matching lines included by find_usages
+	maxUsages = 100
+)
+
+// prompt is an MCP prompt definition and its handler
+type prompt struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Arguments   []promptArgument `json:"arguments"`
+
+	get func(s *Server, ctx context.Context, args map[string]string) (*promptResult, error)
+}
+
+type promptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+type promptResult struct {
+	Description string          `json:"description"`
+	Messages    []promptMessage `json:"messages"`
+}
+
+// promptMessage content is a textContent or an embeddedResource
+type promptMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type embeddedResource struct {
+	Type     string           `json:"type"`
+	Resource resourceContents `json:"resource"`
+}
+
+func userText(text string) promptMessage {
+	return promptMessage{Role: "user", Content: textContent{Type: "text", Text: text}}
+}
+
+// prompts are listed in this order by prompts/list
+var prompts = []*prompt{
+	{
+		Name:  "explain_module",
+		Title: "Explain this module",
+		Description: "Explain what a file or directory of a repository does, " +
+			"with its code attached so the explanation needs no further reads.",
+		Arguments: []promptArgument{
+			{Name: "repo_id", Description: "Repository ID from list_repos", Required: true},
+			{Name: "path", Description: "File or directory relative to the repository root; empty for the whole repository"},
+		},
+		get: (*Server).explainModule,
+	},
+	{
+		Name:        "find_usages",
+		Title:       "Find usages",
+		Description: "Find where a function, type or other identifier is used, starting from every line that mentions it.",
+		Arguments: []promptArgument{
+			{Name: "symbol", Description: "Identifier to look for, e.g. ParseURL", Required: true},
+			{Name: "repo_id", Description: "Only look in this repository (ID from list_repos)"},
+		},
+		get: (*Server).findUsages,
+	},
+}
+
+func (s *Server) getPrompt(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "invalid prompts/get params"}
+	}
+	for _, pr := range prompts {
+		if pr.Name != p.Name {
+			continue
+		}
+		for _, arg := range pr.Arguments {
+			if arg.Required && strings.TrimSpace(p.Arguments[arg.Name]) == "" {
+				return nil, &rpcError{Code: codeInvalidParams, Message: arg.Name + " is required"}
+			}
+		}
+		return pr.get(s, ctx, p.Arguments)
+	}
+	return nil, &rpcError{Code: codeInvalidParams, Message: "unknown prompt: " + p.Name}
+}
+
+// promptRepo gets the repository named by a prompt's repo_id argument
+func (s *Server) promptRepo(ctx context.Context, arg string) (*repos.Repo, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(arg), 10, 64)
+	if err != nil || id <= 0 {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "repo_id must be a repository ID"}
+	}
+	repo, err := repos.Get(ctx, s.workspace.ID, id)
+	if errors.Is(err, repos.ErrNotFound) {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return repo, err
+}
+
+// explainModule attaches a file, or a directory's files in path order until
+// they'd pass maxContextBytes, to a request for an explanation
+func (s *Server) explainModule(ctx context.Context, args map[string]string) (*promptResult, error) {
+	repo, err := s.promptRepo(ctx, args["repo_id"])
+	if err != nil {
+		return nil, err
+	}
+	name := repo.Owner + "/" + repo.Name
+	dir := strings.Trim(strings.TrimSpace(args["path"]), "/")
+	if dir == "." {
+		dir = ""
+	}
+
+	if dir != "" {
+		content, err := files.Read(ctx, repo, files.ReadParams{Path: dir})
+		switch {
+		case err == nil:
+			return &promptResult{
+				Description: "Explain " + name + ":" + content.Path,
+				Messages: []promptMessage{
+					userText(fmt.Sprintf("Explain what %s in the %s repository does: its purpose, "+
+						"its main types and functions, and how the rest of the code uses it. "+
+						"The file is attached.", content.Path, name)),
+					s.embed(content),
+				},
+			}, nil
+		case errors.Is(err, files.ErrNotAFile):
+		case readErrorCode(err) != "":
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		default:
+			return nil, err
+		}
+	}
+
+	indexed, err := files.ListByRepo(ctx, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, f := range indexed {
+		if dir == "" || strings.HasPrefix(f.Path, dir+"/") {
+			paths = append(paths, f.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "no indexed files under " + dir}
+	}
+	slices.Sort(paths)
+
+	module := dir
+	if module == "" {
+		module = "the repository root"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Explain the module at %s in the %s repository: what it's responsible for, "+
+		"how its files fit together, its main types and functions, and how the rest of the code uses it.\n\n"+
+		"Its indexed files:\n", module, name)
+	for i, p := range paths {
+		if i == maxModuleFiles {
+			fmt.Fprintf(&b, "... and %d more\n", len(paths)-i)
+			break
+		}
+		fmt.Fprintf(&b, "- %s\n", p)
+	}
+
+	var (
+		attached []promptMessage
+		size     int
+	)
+	for _, p := range paths[:min(len(paths), maxModuleFiles)] {
+		content, err := files.Read(ctx, repo, files.ReadParams{Path: p})
+		if err != nil {
+			// Changed since indexing, or not text; the listing still names it
+			if readErrorCode(err) != "" {
+				continue
+			}
+			return nil, err
+		}
+		if size+len(content.Content) > maxContextBytes {
+			break
+		}
+		size += len(content.Content)
+		attached = append(attached, s.embed(content))
+	}
+	if len(attached) < len(paths) {
+		fmt.Fprintf(&b, "\n%d of the files are attached; read the others with get_file (repo_id=%d) when needed.\n",
+			len(attached), repo.ID)
+	}
+
+	return &promptResult{
+		Description: "Explain " + name + ":" + module,
+		Messages:    append([]promptMessage{userText(b.String())}, attached...),
+	}, nil
+}
+
+// embed attaches a file's content as its resource
+func (s *Server) embed(content *files.Content) promptMessage {
+	return promptMessage{Role: "user", Content: embeddedResource{
+		Type: "resource",
+		Resource: resourceContents{
+			URI:      s.fileURI(content.RepoID, content.Path),
+			MimeType: "text/plain",
+			Text:     content.Content,
+		},
+	}}
+}
+
+// findUsages greps for the symbol as a whole word and asks for the matches
+// to be sorted into its definition and its uses
+func (s *Server) findUsages(ctx context.Context, args map[string]string) (*promptResult, error) {
+	symbol := strings.TrimSpace(args["symbol"])
+	params := grep.Params{
+		WorkspaceID: s.workspace.ID,
+		Pattern:     `\b` + regexp.QuoteMeta(symbol) + `\b`,
+		Mode:        grep.ModeRegex,
+		MaxMatches:  maxUsages,
+	}
+	if args["repo_id"] != "" {
+		repo, err := s.promptRepo(ctx, args["repo_id"])
+		if err != nil {
+			return nil, err
+		}
+		params.RepoIDs = []int64{repo.ID}
+	}
+
+	result, err := grep.Grep(ctx, params)
+	if err != nil {
+		if errors.Is(err, grep.ErrPatternTooLong) || errors.Is(err, grep.ErrInvalidPattern) {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return nil, err
+	}
+	names, err := s.repoNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Find the usages of `%s`. Tell its definition apart from its uses, group the uses by "+
+		"the code calling them, and summarise how it's used. Read surrounding code with get_file or "+
+		"get_context when a line alone isn't enough.\n\n", symbol)
+	if len(result.Matches) == 0 {
+		b.WriteString("No indexed file mentions it; it may be spelled differently or live outside the indexed repositories.\n")
+	} else {
+		b.WriteString("Lines mentioning it:\n")
+		for _, m := range result.Matches {
+			fmt.Fprintf(&b, "%s:%s:%d: %s\n", names[m.RepoID], m.FilePath, m.LineNumber, strings.TrimSpace(m.Line))
+		}
+		if result.Truncated != "" {
+			fmt.Fprintf(&b, "\nThe search stopped early (%s limit); there are more mentions than listed.\n", result.Truncated)
+		}
+	}
+
+	return &promptResult{
+		Description: "Find usages of " + symbol,
+		Messages:    []promptMessage{userText(b.String())},
+	}, nil
+}
@@ -0,0 +1,265 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gomantics/semantix/internal/domains/files"
+	"github.com/gomantics/semantix/internal/domains/repos"
+)
+
+// URIScheme prefixes resource URIs: semantix://{workspace}/{repo_id} names a
+// repository and semantix://{workspace}/{repo_id}/{path} one of its files
+const URIScheme = "semantix://"
+
+const (
+	// resourcePageSize bounds the files listed per resources/list page
+	resourcePageSize = 500
+
+	// codeResourceNotFound is the JSON-RPC error code for unknown resources
+	codeResourceNotFound = -32002
+)
+
+type resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+type resourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	MimeType    string `json:"mimeType"`
+}
+
+// resourceContents is the text of a resource as returned by resources/read
+type resourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// repoURI returns the URI of a repository in the server's workspace
+func (s *Server) repoURI(repoID int64) string {
+	return URIScheme + url.PathEscape(s.workspace.Slug) + "/" + strconv.FormatInt(repoID, 10)
+}
+
+// fileURI returns the URI of a file, escaping each path segment
+func (s *Server) fileURI(repoID int64, path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return s.repoURI(repoID) + "/" + strings.Join(segments, "/")
+}
+
+// parseURI splits a resource URI of the server's workspace into its
+// repository ID and file path; path is empty for repository URIs
+func (s *Server) parseURI(uri string) (repoID int64, path string, err error) {
+	invalid := &rpcError{Code: codeInvalidParams, Message: "invalid resource URI: " + uri}
+
+	rest, ok := strings.CutPrefix(uri, URIScheme)
+	if !ok {
+		return 0, "", invalid
+	}
+	slug, rest, _ := strings.Cut(rest, "/")
+	if slug != url.PathEscape(s.workspace.Slug) {
+		return 0, "", &rpcError{Code: codeResourceNotFound, Message: "resource is not in this workspace: " + uri}
+	}
+	id, escaped, _ := strings.Cut(rest, "/")
+	repoID, err = strconv.ParseInt(id, 10, 64)
+	if err != nil || repoID <= 0 {
+		return 0, "", invalid
+	}
+	if escaped == "" {
+		return repoID, "", nil
+	}
+	path, err = url.PathUnescape(escaped)
+	if err != nil {
+		return 0, "", invalid
+	}
+	return repoID, path, nil
+}
+
+func (s *Server) resourceTemplates() map[string]any {
+	prefix := URIScheme + url.PathEscape(s.workspace.Slug)
+	return map[string]any{"resourceTemplates": []resourceTemplate{
+		{
+			URITemplate: prefix + "/{repo_id}",
+			Name:        "repository",
+			Title:       "Repository",
+			Description: "An indexed repository's metadata and indexing status",
+			MimeType:    "application/json",
+		},
+		{
+			URITemplate: prefix + "/{repo_id}/{+path}",
+			Name:        "file",
+			Title:       "Repository file",
+			Description: "A file of an indexed repository at its indexed commit",
+			MimeType:    "text/plain",
+		},
+	}}
+}
+
+// listResources returns the workspace's repositories on the first page, then
+// its indexed files a page at a time. The cursor is the last file ID listed.
+func (s *Server) listResources(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Cursor string `json:"cursor"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid resources/list params"}
+		}
+	}
+	var afterID int64
+	if p.Cursor != "" {
+		id, err := strconv.ParseInt(p.Cursor, 10, 64)
+		if err != nil || id < 0 {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid cursor"}
+		}
+		afterID = id
+	}
+
+	all, err := s.repos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(all))
+	resources := []resource{}
+	for _, r := range all {
+		name := r.Owner + "/" + r.Name
+		names[r.ID] = name
+		if p.Cursor != "" {
+			continue
+		}
+		resources = append(resources, resource{
+			URI:         s.repoURI(r.ID),
+			Name:        name,
+			Title:       name,
+			Description: fmt.Sprintf("Repository %s: %s, %d files indexed", name, r.Status, r.FileCount),
+			MimeType:    "application/json",
+		})
+	}
+
+	page, err := files.ListByWorkspace(ctx, files.ListParams{
+		WorkspaceID: s.workspace.ID,
+		AfterID:     afterID,
+		Limit:       resourcePageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range page {
+		resources = append(resources, resource{
+			URI:      s.fileURI(f.RepoID, f.Path),
+			Name:     f.Path,
+			Title:    names[f.RepoID] + ": " + f.Path,
+			MimeType: "text/plain",
+			Size:     f.SizeBytes,
+		})
+	}
+
+	result := map[string]any{"resources": resources}
+	if len(page) == resourcePageSize {
+		result["nextCursor"] = strconv.FormatInt(page[len(page)-1].ID, 10)
+	}
+	return result, nil
+}
+
+func (s *Server) readResource(ctx context.Context, params json.RawMessage) (any, error) {
+	uri, err := uriParam(params)
+	if err != nil {
+		return nil, err
+	}
+	repoID, path, err := s.parseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := s.resourceRepo(ctx, uri, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		b, err := json.MarshalIndent(repo, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"contents": []resourceContents{
+			{URI: uri, MimeType: "application/json", Text: string(b)},
+		}}, nil
+	}
+
+	content, err := files.Read(ctx, repo, files.ReadParams{Path: path})
+	if err != nil {
+		switch readErrorCode(err) {
+		case errNotFound:
+			return nil, &rpcError{Code: codeResourceNotFound, Message: "resource not found: " + uri}
+		case "":
+			return nil, err
+		}
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return map[string]any{"contents": []resourceContents{
+		{URI: uri, MimeType: "text/plain", Text: content.Content},
+	}}, nil
+}
+
+// subscribe records the client's interest in a resource; it's told when the
+// resource's repository finishes re-indexing
+func (s *Server) subscribe(ctx context.Context, params json.RawMessage, on bool) (any, error) {
+	sub, _ := ctx.Value(subscriberKey{}).(*subscriber)
+	if sub == nil {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "subscriptions need a session"}
+	}
+	uri, err := uriParam(params)
+	if err != nil {
+		return nil, err
+	}
+	repoID, _, err := s.parseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if !on {
+		sub.unsubscribe(uri)
+		return struct{}{}, nil
+	}
+	if _, err := s.resourceRepo(ctx, uri, repoID); err != nil {
+		return nil, err
+	}
+	if err := sub.subscribe(uri, repoID); err != nil {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: err.Error()}
+	}
+	return struct{}{}, nil
+}
+
+// resourceRepo gets the repository a resource URI names
+func (s *Server) resourceRepo(ctx context.Context, uri string, repoID int64) (*repos.Repo, error) {
+	repo, err := repos.Get(ctx, s.workspace.ID, repoID)
+	if errors.Is(err, repos.ErrNotFound) {
+		return nil, &rpcError{Code: codeResourceNotFound, Message: "resource not found: " + uri}
+	}
+	return repo, err
+}
+
+func uriParam(params json.RawMessage) (string, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil || p.URI == "" {
+		return "", &rpcError{Code: codeInvalidParams, Message: "uri is required"}
+	}
+	return p.URI, nil
+}
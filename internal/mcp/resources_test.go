@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gomantics/semantix/internal/domains/repos"
+	"go.uber.org/zap"
+)
+
+func TestResourceURI(t *testing.T) {
+	s := NewServer(zap.NewNop(), testWorkspace(1))
+
+	for _, path := range []string{"main.go", "internal/mcp/http.go", "docs/a b#1?.md", "100%/ünïcode.txt"} {
+		uri := s.fileURI(7, path)
+		repoID, got, err := s.parseURI(uri)
+		if err != nil || repoID != 7 || got != path {
+			t.Errorf("parseURI(%q) = %d, %q, %v, want 7, %q", uri, repoID, got, err, path)
+		}
+	}
+	if repoID, path, err := s.parseURI(s.repoURI(7)); err != nil || repoID != 7 || path != "" {
+		t.Errorf("parseURI(%q) = %d, %q, %v", s.repoURI(7), repoID, path, err)
+	}
+
+	tests := []struct {
+		uri  string
+		code int
+	}{
+		{uri: "file:///etc/passwd", code: codeInvalidParams},
+		{uri: URIScheme + "test", code: codeInvalidParams},
+		{uri: URIScheme + "test/0", code: codeInvalidParams},
+		{uri: URIScheme + "test/x/main.go", code: codeInvalidParams},
+		{uri: URIScheme + "test/7/%zz", code: codeInvalidParams},
+		{uri: URIScheme + "other/7/main.go", code: codeResourceNotFound},
+	}
+	for _, tt := range tests {
+		_, _, err := s.parseURI(tt.uri)
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) || rpcErr.Code != tt.code {
+			t.Errorf("parseURI(%q) = %v, want code %d", tt.uri, err, tt.code)
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	var got []string
+	record := func(prefix string) notifier {
+		return func(n notification) {
+			got = append(got, prefix+n.Params.(map[string]string)["uri"])
+		}
+	}
+
+	a := newSubscriber(1, record("a "))
+	b := newSubscriber(2, record("b "))
+	detached := newSubscriber(1, nil)
+	for _, sub := range []*subscriber{a, b, detached} {
+		register(sub)
+		t.Cleanup(func() { unregister(sub) })
+	}
+	_ = a.subscribe("semantix://one/7", 7)
+	_ = a.subscribe("semantix://one/8/main.go", 8)
+	_ = a.subscribe("semantix://one/7/gone.go", 7)
+	a.unsubscribe("semantix://one/7/gone.go")
+	_ = b.subscribe("semantix://two/7", 7)
+	_ = detached.subscribe("semantix://one/7", 7)
+
+	// Only subscribers in the event's workspace hear of it, and only for
+	// the repository's resources
+	publish(repos.IndexedEvent{WorkspaceID: 1, RepoID: 7})
+	if want := []string{"a semantix://one/7"}; !slices.Equal(got, want) {
+		t.Errorf("notified %q, want %q", got, want)
+	}
+
+	// A detached stream hears nothing until it attaches
+	got = nil
+	a.detach()
+	publish(repos.IndexedEvent{WorkspaceID: 1, RepoID: 8})
+	if len(got) != 0 {
+		t.Errorf("detached subscriber notified %q", got)
+	}
+	if !a.attach(record("a ")) || a.attach(record("again ")) {
+		t.Error("attach should succeed once")
+	}
+	publish(repos.IndexedEvent{WorkspaceID: 1, RepoID: 8})
+	if want := []string{"a semantix://one/8/main.go"}; !slices.Equal(got, want) {
+		t.Errorf("notified %q, want %q", got, want)
+	}
+}
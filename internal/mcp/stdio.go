@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/gomantics/semantix/internal/domains/workspaces"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// MaxMessageBytes bounds one incoming message
+const MaxMessageBytes = 4 << 20
+
+// RunStdio serves the workspace named by ref (slug or ID) over the process's
+// stdin and stdout, stopping the app once the client closes stdin. Logs must
+// go to stderr, which zap does by default.
+func RunStdio(lc fx.Lifecycle, l *zap.Logger, sd fx.Shutdowner, ref string) error {
+	if ref == "" {
+		return errors.New("no workspace configured; set mcp.workspace or pass --workspace")
+	}
+	ws, err := workspaces.Resolve(context.Background(), ref)
+	if err != nil {
+		if errors.Is(err, workspaces.ErrNotFound) {
+			return fmt.Errorf("workspace %q not found", ref)
+		}
+		return err
+	}
+
+	s := NewServer(l, ws)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go Watch(ctx, l)
+			go func() {
+				l.Info("serving MCP over stdio", zap.String("workspace", ws.Slug))
+				if err := s.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil {
+					l.Error("stdio transport failed", zap.Error(err))
+				}
+				l.Info("client disconnected")
+				if err := sd.Shutdown(); err != nil {
+					l.Error("failed to shut down", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			// Stdin can't be interrupted; cancelling stops in-flight calls
+			cancel()
+			return nil
+		},
+	})
+
+	return nil
+}
+
+// ServeStdio reads newline-delimited JSON-RPC messages from r and writes the
+// responses to w until r ends. Requests run concurrently, so a slow search
+// doesn't hold up a ping, and notifications/cancelled stops one in flight.
+// Resource updates are written as they happen while Watch runs.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	var (
+		mu       sync.Mutex // guards w and inflight
+		inflight = make(map[string]context.CancelFunc)
+		wg       sync.WaitGroup
+	)
+	write := func(b []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := w.Write(append(b, '\n')); err != nil {
+			s.l.Error("failed to write response", zap.Error(err))
+		}
+	}
+	defer wg.Wait()
+
+	notify := func(n notification) {
+		b, err := json.Marshal(n)
+		if err != nil {
+			s.l.Error("failed to encode notification", zap.Error(err))
+			return
+		}
+		write(b)
+	}
+	sub := newSubscriber(s.workspace.ID, notify)
+	register(sub)
+	defer unregister(sub)
+	ctx = withSubscriber(withNotifier(ctx, notify), sub)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), MaxMessageBytes)
+	for scanner.Scan() {
+		msg := bytes.TrimSpace(scanner.Bytes())
+		if len(msg) == 0 {
+			continue
+		}
+
+		req, resp := parse(msg)
+		if resp != nil {
+			write(s.encode(resp))
+			continue
+		}
+
+		if req.Method == "notifications/cancelled" {
+			var p struct {
+				RequestID json.RawMessage `json:"requestId"`
+			}
+			if json.Unmarshal(req.Params, &p) == nil {
+				mu.Lock()
+				if cancel := inflight[string(p.RequestID)]; cancel != nil {
+					cancel()
+				}
+				mu.Unlock()
+			}
+			continue
+		}
+
+		reqCtx, cancel := context.WithCancel(ctx)
+		id := string(req.ID)
+		if !req.isNotification() {
+			mu.Lock()
+			inflight[id] = cancel
+			mu.Unlock()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer cancel()
+
+			resp := s.handle(reqCtx, req)
+			if !req.isNotification() {
+				mu.Lock()
+				delete(inflight, id)
+				mu.Unlock()
+			}
+			// Cancelled requests get no response
+			if resp == nil || reqCtx.Err() != nil {
+				return
+			}
+			write(s.encode(resp))
+		}()
+	}
+	return scanner.Err()
+}
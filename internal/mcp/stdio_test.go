@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// stdioClient drives ServeStdio over pipes
+type stdioClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *bufio.Scanner
+	served chan error
+}
+
+func newStdioClient(t *testing.T, s *Server) *stdioClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &stdioClient{t: t, in: inW, out: bufio.NewScanner(outR), served: make(chan error, 1)}
+	go func() {
+		err := s.ServeStdio(context.Background(), inR, outW)
+		outW.Close()
+		c.served <- err
+	}()
+	return c
+}
+
+func (c *stdioClient) send(msg string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, msg+"\n"); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// next reads the next message the server wrote
+func (c *stdioClient) next() map[string]json.RawMessage {
+	c.t.Helper()
+	if !c.out.Scan() {
+		c.t.Fatalf("server stopped writing: %v", c.out.Err())
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(c.out.Bytes(), &msg); err != nil {
+		c.t.Fatalf("invalid message %s: %v", c.out.Bytes(), err)
+	}
+	return msg
+}
+
+// close ends the input and returns the messages written after it, once the
+// server has stopped
+func (c *stdioClient) close() []map[string]json.RawMessage {
+	c.t.Helper()
+	c.in.Close()
+	var rest []map[string]json.RawMessage
+	for c.out.Scan() {
+		var msg map[string]json.RawMessage
+		if err := json.Unmarshal(c.out.Bytes(), &msg); err != nil {
+			c.t.Fatalf("invalid message %s: %v", c.out.Bytes(), err)
+		}
+		rest = append(rest, msg)
+	}
+	if err := <-c.served; err != nil {
+		c.t.Errorf("ServeStdio = %v", err)
+	}
+	return rest
+}
+
+func TestServeStdio(t *testing.T) {
+	c := newStdioClient(t, NewServer(zap.NewNop(), testWorkspace(1)))
+
+	// Blank lines are skipped and malformed ones answered in order
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`)
+	if msg := c.next(); string(msg["id"]) != "1" || msg["result"] == nil {
+		t.Errorf("initialize response = %v", msg)
+	}
+	c.send(``)
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	c.send(`not json`)
+	if msg := c.next(); string(msg["id"]) != "null" || msg["error"] == nil {
+		t.Errorf("parse error response = %v", msg)
+	}
+	c.send(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	var list struct {
+		Tools []tool `json:"tools"`
+	}
+	if msg := c.next(); json.Unmarshal(msg["result"], &list) != nil || len(list.Tools) != len(tools) {
+		t.Errorf("tools/list response = %v", msg)
+	}
+
+	if rest := c.close(); len(rest) != 0 {
+		t.Errorf("unexpected messages %v", rest)
+	}
+}
+
+func TestServeStdioCancel(t *testing.T) {
+	addWaitTool(t)
+	c := newStdioClient(t, NewServer(zap.NewNop(), testWorkspace(1)))
+
+	// The tool's progress shows it's running; a ping is answered meanwhile
+	c.send(`{"jsonrpc":"2.0","id":"slow","method":"tools/call","params":{"name":"wait","arguments":{"wait":true},"_meta":{"progressToken":7}}}`)
+	msg := c.next()
+	if string(msg["method"]) != `"notifications/progress"` {
+		t.Fatalf("first message = %v, want progress", msg)
+	}
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	if msg := c.next(); string(msg["id"]) != "1" {
+		t.Errorf("ping response = %v", msg)
+	}
+
+	// Cancelling an unknown request does nothing; the slow one stops without
+	// a response
+	c.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"other"}}`)
+	c.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"slow","reason":"user"}}`)
+	if rest := c.close(); len(rest) != 0 {
+		t.Errorf("cancelled request answered with %v", rest)
+	}
+}
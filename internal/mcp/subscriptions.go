@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gomantics/semantix/internal/domains/repos"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxSubscriptions bounds the resources one session can subscribe to
+	MaxSubscriptions = 1000
+
+	// watchRetryMin and watchRetryMax bound the wait before listening again
+	// after the connection fails
+	watchRetryMin = time.Second
+	watchRetryMax = time.Minute
+)
+
+var errTooManySubscriptions = fmt.Errorf("at most %d resources can be subscribed to", MaxSubscriptions)
+
+// subscriber holds one session's resource subscriptions. Transports create
+// it with the session and put it on each request's context.
+type subscriber struct {
+	workspaceID int64
+
+	mu sync.Mutex
+	// uris maps each subscribed URI to its repository
+	uris map[string]int64
+	// send delivers notifications outside of any request; nil while the
+	// client has no way to receive them
+	send notifier
+}
+
+type subscriberKey struct{}
+
+func withSubscriber(ctx context.Context, sub *subscriber) context.Context {
+	return context.WithValue(ctx, subscriberKey{}, sub)
+}
+
+func newSubscriber(workspaceID int64, send notifier) *subscriber {
+	return &subscriber{workspaceID: workspaceID, uris: make(map[string]int64), send: send}
+}
+
+func (s *subscriber) subscribe(uri string, repoID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.uris[uri]; !ok && len(s.uris) >= MaxSubscriptions {
+		return errTooManySubscriptions
+	}
+	s.uris[uri] = repoID
+	return nil
+}
+
+func (s *subscriber) unsubscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.uris, uri)
+}
+
+// attach sets where notifications go, reporting false when another stream
+// already receives them
+func (s *subscriber) attach(send notifier) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.send != nil {
+		return false
+	}
+	s.send = send
+	return true
+}
+
+func (s *subscriber) detach() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.send = nil
+}
+
+// indexed notifies the client of each subscribed resource in the repository
+func (s *subscriber) indexed(repoID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.send == nil {
+		return
+	}
+	for uri, id := range s.uris {
+		if id == repoID {
+			s.send(notification{JSONRPC: "2.0", Method: "notifications/resources/updated",
+				Params: map[string]string{"uri": uri}})
+		}
+	}
+}
+
+// subscribers are the sessions of this process that may hold subscriptions
+var subscribers = struct {
+	sync.Mutex
+	set map[*subscriber]struct{}
+}{set: make(map[*subscriber]struct{})}
+
+func register(sub *subscriber) {
+	subscribers.Lock()
+	defer subscribers.Unlock()
+	subscribers.set[sub] = struct{}{}
+}
+
+func unregister(sub *subscriber) {
+	subscribers.Lock()
+	defer subscribers.Unlock()
+	delete(subscribers.set, sub)
+}
+
+func publish(ev repos.IndexedEvent) {
+	subscribers.Lock()
+	defer subscribers.Unlock()
+	for sub := range subscribers.set {
+		if sub.workspaceID == ev.WorkspaceID {
+			sub.indexed(ev.RepoID)
+		}
+	}
+}
+
+// Watch relays finished index runs, announced by workers in any process, to
+// the subscribed sessions of this one until ctx ends
+func Watch(ctx context.Context, l *zap.Logger) {
+	wait := watchRetryMin
+	for {
+		started := time.Now()
+		err := repos.ListenIndexed(ctx, publish)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > watchRetryMax {
+			wait = watchRetryMin
+		}
+		l.Warn("lost index notifications; listening again", zap.Error(err), zap.Duration("retry_in", wait))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		wait = min(wait*2, watchRetryMax)
+	}
+}
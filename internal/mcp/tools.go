@@ -0,0 +1,541 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gomantics/semantix/internal/domains/files"
+	"github.com/gomantics/semantix/internal/domains/repos"
+	"github.com/gomantics/semantix/internal/domains/search"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultContextResults and MaxContextResults bound get_context's hits
+	DefaultContextResults = 5
+	MaxContextResults     = 20
+	// MaxContextLines bounds the lines get_context adds around each symbol
+	MaxContextLines = 50
+	// maxContextBytes bounds the code returned by one get_context call
+	maxContextBytes = 64 << 10
+)
+
+// Error codes of failed tool results, in structuredContent.error.code
+const (
+	errInvalidArguments = "invalid_arguments"
+	errNotFound         = "not_found"
+	errUnavailable      = "unavailable"
+	errInternal         = "internal"
+)
+
+// tool is an MCP tool definition and its handler
+type tool struct {
+	Name        string          `json:"name"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+
+	call func(s *Server, ctx context.Context, args json.RawMessage) *toolResult
+}
+
+// toolResult is the result of tools/call. Failures the assistant can act on
+// are results with IsError set rather than JSON-RPC errors.
+type toolResult struct {
+	Content           []textContent `json:"content"`
+	StructuredContent any           `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// toolError is the structured content of a failed tool call
+type toolError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func textResult(text string, structured any) *toolResult {
+	return &toolResult{Content: []textContent{{Type: "text", Text: text}}, StructuredContent: structured}
+}
+
+func errorResult(code, message string) *toolResult {
+	return &toolResult{
+		Content:           []textContent{{Type: "text", Text: message}},
+		StructuredContent: map[string]toolError{"error": {Code: code, Message: message}},
+		IsError:           true,
+	}
+}
+
+// filtersSchema is shared by the search tools
+const filtersSchema = `
+		"repo_ids": {"type": "array", "items": {"type": "integer"}, "description": "Only search these repositories (IDs from list_repos)"},
+		"languages": {"type": "array", "items": {"type": "string"}, "description": "Only search these languages, e.g. go, python"},
+		"chunk_types": {"type": "array", "items": {"type": "string"}, "description": "Only return these chunk types, e.g. function, class"},
+		"include": {"type": "array", "items": {"type": "string"}, "description": "Path globs to search, e.g. internal/**/*.go"},
+		"exclude": {"type": "array", "items": {"type": "string"}, "description": "Path globs to skip, e.g. **/*_test.go"}`
+
+// tools are listed in this order by tools/list
+var tools = []*tool{
+	{
+		Name:  "search",
+		Title: "Search code",
+		Description: "Search the workspace's indexed code by meaning and keywords, " +
+			`e.g. "how are webhooks verified?". Returns the best matching snippets with their locations.`,
+		InputSchema: json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"query": {"type": "string", "description": "Natural language question or code terms"},
+		"limit": {"type": "integer", "minimum": 1, "maximum": 100, "default": 10},` + filtersSchema + `
+	},
+	"required": ["query"],
+	"additionalProperties": false
+}`),
+		call: (*Server).searchTool,
+	},
+	{
+		Name:        "get_file",
+		Title:       "Read file",
+		Description: "Read lines of a file from an indexed repository at its indexed commit.",
+		InputSchema: json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"repo_id": {"type": "integer", "description": "Repository ID from list_repos"},
+		"path": {"type": "string", "description": "Path relative to the repository root"},
+		"start_line": {"type": "integer", "minimum": 1, "description": "First line, 1-based; defaults to the start of the file"},
+		"end_line": {"type": "integer", "minimum": 1, "description": "Last line, inclusive; defaults to the end of the file"},
+		"expand": {"type": "integer", "minimum": 0, "maximum": 500, "description": "Extra lines to include around the range"},
+		"expand_symbol": {"type": "boolean", "description": "Widen the range to the function, type or section enclosing it"}
+	},
+	"required": ["repo_id", "path"],
+	"additionalProperties": false
+}`),
+		call: (*Server).getFileTool,
+	},
+	{
+		Name:        "list_repos",
+		Title:       "List repositories",
+		Description: "List the workspace's repositories with their IDs and indexing status.",
+		InputSchema: json.RawMessage(`{
+	"type": "object",
+	"properties": {},
+	"additionalProperties": false
+}`),
+		call: (*Server).listReposTool,
+	},
+	{
+		Name:  "get_context",
+		Title: "Get code context",
+		Description: "Find the code most relevant to a question and return it whole: each match is widened " +
+			"to its enclosing function, type or section. Prefer this over search when you need to read the code.",
+		InputSchema: json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"query": {"type": "string", "description": "Natural language question or code terms"},
+		"limit": {"type": "integer", "minimum": 1, "maximum": 20, "default": 5, "description": "Matches to expand"},
+		"context_lines": {"type": "integer", "minimum": 0, "maximum": 50, "default": 0, "description": "Extra lines around each match"},` + filtersSchema + `
+	},
+	"required": ["query"],
+	"additionalProperties": false
+}`),
+		call: (*Server).getContextTool,
+	},
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+		Meta      struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "invalid tools/call params"}
+	}
+	if len(p.Meta.ProgressToken) > 0 {
+		ctx = context.WithValue(ctx, progressKey{}, p.Meta.ProgressToken)
+	}
+	for _, t := range tools {
+		if t.Name == p.Name {
+			return t.call(s, ctx, p.Arguments), nil
+		}
+	}
+	return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+}
+
+// decodeArgs decodes tool arguments strictly, so misspelled fields are
+// reported instead of silently ignored
+func decodeArgs(args json.RawMessage, v any) *toolResult {
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errorResult(errInvalidArguments, "invalid arguments: "+err.Error())
+	}
+	return nil
+}
+
+type searchArgs struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+	search.Filters
+}
+
+// searchError turns search failures into tool results
+func (s *Server) searchError(err error) *toolResult {
+	switch {
+	case errors.Is(err, search.ErrEmptyQuery), errors.Is(err, search.ErrQueryTooLong),
+		errors.Is(err, search.ErrInvalidGlob):
+		return errorResult(errInvalidArguments, err.Error())
+	case errors.Is(err, search.ErrUnavailable):
+		return errorResult(errUnavailable, err.Error())
+	}
+	s.l.Error("failed to search", zap.Error(err))
+	return errorResult(errInternal, "failed to search")
+}
+
+func (s *Server) hybrid(ctx context.Context, args searchArgs) ([]search.HybridResult, error) {
+	return search.Hybrid(ctx, search.HybridParams{
+		WorkspaceID: s.workspace.ID,
+		Query:       args.Query,
+		Filters:     args.Filters,
+		Weights:     search.DefaultWeights,
+		Limit:       args.Limit,
+	})
+}
+
+func (s *Server) searchTool(ctx context.Context, raw json.RawMessage) *toolResult {
+	var args searchArgs
+	if res := decodeArgs(raw, &args); res != nil {
+		return res
+	}
+	if args.Limit == 0 {
+		args.Limit = search.DefaultLimit
+	}
+	if args.Limit < 1 || args.Limit > search.MaxLimit {
+		return errorResult(errInvalidArguments, fmt.Sprintf("limit must be between 1 and %d", search.MaxLimit))
+	}
+
+	reportProgress(ctx, 0, 1, "searching")
+	results, err := s.hybrid(ctx, args)
+	if err != nil {
+		return s.searchError(err)
+	}
+	reportProgress(ctx, 1, 1, fmt.Sprintf("found %d results", len(results)))
+	names, err := s.repoNames(ctx)
+	if err != nil {
+		s.l.Error("failed to list repositories", zap.Error(err))
+		return errorResult(errInternal, "failed to search")
+	}
+
+	if len(results) == 0 {
+		return textResult("No results.", map[string]any{"results": results})
+	}
+	var b strings.Builder
+	for i, r := range results {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s:%s:%d-%d", names[r.RepoID], r.FilePath, r.StartLine, r.EndLine)
+		if r.SymbolName != nil {
+			fmt.Fprintf(&b, " (%s %s)", r.ChunkType, *r.SymbolName)
+		}
+		fmt.Fprintf(&b, " repo_id=%d\n", r.RepoID)
+		writeCode(&b, r.Language, r.Snippet)
+	}
+	return textResult(b.String(), map[string]any{"results": results})
+}
+
+type getFileArgs struct {
+	RepoID       int64  `json:"repo_id"`
+	Path         string `json:"path"`
+	StartLine    int    `json:"start_line"`
+	EndLine      int    `json:"end_line"`
+	Expand       int    `json:"expand"`
+	ExpandSymbol bool   `json:"expand_symbol"`
+}
+
+func (s *Server) getFileTool(ctx context.Context, raw json.RawMessage) *toolResult {
+	var args getFileArgs
+	if res := decodeArgs(raw, &args); res != nil {
+		return res
+	}
+	if args.RepoID <= 0 {
+		return errorResult(errInvalidArguments, "repo_id is required")
+	}
+
+	repo, err := repos.Get(ctx, s.workspace.ID, args.RepoID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNotFound) {
+			return errorResult(errNotFound, err.Error())
+		}
+		s.l.Error("failed to get repository", zap.Error(err), zap.Int64("repo_id", args.RepoID))
+		return errorResult(errInternal, "failed to read file")
+	}
+
+	content, err := files.Read(ctx, repo, files.ReadParams{
+		Path:         args.Path,
+		StartLine:    args.StartLine,
+		EndLine:      args.EndLine,
+		Expand:       args.Expand,
+		ExpandSymbol: args.ExpandSymbol,
+	})
+	if err != nil {
+		return s.readError(err, args.Path)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s/%s:%s lines %d-%d of %d\n", repo.Owner, repo.Name, content.Path,
+		content.StartLine, content.EndLine, content.TotalLines)
+	writeCode(&b, content.Language, content.Content)
+	return textResult(b.String(), content)
+}
+
+// readError turns file read failures into tool results
+func (s *Server) readError(err error, path string) *toolResult {
+	if code := readErrorCode(err); code != "" {
+		return errorResult(code, err.Error())
+	}
+	s.l.Error("failed to read file", zap.Error(err), zap.String("path", path))
+	return errorResult(errInternal, "failed to read file")
+}
+
+// readErrorCode classifies the file read failures caused by the request or
+// the repository's state; unexpected failures return ""
+func readErrorCode(err error) string {
+	switch {
+	case errors.Is(err, files.ErrInvalidPath), errors.Is(err, files.ErrInvalidRange),
+		errors.Is(err, files.ErrInvalidExpand), errors.Is(err, files.ErrNotAFile),
+		errors.Is(err, files.ErrTooLarge), errors.Is(err, files.ErrBinary):
+		return errInvalidArguments
+	case errors.Is(err, files.ErrNotFound):
+		return errNotFound
+	case errors.Is(err, files.ErrNotIndexed):
+		return errUnavailable
+	}
+	return ""
+}
+
+func (s *Server) listReposTool(ctx context.Context, raw json.RawMessage) *toolResult {
+	var args struct{}
+	if res := decodeArgs(raw, &args); res != nil {
+		return res
+	}
+
+	all, err := s.repos(ctx)
+	if err != nil {
+		s.l.Error("failed to list repositories", zap.Error(err))
+		return errorResult(errInternal, "failed to list repositories")
+	}
+
+	if len(all) == 0 {
+		return textResult("The workspace has no repositories.", map[string]any{"repos": all})
+	}
+	var b strings.Builder
+	for _, r := range all {
+		fmt.Fprintf(&b, "repo_id=%d %s/%s status=%s files=%d chunks=%d", r.ID, r.Owner, r.Name, r.Status, r.FileCount, r.ChunkCount)
+		if r.DefaultBranch != nil {
+			fmt.Fprintf(&b, " branch=%s", *r.DefaultBranch)
+		}
+		if r.IndexedCommit != nil {
+			fmt.Fprintf(&b, " commit=%.12s", *r.IndexedCommit)
+		}
+		b.WriteString("\n")
+	}
+	return textResult(b.String(), map[string]any{"repos": all})
+}
+
+type getContextArgs struct {
+	searchArgs
+	ContextLines int `json:"context_lines"`
+}
+
+// contextBlock is one stretch of code returned by get_context
+type contextBlock struct {
+	RepoID     int64   `json:"repo_id"`
+	Repo       string  `json:"repo"`
+	Path       string  `json:"path"`
+	Language   string  `json:"language"`
+	StartLine  int     `json:"start_line"`
+	EndLine    int     `json:"end_line"`
+	SymbolName *string `json:"symbol_name,omitempty"`
+	Content    string  `json:"content"`
+	Score      float32 `json:"score"`
+}
+
+// getContextTool searches, then reads each hit widened to its enclosing
+// symbol. Hits inside code already returned are dropped, and output stops
+// growing past maxContextBytes.
+func (s *Server) getContextTool(ctx context.Context, raw json.RawMessage) *toolResult {
+	var args getContextArgs
+	if res := decodeArgs(raw, &args); res != nil {
+		return res
+	}
+	if args.Limit == 0 {
+		args.Limit = DefaultContextResults
+	}
+	if args.Limit < 1 || args.Limit > MaxContextResults {
+		return errorResult(errInvalidArguments, fmt.Sprintf("limit must be between 1 and %d", MaxContextResults))
+	}
+	if args.ContextLines < 0 || args.ContextLines > MaxContextLines {
+		return errorResult(errInvalidArguments, fmt.Sprintf("context_lines must be between 0 and %d", MaxContextLines))
+	}
+
+	reportProgress(ctx, 0, 1, "searching")
+	results, err := s.hybrid(ctx, args.searchArgs)
+	if err != nil {
+		return s.searchError(err)
+	}
+	// One step for the search, one per result read
+	total := float64(1 + len(results))
+	reportProgress(ctx, 1, total, fmt.Sprintf("found %d results; reading files", len(results)))
+
+	var (
+		blocks    []contextBlock
+		size      int
+		truncated bool
+		repoByID  = make(map[int64]*repos.Repo)
+	)
+	for i, r := range results {
+		if i > 0 {
+			reportProgress(ctx, float64(1+i), total, "reading "+r.FilePath)
+		}
+		if covered(blocks, r) {
+			continue
+		}
+
+		repo, ok := repoByID[r.RepoID]
+		if !ok {
+			repo, err = repos.Get(ctx, s.workspace.ID, r.RepoID)
+			if err != nil && !errors.Is(err, repos.ErrNotFound) {
+				s.l.Error("failed to get repository", zap.Error(err), zap.Int64("repo_id", r.RepoID))
+				return errorResult(errInternal, "failed to get context")
+			}
+			repoByID[r.RepoID] = repo
+		}
+		// Deleted since the search
+		if repo == nil {
+			continue
+		}
+
+		block := contextBlock{
+			RepoID:     r.RepoID,
+			Repo:       repo.Owner + "/" + repo.Name,
+			Path:       r.FilePath,
+			Language:   r.Language,
+			StartLine:  r.StartLine,
+			EndLine:    r.EndLine,
+			SymbolName: r.SymbolName,
+			Content:    r.Snippet,
+			Score:      r.Score,
+		}
+		content, err := files.Read(ctx, repo, files.ReadParams{
+			Path:         r.FilePath,
+			StartLine:    r.StartLine,
+			EndLine:      r.EndLine,
+			Expand:       args.ContextLines,
+			ExpandSymbol: true,
+		})
+		switch {
+		case err == nil:
+			block.StartLine, block.EndLine, block.Content = content.StartLine, content.EndLine, content.Content
+			if content.SymbolName != nil {
+				block.SymbolName = content.SymbolName
+			}
+		case readErrorCode(err) != "":
+			// The file changed since indexing; the snippet still helps
+		default:
+			s.l.Error("failed to read file", zap.Error(err), zap.String("path", r.FilePath))
+			return errorResult(errInternal, "failed to get context")
+		}
+
+		if size > 0 && size+len(block.Content) > maxContextBytes {
+			truncated = true
+			break
+		}
+		size += len(block.Content)
+		blocks = append(blocks, block)
+	}
+
+	structured := map[string]any{"blocks": blocks, "truncated": truncated}
+	if len(blocks) == 0 {
+		return textResult("No results.", structured)
+	}
+	var b strings.Builder
+	for i, c := range blocks {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s:%s:%d-%d", c.Repo, c.Path, c.StartLine, c.EndLine)
+		if c.SymbolName != nil {
+			fmt.Fprintf(&b, " (%s)", *c.SymbolName)
+		}
+		fmt.Fprintf(&b, " repo_id=%d\n", c.RepoID)
+		writeCode(&b, c.Language, c.Content)
+	}
+	if truncated {
+		b.WriteString("\nMore matches were left out to keep the response short; narrow the query or use get_file.\n")
+	}
+	return textResult(b.String(), structured)
+}
+
+// covered reports whether a hit lies within a block already returned
+func covered(blocks []contextBlock, r search.HybridResult) bool {
+	for _, c := range blocks {
+		if c.RepoID == r.RepoID && c.Path == r.FilePath && c.StartLine <= r.StartLine && c.EndLine >= r.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+// repos returns every repository in the workspace
+func (s *Server) repos(ctx context.Context) ([]repos.Repo, error) {
+	var all []repos.Repo
+	for {
+		page, err := repos.List(ctx, repos.ListParams{WorkspaceID: s.workspace.ID, Limit: 100, Offset: len(all)})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Repos...)
+		if len(page.Repos) == 0 || int64(len(all)) >= page.Total {
+			return all, nil
+		}
+	}
+}
+
+// repoNames maps the workspace's repository IDs to owner/name
+func (s *Server) repoNames(ctx context.Context) (map[int64]string, error) {
+	all, err := s.repos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(all))
+	for _, r := range all {
+		names[r.ID] = r.Owner + "/" + r.Name
+	}
+	return names, nil
+}
+
+// writeCode writes a fenced code block long enough not to clash with
+// fences inside the code
+func writeCode(b *strings.Builder, language, code string) {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	b.WriteString(fence + language + "\n" + code)
+	if !strings.HasSuffix(code, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString(fence + "\n")
+}
@@ -0,0 +1,116 @@
+// Package testdb connects tests to a migrated Postgres database. Tests using
+// it are skipped unless CONFIG_DATABASE_DSN names a database they may write to.
+package testdb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"go.uber.org/zap"
+)
+
+// Connect opens the default pool on the test database, applying pending
+// migrations, and closes it when the test ends
+func Connect(t *testing.T) {
+	t.Helper()
+	if os.Getenv("CONFIG_DATABASE_DSN") == "" {
+		t.Skip("CONFIG_DATABASE_DSN not set")
+	}
+
+	ctx := context.Background()
+	if err := db.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	if _, err := db.Migrate(ctx, zap.NewNop(), db.MigrateOptions{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+}
+
+// Workspace creates a workspace that's deleted, with its repositories, their
+// stored file text, members and git tokens, when the test ends
+func Workspace(t *testing.T) db.Workspace {
+	t.Helper()
+	ctx := context.Background()
+
+	now := time.Now().UnixNano()
+	ws, err := db.Query1(ctx, func(q *db.Queries) (db.Workspace, error) {
+		return q.CreateWorkspace(ctx, db.CreateWorkspaceParams{
+			Name:     t.Name(),
+			Slug:     fmt.Sprintf("test-%d", now),
+			Settings: []byte("{}"),
+			Created:  now,
+			Updated:  now,
+		})
+	})
+	if err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+
+	t.Cleanup(func() {
+		err := db.Tx(ctx, func(q *db.Queries) error {
+			_, err := q.Conn().Exec(ctx, "DELETE FROM jobs WHERE dedupe_key IN (SELECT 'repo:' || id FROM repos WHERE workspace_id = $1)", ws.ID)
+			if err != nil {
+				return err
+			}
+			if err := q.DeleteFileContentsByWorkspace(ctx, ws.ID); err != nil {
+				return err
+			}
+			if err := q.DeleteReposByWorkspace(ctx, ws.ID); err != nil {
+				return err
+			}
+			if err := q.DeleteWorkspaceMembersByWorkspace(ctx, ws.ID); err != nil {
+				return err
+			}
+			if err := q.DeleteGitTokensByWorkspace(ctx, ws.ID); err != nil {
+				return err
+			}
+			return q.DeleteWorkspace(ctx, ws.ID)
+		})
+		if err != nil {
+			t.Errorf("delete workspace: %v", err)
+		}
+	})
+	return ws
+}
+
+// APIKey creates an API key that's deleted, with its memberships, when the
+// test ends. Its secret is never issued, so it's only used through the
+// principal it loads as.
+func APIKey(t *testing.T, superadmin bool) db.ApiKey {
+	t.Helper()
+	ctx := context.Background()
+
+	now := time.Now().UnixNano()
+	k, err := db.Query1(ctx, func(q *db.Queries) (db.ApiKey, error) {
+		return q.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+			Name:       t.Name(),
+			Prefix:     fmt.Sprintf("sx_t%011x", now%(1<<44)),
+			KeyHash:    fmt.Sprintf("%064x", now),
+			Superadmin: superadmin,
+			Created:    now,
+			Updated:    now,
+		})
+	})
+	if err != nil {
+		t.Fatalf("create API key: %v", err)
+	}
+
+	t.Cleanup(func() {
+		err := db.Tx(ctx, func(q *db.Queries) error {
+			if err := q.DeleteWorkspaceMembersByAPIKey(ctx, k.ID); err != nil {
+				return err
+			}
+			return q.DeleteAPIKey(ctx, k.ID)
+		})
+		if err != nil {
+			t.Errorf("delete API key: %v", err)
+		}
+	})
+	return k
+}
@@ -0,0 +1,334 @@
+// Package pgvector implements vectorstore.Store on the chunks table with the
+// pgvector extension, so Postgres can be the only datastore
+package pgvector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/vectorstore"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DefaultM              = 16
+	DefaultEfConstruction = 64
+	DefaultEfSearch       = 100
+
+	// maxIndexDimensions is the largest vector pgvector's HNSW index accepts
+	maxIndexDimensions = 2000
+
+	// upsertBatch bounds the rows written per statement
+	upsertBatch = 500
+
+	// schemaLockKey serialises schema changes across replicas ("pgvector" in ASCII)
+	schemaLockKey int64 = 0x7067766563746f72
+)
+
+// columns are the chunks columns read back into a payload, in scan order
+const columns = `id, workspace_id, repo_id, file_id, file_path, language, chunk_type,
+  COALESCE(symbol_name, ''), content, content_hash, chunk_index, start_line, end_line`
+
+// Options configures the HNSW index and searches
+type Options struct {
+	// M and EfConstruction are used when the index is created
+	M              int
+	EfConstruction int
+	// EfSearch is the candidate list size of each search
+	EfSearch int
+}
+
+// Store is a vectorstore.Store backed by the chunks table. Writes can join a
+// caller's transaction with ReplaceTx.
+type Store struct {
+	opts Options
+}
+
+var _ vectorstore.Store = (*Store)(nil)
+
+// New creates a store using the default database pool
+func New(opts Options) *Store {
+	if opts.M <= 1 {
+		opts.M = DefaultM
+	}
+	if opts.EfConstruction <= 0 {
+		opts.EfConstruction = DefaultEfConstruction
+	}
+	if opts.EfSearch <= 0 {
+		opts.EfSearch = DefaultEfSearch
+	}
+	return &Store{opts: opts}
+}
+
+// EnsureCollection creates the extension, the embedding column and its
+// index. An empty table is converted to a new vector size; a populated one
+// returns ErrDimensionMismatch.
+func (s *Store) EnsureCollection(ctx context.Context, dimensions int) error {
+	if dimensions <= 0 {
+		return fmt.Errorf("invalid vector size %d", dimensions)
+	}
+	if dimensions > maxIndexDimensions {
+		return fmt.Errorf("pgvector indexes support at most %d dimensions, embedder has %d", maxIndexDimensions, dimensions)
+	}
+
+	return db.Tx(ctx, func(q *db.Queries) error {
+		conn := q.Conn()
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", schemaLockKey); err != nil {
+			return err
+		}
+		if _, err := conn.Exec(ctx, "CREATE EXTENSION IF NOT EXISTS vector"); err != nil {
+			return fmt.Errorf("failed to create vector extension: %w", err)
+		}
+
+		// atttypmod holds a vector column's size
+		var current int
+		err := conn.QueryRow(ctx, `SELECT atttypmod FROM pg_attribute
+			WHERE attrelid = 'chunks'::regclass AND attname = 'embedding' AND NOT attisdropped`).Scan(&current)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			_, err = conn.Exec(ctx, fmt.Sprintf("ALTER TABLE chunks ADD COLUMN embedding vector(%d)", dimensions))
+			if err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case current != dimensions:
+			var populated bool
+			if err := conn.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM chunks)").Scan(&populated); err != nil {
+				return err
+			}
+			if populated {
+				return fmt.Errorf("%w: chunks has %d dimensions, embedder has %d",
+					vectorstore.ErrDimensionMismatch, current, dimensions)
+			}
+			// The index is built for one size, so it goes with the old type
+			if _, err := conn.Exec(ctx, "DROP INDEX IF EXISTS idx_chunks_embedding"); err != nil {
+				return err
+			}
+			_, err = conn.Exec(ctx, fmt.Sprintf("ALTER TABLE chunks ALTER COLUMN embedding TYPE vector(%d)", dimensions))
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = conn.Exec(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_chunks_embedding ON chunks
+			USING hnsw (embedding vector_cosine_ops) WITH (m = %d, ef_construction = %d)`,
+			s.opts.M, s.opts.EfConstruction))
+		return err
+	})
+}
+
+func (s *Store) Upsert(ctx context.Context, points []vectorstore.Point) error {
+	return db.Tx(ctx, func(q *db.Queries) error {
+		return upsert(ctx, q, points)
+	})
+}
+
+func (s *Store) DeleteByFilter(ctx context.Context, filter vectorstore.Filter) error {
+	if filter.IsEmpty() {
+		return vectorstore.ErrEmptyFilter
+	}
+	return db.Query(ctx, func(q *db.Queries) error {
+		return deleteByFilter(ctx, q, filter)
+	})
+}
+
+// ReplaceTx deletes the points matching filter and inserts points within the
+// caller's transaction, so a file's chunks change atomically with its row
+func (s *Store) ReplaceTx(ctx context.Context, q *db.Queries, filter vectorstore.Filter, points []vectorstore.Point) error {
+	if filter.IsEmpty() {
+		return vectorstore.ErrEmptyFilter
+	}
+	if err := deleteByFilter(ctx, q, filter); err != nil {
+		return err
+	}
+	return upsert(ctx, q, points)
+}
+
+func (s *Store) Search(ctx context.Context, params vectorstore.SearchParams) ([]vectorstore.Result, error) {
+	args := []any{formatVector(params.Vector)}
+	where := append([]string{"embedding IS NOT NULL"}, buildWhere(params.Filter, &args)...)
+	if params.MinScore > 0 {
+		args = append(args, params.MinScore)
+		where = append(where, fmt.Sprintf("1 - (embedding <=> $1::vector) >= $%d", len(args)))
+	}
+	args = append(args, max(params.Limit, 1), max(params.Offset, 0))
+
+	sql := fmt.Sprintf(`SELECT %s, 1 - (embedding <=> $1::vector)
+		FROM chunks
+		WHERE %s
+		ORDER BY embedding <=> $1::vector
+		LIMIT $%d OFFSET $%d`, columns, strings.Join(where, " AND "), len(args)-1, len(args))
+
+	return db.Tx1(ctx, func(q *db.Queries) ([]vectorstore.Result, error) {
+		conn := q.Conn()
+		// Iterative scans keep walking the index until enough rows pass the
+		// filters, instead of returning too few results (pgvector 0.8+)
+		_, err := conn.Exec(ctx, `SELECT set_config('hnsw.ef_search', $1, true),
+			set_config('hnsw.iterative_scan', 'strict_order', true)`,
+			strconv.Itoa(max(s.opts.EfSearch, params.Limit+params.Offset)))
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := conn.Query(ctx, sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, func(row pgx.CollectableRow) (vectorstore.Result, error) {
+			var r vectorstore.Result
+			p := &r.Payload
+			err := row.Scan(&r.ID, &p.WorkspaceID, &p.RepoID, &p.FileID, &p.FilePath, &p.Language, &p.ChunkType,
+				&p.SymbolName, &p.Content, &p.ContentHash, &p.ChunkIndex, &p.StartLine, &p.EndLine, &r.Score)
+			return r, err
+		})
+	})
+}
+
+func (s *Store) Count(ctx context.Context, filter vectorstore.Filter) (int64, error) {
+	var args []any
+	where := buildWhere(filter, &args)
+
+	return db.Query1(ctx, func(q *db.Queries) (int64, error) {
+		var n int64
+		err := q.Conn().QueryRow(ctx, "SELECT COUNT(*) FROM chunks"+whereClause(where), args...).Scan(&n)
+		return n, err
+	})
+}
+
+// Health checks that the database is reachable
+func (s *Store) Health(ctx context.Context) error {
+	return db.Query(ctx, func(q *db.Queries) error {
+		_, err := q.Conn().Exec(ctx, "SELECT 1")
+		return err
+	})
+}
+
+func upsert(ctx context.Context, q *db.Queries, points []vectorstore.Point) error {
+	now := time.Now().UnixNano()
+
+	for start := 0; start < len(points); start += upsertBatch {
+		batch := points[start:min(start+upsertBatch, len(points))]
+
+		var (
+			ids, paths, languages, types, symbols, contents, hashes, vectors []string
+			workspaceIDs, repoIDs, fileIDs                                   []int64
+			indexes, startLines, endLines                                    []int32
+		)
+		for _, p := range batch {
+			ids = append(ids, p.ID)
+			workspaceIDs = append(workspaceIDs, p.Payload.WorkspaceID)
+			repoIDs = append(repoIDs, p.Payload.RepoID)
+			fileIDs = append(fileIDs, p.Payload.FileID)
+			paths = append(paths, p.Payload.FilePath)
+			languages = append(languages, p.Payload.Language)
+			types = append(types, p.Payload.ChunkType)
+			symbols = append(symbols, p.Payload.SymbolName)
+			contents = append(contents, p.Payload.Content)
+			hashes = append(hashes, p.Payload.ContentHash)
+			indexes = append(indexes, int32(p.Payload.ChunkIndex))
+			startLines = append(startLines, int32(p.Payload.StartLine))
+			endLines = append(endLines, int32(p.Payload.EndLine))
+			vectors = append(vectors, formatVector(p.Vector))
+		}
+
+		_, err := q.Conn().Exec(ctx, `INSERT INTO chunks (id, workspace_id, repo_id, file_id, file_path, language,
+			chunk_type, symbol_name, content, content_hash, chunk_index, start_line, end_line, embedding, created)
+		SELECT id, workspace_id, repo_id, file_id, file_path, language,
+			chunk_type, NULLIF(symbol_name, ''), content, content_hash, chunk_index, start_line, end_line, embedding::vector, $15
+		FROM unnest($1::text[], $2::bigint[], $3::bigint[], $4::bigint[], $5::text[], $6::text[],
+			$7::text[], $8::text[], $9::text[], $10::text[], $11::int[], $12::int[], $13::int[], $14::text[])
+			AS t(id, workspace_id, repo_id, file_id, file_path, language,
+				chunk_type, symbol_name, content, content_hash, chunk_index, start_line, end_line, embedding)
+		ON CONFLICT (id) DO UPDATE SET
+			workspace_id = EXCLUDED.workspace_id,
+			repo_id = EXCLUDED.repo_id,
+			file_id = EXCLUDED.file_id,
+			file_path = EXCLUDED.file_path,
+			language = EXCLUDED.language,
+			chunk_type = EXCLUDED.chunk_type,
+			symbol_name = EXCLUDED.symbol_name,
+			content = EXCLUDED.content,
+			content_hash = EXCLUDED.content_hash,
+			chunk_index = EXCLUDED.chunk_index,
+			start_line = EXCLUDED.start_line,
+			end_line = EXCLUDED.end_line,
+			embedding = EXCLUDED.embedding`,
+			ids, workspaceIDs, repoIDs, fileIDs, paths, languages,
+			types, symbols, contents, hashes, indexes, startLines, endLines, vectors, now)
+		if err != nil {
+			return fmt.Errorf("failed to upsert chunks: %w", err)
+		}
+	}
+	return nil
+}
+
+func deleteByFilter(ctx context.Context, q *db.Queries, filter vectorstore.Filter) error {
+	var args []any
+	where := buildWhere(filter, &args)
+	_, err := q.Conn().Exec(ctx, "DELETE FROM chunks"+whereClause(where), args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+// buildWhere turns a filter into SQL conditions, appending their arguments.
+// Conditions are only added for set fields so the planner can use the
+// matching index.
+func buildWhere(f vectorstore.Filter, args *[]any) []string {
+	var where []string
+	add := func(column string, value any, cast string) {
+		*args = append(*args, value)
+		if cast == "" {
+			where = append(where, fmt.Sprintf("%s = $%d", column, len(*args)))
+		} else {
+			where = append(where, fmt.Sprintf("%s = ANY($%d::%s)", column, len(*args), cast))
+		}
+	}
+
+	if f.WorkspaceID != 0 {
+		add("workspace_id", f.WorkspaceID, "")
+	}
+	if len(f.RepoIDs) > 0 {
+		add("repo_id", f.RepoIDs, "bigint[]")
+	}
+	if len(f.FileIDs) > 0 {
+		add("file_id", f.FileIDs, "bigint[]")
+	}
+	if len(f.FilePaths) > 0 {
+		add("file_path", f.FilePaths, "text[]")
+	}
+	if len(f.Languages) > 0 {
+		add("language", f.Languages, "text[]")
+	}
+	if len(f.ChunkTypes) > 0 {
+		add("chunk_type", f.ChunkTypes, "text[]")
+	}
+	return where
+}
+
+func whereClause(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(where, " AND ")
+}
+
+// formatVector writes v in pgvector's text format, e.g. [0.1,0.2]
+func formatVector(v []float32) string {
+	b := make([]byte, 0, len(v)*10+2)
+	b = append(b, '[')
+	for i, x := range v {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendFloat(b, float64(x), 'g', -1, 32)
+	}
+	return string(append(b, ']'))
+}
@@ -0,0 +1,118 @@
+// Package vectordb provides the vector store selected in config
+package vectordb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gomantics/semantix/internal/embedder"
+	"github.com/gomantics/semantix/internal/vectordb/pgvector"
+	"github.com/gomantics/semantix/config"
+	"github.com/gomantics/semantix/db"
+	"github.com/gomantics/semantix/pkg/vectorstore"
+	"github.com/gomantics/semantix/pkg/vectorstore/hnsw"
+	"github.com/gomantics/semantix/pkg/vectorstore/qdrant"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Backends selectable with vectorstore.backend
+const (
+	BackendQdrant = "qdrant"
+	// BackendEmbedded keeps vectors in process, so only Postgres is needed
+	BackendEmbedded = "embedded"
+	// BackendPgvector keeps vectors in the chunks table, next to the metadata
+	BackendPgvector = "pgvector"
+)
+
+// initTimeout bounds the startup health check and collection setup
+const initTimeout = 30 * time.Second
+
+var defaultStore vectorstore.Store
+
+// Transactional is implemented by stores kept in the application database.
+// Their writes can join a db.Tx, so chunks change atomically with the rows
+// describing them. They write the chunks table themselves; with other stores
+// the indexer fills it for keyword search.
+type Transactional interface {
+	// ReplaceTx deletes the points matching filter and inserts points
+	ReplaceTx(ctx context.Context, q *db.Queries, filter vectorstore.Filter, points []vectorstore.Point) error
+}
+
+// Init opens the configured vector store and creates the collection for
+// the configured embedder. An unreachable store fails startup.
+func Init(lc fx.Lifecycle, l *zap.Logger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), initTimeout)
+	defer cancel()
+
+	s, err := New()
+	if err != nil {
+		return fmt.Errorf("failed to open vector store: %w", err)
+	}
+	// Embedded stores snapshot on close, so the next start doesn't replay the log
+	if c, ok := s.(io.Closer); ok {
+		lc.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				l.Info("closing vector store")
+				return c.Close()
+			},
+		})
+	}
+
+	if err := s.Health(ctx); err != nil {
+		return fmt.Errorf("vector store is not ready: %w", err)
+	}
+
+	// The collection's size depends on the model; it's created once one is configured
+	if e, err := embedder.Default(); err != nil {
+		l.Warn("skipping vector collection setup until embeddings are configured", zap.Error(err))
+	} else if err := s.EnsureCollection(ctx, e.Dimensions()); err != nil {
+		return fmt.Errorf("failed to set up vector collection: %w", err)
+	}
+
+	defaultStore = s
+	l.Info("vector store initialized", zap.String("backend", config.Vectorstore.Backend()))
+	return nil
+}
+
+// Default returns the store created by Init
+func Default() vectorstore.Store {
+	return defaultStore
+}
+
+// New opens the store described by config. Qdrant connectivity isn't checked.
+func New() (vectorstore.Store, error) {
+	switch backend := config.Vectorstore.Backend(); backend {
+	case BackendQdrant:
+		return qdrant.New(qdrant.Options{
+			URL:        config.Qdrant.Url(),
+			APIKey:     config.Qdrant.ApiKey(),
+			Collection: config.Qdrant.CollectionName(),
+			Distance:   config.Qdrant.Distance(),
+			HTTPClient: &http.Client{Timeout: time.Duration(config.Qdrant.TimeoutSeconds()) * time.Second},
+		}), nil
+	case BackendEmbedded:
+		s, err := hnsw.Open(hnsw.Options{
+			Dir:            config.Vectorstore.DataDir(),
+			M:              int(config.Vectorstore.HnswM()),
+			EfConstruction: int(config.Vectorstore.HnswEfConstruction()),
+			EfSearch:       int(config.Vectorstore.HnswEfSearch()),
+			SnapshotBytes:  config.Vectorstore.SnapshotWalMb() << 20,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case BackendPgvector:
+		return pgvector.New(pgvector.Options{
+			M:              int(config.Vectorstore.HnswM()),
+			EfConstruction: int(config.Vectorstore.HnswEfConstruction()),
+			EfSearch:       int(config.Vectorstore.HnswEfSearch()),
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown vector store backend %q", backend)
+	}
+}
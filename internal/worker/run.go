@@ -0,0 +1,65 @@
+// Package worker runs background jobs alongside the API
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomantics/semantix/internal/domains/indexing"
+	"github.com/gomantics/semantix/internal/domains/jobs"
+	"github.com/gomantics/semantix/internal/domains/repos"
+	"github.com/gomantics/semantix/internal/embedder"
+	"github.com/gomantics/semantix/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// cacheEvictionInterval is how often the embedding cache is trimmed
+const cacheEvictionInterval = time.Hour
+
+func Run(lc fx.Lifecycle, l *zap.Logger) error {
+	l = l.Named("worker")
+
+	pool := jobs.NewPool(l, map[jobs.Kind]jobs.Handler{
+		jobs.KindIndexRepo: indexing.HandleIndexRepo,
+	}, jobs.PoolOptions{
+		Concurrency:       int(config.Indexing.MaxConcurrentJobs()),
+		VisibilityTimeout: time.Duration(config.Jobs.VisibilityTimeoutSeconds()) * time.Second,
+		PollInterval:      time.Duration(config.Jobs.PollIntervalSeconds()) * time.Second,
+		Retention:         time.Duration(config.Jobs.RetentionHours()) * time.Hour,
+	})
+
+	evictCtx, stopEviction := context.WithCancel(context.Background())
+	evicted := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			n, err := repos.EnqueuePending(ctx)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				l.Info("queued pending repositories", zap.Int("count", n))
+			}
+
+			pool.Start()
+
+			go func() {
+				defer close(evicted)
+				if config.Embeddings.CacheEnabled() {
+					embedder.RunEviction(evictCtx, l.Named("cache"), cacheEvictionInterval)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stopEviction()
+			<-evicted
+
+			l.Info("draining job workers")
+			return pool.Stop(ctx)
+		},
+	})
+
+	return nil
+}
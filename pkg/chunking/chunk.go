@@ -0,0 +1,125 @@
+// Package chunking splits source files into chunks small enough to embed,
+// along the boundaries of the language's own structure where possible.
+package chunking
+
+import "strings"
+
+// Chunk types
+const (
+	TypeFunction = "function"
+	TypeMethod   = "method"
+	// TypeClass covers classes, structs, interfaces and other named types
+	TypeClass = "class"
+	// TypeBlock is code outside a declaration, such as imports or variables,
+	// or a window of lines from a file without structure
+	TypeBlock = "block"
+	// TypeSection is a Markdown section or a key path in a config file
+	TypeSection = "section"
+)
+
+const (
+	// DefaultMaxTokens is the target chunk size when Options doesn't set one
+	DefaultMaxTokens = 500
+	// DefaultOverlapLines is the line overlap when Options doesn't set one
+	DefaultOverlapLines = 5
+)
+
+// bytesPerToken approximates tokenizer output for source code
+const bytesPerToken = 4
+
+// Chunk is a contiguous range of lines from a file
+type Chunk struct {
+	Content    string `json:"content"`
+	FilePath   string `json:"file_path"`
+	StartLine  int    `json:"start_line"` // 1-based, inclusive
+	EndLine    int    `json:"end_line"`   // 1-based, inclusive
+	Language   string `json:"language"`
+	ChunkType  string `json:"chunk_type"`
+	SymbolName string `json:"symbol_name,omitempty"`
+}
+
+// Options configures chunk sizes
+type Options struct {
+	// MaxTokens is the approximate size above which a chunk is split
+	MaxTokens int
+	// OverlapLines is how many lines consecutive line windows share, so code
+	// near a window edge keeps some context. 0 uses DefaultOverlapLines;
+	// negative disables overlap.
+	OverlapLines int
+	// MaxFileSizeBytes skips larger files; 0 means no limit
+	MaxFileSizeBytes int64
+}
+
+func (o Options) maxBytes() int {
+	if o.MaxTokens <= 0 {
+		return DefaultMaxTokens * bytesPerToken
+	}
+	return o.MaxTokens * bytesPerToken
+}
+
+func (o Options) overlap() int {
+	switch {
+	case o.OverlapLines == 0:
+		return DefaultOverlapLines
+	case o.OverlapLines < 0:
+		return 0
+	}
+	return o.OverlapLines
+}
+
+// EstimateTokens approximates the number of tokens in s
+func EstimateTokens(s string) int {
+	return (len(s) + bytesPerToken - 1) / bytesPerToken
+}
+
+// lines indexes a file's lines so chunks can be cut on line boundaries
+type lines struct {
+	text []string // text[i] is line i+1 without its newline
+}
+
+func splitLines(src []byte) lines {
+	text := strings.Split(string(src), "\n")
+	// A trailing newline doesn't start another line
+	if len(text) > 1 && text[len(text)-1] == "" {
+		text = text[:len(text)-1]
+	}
+	return lines{text: text}
+}
+
+// count returns the number of lines
+func (l lines) count() int {
+	return len(l.text)
+}
+
+// join returns lines start..end (1-based, inclusive)
+func (l lines) join(start, end int) string {
+	return strings.Join(l.text[start-1:end], "\n")
+}
+
+// size returns the byte length of lines start..end including newlines
+func (l lines) size(start, end int) int {
+	n := 0
+	for i := start; i <= end; i++ {
+		n += len(l.text[i-1]) + 1
+	}
+	return n
+}
+
+// partition splits lines start..end into ranges of at most maxBytes,
+// cutting only before lines listed in cuts. A range with no permitted cut
+// inside it may exceed maxBytes.
+func (l lines) partition(start, end int, cuts []int, maxBytes int) [][2]int {
+	var parts [][2]int
+	from, last := start, start
+	for _, c := range append(cuts, end+1) {
+		if c <= last || c > end+1 {
+			continue
+		}
+		if l.size(from, c-1) > maxBytes && last > from {
+			parts = append(parts, [2]int{from, last - 1})
+			from = last
+		}
+		last = c
+	}
+	return append(parts, [2]int{from, end})
+}
@@ -0,0 +1,68 @@
+package chunking
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// spans formats each chunk as "start-end type symbol" for comparison
+func spans(chunks []Chunk) []string {
+	out := make([]string, len(chunks))
+	for i, c := range chunks {
+		out[i] = strings.TrimSpace(fmt.Sprintf("%d-%d %s %s", c.StartLine, c.EndLine, c.ChunkType, c.SymbolName))
+	}
+	return out
+}
+
+// checkChunks compares the chunks' spans with want and checks that each
+// chunk's content is exactly its line range of src
+func checkChunks(t *testing.T, src string, chunks []Chunk, want []string) {
+	t.Helper()
+	if got := spans(chunks); !slices.Equal(got, want) {
+		t.Errorf("chunks:\n  got  %q\n  want %q", got, want)
+	}
+
+	l := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	for _, c := range chunks {
+		if c.StartLine < 1 || c.EndLine < c.StartLine || c.EndLine > len(l) {
+			t.Errorf("chunk %d-%d is outside the file's %d lines", c.StartLine, c.EndLine, len(l))
+			continue
+		}
+		if want := strings.Join(l[c.StartLine-1:c.EndLine], "\n"); c.Content != want {
+			t.Errorf("chunk %d-%d content = %q, want %q", c.StartLine, c.EndLine, c.Content, want)
+		}
+	}
+}
+
+// numbered returns n lines "line 1" to "line n"
+func numbered(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestPartition(t *testing.T) {
+	// Every line is 10 bytes with its newline
+	l := splitLines([]byte(strings.Repeat("123456789\n", 10)))
+	tests := []struct {
+		name       string
+		start, end int
+		cuts       []int
+		maxBytes   int
+		want       [][2]int
+	}{
+		{name: "fits", start: 1, end: 10, cuts: []int{3, 6}, maxBytes: 100, want: [][2]int{{1, 10}}},
+		{name: "cut at permitted lines", start: 1, end: 10, cuts: []int{3, 5, 7, 9}, maxBytes: 40, want: [][2]int{{1, 4}, {5, 8}, {9, 10}}},
+		{name: "no cut keeps the range whole", start: 1, end: 10, maxBytes: 40, want: [][2]int{{1, 10}}},
+		{name: "cuts outside the range are ignored", start: 4, end: 8, cuts: []int{1, 6, 12}, maxBytes: 30, want: [][2]int{{4, 5}, {6, 8}}},
+	}
+	for _, tt := range tests {
+		if got := l.partition(tt.start, tt.end, tt.cuts, tt.maxBytes); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: partition = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package chunking
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrSkipped is wrapped by the errors returned for files that aren't worth indexing
+var ErrSkipped = errors.New("file skipped")
+
+var (
+	ErrTooLarge  = fmt.Errorf("%w: exceeds maximum file size", ErrSkipped)
+	ErrBinary    = fmt.Errorf("%w: binary content", ErrSkipped)
+	ErrGenerated = fmt.Errorf("%w: generated or minified", ErrSkipped)
+)
+
+// sniffLen is how much of a file is inspected for binary content and generated-code markers
+const sniffLen = 8000
+
+// minifiedLineLen is the average line length above which a file is treated as minified
+const minifiedLineLen = 500
+
+// generatedMarker matches the Go convention and the @generated tag used by many other tools
+var generatedMarker = regexp.MustCompile(`(?m)^\s*(//|#|/\*|<!--|--)?\s*(Code generated .* DO NOT EDIT\.|@generated\b)`)
+
+// lockfiles are dependency manifests generated by package managers
+var lockfiles = map[string]bool{
+	"go.sum":            true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"bun.lockb":         true,
+	"cargo.lock":        true,
+	"poetry.lock":       true,
+	"pipfile.lock":      true,
+	"composer.lock":     true,
+	"gemfile.lock":      true,
+	"mix.lock":          true,
+	"flake.lock":        true,
+}
+
+// Chunker splits one file's content into chunks
+type Chunker interface {
+	Chunk(path string, src []byte) ([]Chunk, error)
+}
+
+// Registry picks a chunker by detected language, falling back to line
+// windows for languages without one or files the chunker can't parse
+type Registry struct {
+	opts     Options
+	chunkers map[string]Chunker
+	fallback Chunker
+}
+
+// NewRegistry creates a registry with the built-in chunkers registered
+func NewRegistry(opts Options) *Registry {
+	r := &Registry{
+		opts:     opts,
+		chunkers: make(map[string]Chunker),
+		fallback: NewLineChunker(opts),
+	}
+	r.Register(LangGo, NewGoChunker(opts))
+	r.Register(LangMarkdown, NewMarkdownChunker(opts))
+	r.Register(LangYAML, NewStructuredChunker(LangYAML, opts))
+	r.Register(LangJSON, NewStructuredChunker(LangJSON, opts))
+	r.Register(LangTOML, NewStructuredChunker(LangTOML, opts))
+	return r
+}
+
+// Register sets the chunker used for a language, replacing any existing one
+func (r *Registry) Register(language string, c Chunker) {
+	r.chunkers[language] = c
+}
+
+// Chunk detects the file's language and chunks it.
+// Files that shouldn't be indexed return an error wrapping ErrSkipped.
+func (r *Registry) Chunk(path string, src []byte) ([]Chunk, error) {
+	if err := r.Skip(path, src); err != nil {
+		return nil, err
+	}
+
+	lang := Detect(path, src)
+
+	var chunks []Chunk
+	c, ok := r.chunkers[lang]
+	if ok {
+		var err error
+		chunks, err = c.Chunk(path, src)
+		if err != nil {
+			// Unparseable input still gets indexed, just without structure
+			ok = false
+		}
+	}
+	if !ok {
+		var err error
+		chunks, err = r.fallback.Chunk(path, src)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out := chunks[:0]
+	for _, chunk := range chunks {
+		if strings.TrimSpace(chunk.Content) == "" {
+			continue
+		}
+		chunk.Language = lang
+		out = append(out, chunk)
+	}
+	return out, nil
+}
+
+// Skip reports why a file shouldn't be indexed, or nil if it should
+func (r *Registry) Skip(path string, src []byte) error {
+	if r.opts.MaxFileSizeBytes > 0 && int64(len(src)) > r.opts.MaxFileSizeBytes {
+		return fmt.Errorf("%w (%d bytes)", ErrTooLarge, len(src))
+	}
+	if IsBinary(src) {
+		return ErrBinary
+	}
+	if IsGenerated(path, src) {
+		return ErrGenerated
+	}
+	return nil
+}
+
+// IsBinary reports whether src looks like binary data: it contains a NUL
+// byte or isn't valid UTF-8 near the start
+func IsBinary(src []byte) bool {
+	head := src[:min(len(src), sniffLen)]
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	// Don't count a multi-byte rune cut off by the sniff window
+	if len(head) < len(src) {
+		for i := 1; i < utf8.UTFMax && i <= len(head); i++ {
+			if utf8.RuneStart(head[len(head)-i]) {
+				if !utf8.FullRune(head[len(head)-i:]) {
+					head = head[:len(head)-i]
+				}
+				break
+			}
+		}
+	}
+	return !utf8.Valid(head)
+}
+
+// IsGenerated reports whether a file is a lockfile, minified, or marked as
+// generated near its top
+func IsGenerated(path string, src []byte) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if lockfiles[base] {
+		return true
+	}
+	if strings.Contains(base, ".min.") {
+		return true
+	}
+
+	if generatedMarker.Match(src[:min(len(src), sniffLen)]) {
+		return true
+	}
+
+	lineCount := bytes.Count(src, []byte("\n")) + 1
+	return len(src) > sniffLen && len(src)/lineCount > minifiedLineLen
+}
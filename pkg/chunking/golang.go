@@ -0,0 +1,213 @@
+package chunking
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// GoChunker chunks Go source using the standard library parser: one chunk per
+// function, method and type declaration, with doc comments attached.
+// Declarations over the size limit are split at statement (or field)
+// boundaries.
+type GoChunker struct {
+	opts Options
+}
+
+// NewGoChunker creates a Go chunker
+func NewGoChunker(opts Options) *GoChunker {
+	return &GoChunker{opts: opts}
+}
+
+// Chunk parses src and returns its chunks in file order.
+// Returns an error if src isn't valid Go.
+func (c *GoChunker) Chunk(path string, src []byte) ([]Chunk, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go file: %w", err)
+	}
+
+	g := &goFile{
+		path:     path,
+		fset:     fset,
+		lines:    splitLines(src),
+		maxBytes: c.opts.maxBytes(),
+	}
+
+	g.header(file)
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			g.funcDecl(d)
+		case *ast.GenDecl:
+			g.genDecl(d)
+		}
+	}
+
+	sort.SliceStable(g.chunks, func(i, j int) bool {
+		return g.chunks[i].StartLine < g.chunks[j].StartLine
+	})
+	return g.chunks, nil
+}
+
+type goFile struct {
+	path     string
+	fset     *token.FileSet
+	lines    lines
+	maxBytes int
+	chunks   []Chunk
+}
+
+// header emits the package clause with its doc comment and imports
+func (g *goFile) header(file *ast.File) {
+	start := file.Package
+	if file.Doc != nil {
+		start = file.Doc.Pos()
+	}
+	end := file.Name.End()
+	for _, decl := range file.Decls {
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+			end = d.End()
+		}
+	}
+	g.emit(g.line(start), g.line(end), TypeBlock, file.Name.Name, nil)
+}
+
+func (g *goFile) funcDecl(d *ast.FuncDecl) {
+	chunkType, symbol := TypeFunction, d.Name.Name
+	if d.Recv != nil && len(d.Recv.List) > 0 {
+		chunkType, symbol = TypeMethod, receiverName(d.Recv.List[0].Type)+"."+d.Name.Name
+	}
+
+	var cuts []int
+	if d.Body != nil {
+		cuts = g.stmtCuts(d.Body.List)
+	}
+	g.emit(g.line(docStart(d.Doc, d.Pos())), g.line(d.End()), chunkType, symbol, cuts)
+}
+
+func (g *goFile) genDecl(d *ast.GenDecl) {
+	switch d.Tok {
+	case token.TYPE:
+		for _, spec := range d.Specs {
+			ts := spec.(*ast.TypeSpec)
+			// An ungrouped declaration starts at the type keyword and carries the decl's doc
+			start, end := docStart(ts.Doc, ts.Pos()), ts.End()
+			if !d.Lparen.IsValid() {
+				start, end = docStart(d.Doc, d.Pos()), d.End()
+			}
+			g.emit(g.line(start), g.line(end), TypeClass, ts.Name.Name, g.fieldCuts(ts.Type))
+		}
+	case token.CONST, token.VAR:
+		var cuts []int
+		for _, spec := range d.Specs {
+			vs := spec.(*ast.ValueSpec)
+			cuts = append(cuts, g.line(docStart(vs.Doc, vs.Pos())))
+		}
+		symbol := d.Specs[0].(*ast.ValueSpec).Names[0].Name
+		g.emit(g.line(docStart(d.Doc, d.Pos())), g.line(d.End()), TypeBlock, symbol, cuts)
+	}
+}
+
+// emit adds lines start..end as one chunk, or several if it's too large
+func (g *goFile) emit(start, end int, chunkType, symbol string, cuts []int) {
+	for _, part := range g.lines.partition(start, end, cuts, g.maxBytes) {
+		g.chunks = append(g.chunks, Chunk{
+			Content:    g.lines.join(part[0], part[1]),
+			FilePath:   g.path,
+			StartLine:  part[0],
+			EndLine:    part[1],
+			Language:   "go",
+			ChunkType:  chunkType,
+			SymbolName: symbol,
+		})
+	}
+}
+
+// stmtCuts returns the lines where statements start. Statements too large to
+// fit in a chunk also contribute the starts of their nested statements.
+func (g *goFile) stmtCuts(stmts []ast.Stmt) []int {
+	var cuts []int
+	for _, stmt := range stmts {
+		cuts = append(cuts, g.line(stmt.Pos()))
+		if g.lines.size(g.line(stmt.Pos()), g.line(stmt.End())) <= g.maxBytes {
+			continue
+		}
+		for _, nested := range nestedStmts(stmt) {
+			cuts = append(cuts, g.stmtCuts(nested)...)
+		}
+	}
+	sort.Ints(cuts)
+	return cuts
+}
+
+// fieldCuts returns the lines where struct fields or interface methods start
+func (g *goFile) fieldCuts(expr ast.Expr) []int {
+	var fields *ast.FieldList
+	switch t := expr.(type) {
+	case *ast.StructType:
+		fields = t.Fields
+	case *ast.InterfaceType:
+		fields = t.Methods
+	}
+	if fields == nil {
+		return nil
+	}
+
+	cuts := make([]int, 0, len(fields.List))
+	for _, f := range fields.List {
+		cuts = append(cuts, g.line(docStart(f.Doc, f.Pos())))
+	}
+	return cuts
+}
+
+func (g *goFile) line(pos token.Pos) int {
+	return g.fset.Position(pos).Line
+}
+
+// nestedStmts returns the statement lists directly inside stmt
+func nestedStmts(stmt ast.Stmt) [][]ast.Stmt {
+	var lists [][]ast.Stmt
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if n == stmt {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.BlockStmt:
+			lists = append(lists, n.List)
+			return false
+		case *ast.CaseClause:
+			lists = append(lists, n.Body)
+			return false
+		case *ast.CommClause:
+			lists = append(lists, n.Body)
+			return false
+		case *ast.FuncLit:
+			lists = append(lists, n.Body.List)
+			return false
+		}
+		return true
+	})
+	return lists
+}
+
+// receiverName formats a method receiver type: (*Server) for pointers,
+// Server for values, keeping type parameters as in (*List[T])
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		return "(*" + types.ExprString(star.X) + ")"
+	}
+	return types.ExprString(expr)
+}
+
+// docStart returns where a declaration starts including its doc comment
+func docStart(doc *ast.CommentGroup, pos token.Pos) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+	return pos
+}
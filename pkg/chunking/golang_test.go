@@ -0,0 +1,166 @@
+package chunking
+
+import "testing"
+
+func TestGoChunker(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts Options
+		want []string
+	}{
+		{
+			name: "declarations",
+			src: `// Package store keeps things
+package store
+
+import "fmt"
+
+// Server serves
+type Server struct {
+	addr string
+}
+
+// Start starts
+func (s *Server) Start() error {
+	return nil
+}
+
+func (s Server) Addr() string { return s.addr }
+
+func (l *List[T]) Push(v T) {}
+
+func (m Map[K, V]) Len() int { return len(m) }
+
+const (
+	A = 1
+	B = 2
+)
+
+var debug = fmt.Sprint("x")
+
+func main() {}
+`,
+			want: []string{
+				"1-4 block store",
+				"6-9 class Server",
+				"11-14 method (*Server).Start",
+				"16-16 method Server.Addr",
+				"18-18 method (*List[T]).Push",
+				"20-20 method Map[K, V].Len",
+				"22-25 block A",
+				"27-27 block debug",
+				"29-29 function main",
+			},
+		},
+		{
+			name: "grouped types keep their own docs",
+			src: `package p
+
+type (
+	// A is a
+	A int
+
+	// B is b
+	B struct{ x int }
+)
+`,
+			want: []string{
+				"1-1 block p",
+				"4-5 class A",
+				"7-8 class B",
+			},
+		},
+		{
+			// 20 tokens is 80 bytes, so the function splits before the loop
+			name: "oversized function",
+			opts: Options{MaxTokens: 20},
+			src: `package p
+
+func big() {
+	a := 1
+	b := 2
+	if a < b {
+		a = b + 1
+		b = a + 1
+	}
+	for i := 0; i < 3; i++ {
+		a += i
+	}
+	_ = a + b
+}
+`,
+			want: []string{
+				"1-1 block p",
+				"3-9 function big",
+				"10-14 function big",
+			},
+		},
+		{
+			// The loop is too large for a chunk, so it's split between the
+			// statements inside it
+			name: "oversized statement",
+			opts: Options{MaxTokens: 15},
+			src: `package p
+
+func big() {
+	for {
+		first := "aaaaaaaaaaaa"
+		second := "bbbbbbbbbbbb"
+		third := "cccccccccccc"
+	}
+}
+`,
+			want: []string{
+				"1-1 block p",
+				"3-5 function big",
+				"6-9 function big",
+			},
+		},
+		{
+			name: "oversized struct",
+			opts: Options{MaxTokens: 10},
+			src: `package p
+
+type Config struct {
+	// Name names it
+	Name string
+	Addr string
+	Port int
+}
+`,
+			want: []string{
+				"1-1 block p",
+				"3-3 class Config",
+				"4-5 class Config",
+				"6-8 class Config",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := NewGoChunker(tt.opts).Chunk("p.go", []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkChunks(t, tt.src, chunks, tt.want)
+		})
+	}
+}
+
+func TestGoChunkerInvalid(t *testing.T) {
+	if _, err := NewGoChunker(Options{}).Chunk("p.go", []byte("package p\nfunc {")); err == nil {
+		t.Fatal("invalid Go chunked without error")
+	}
+
+	// The registry falls back to line windows
+	src := "package p\nfunc {\n"
+	chunks, err := NewRegistry(Options{}).Chunk("p.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, src, chunks, []string{"1-2 block"})
+	if chunks[0].Language != LangGo {
+		t.Errorf("language = %q, want go", chunks[0].Language)
+	}
+}
@@ -0,0 +1,195 @@
+package chunking
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// Languages with dedicated chunkers
+const (
+	LangGo       = "go"
+	LangMarkdown = "markdown"
+	LangYAML     = "yaml"
+	LangJSON     = "json"
+	LangTOML     = "toml"
+	// LangText is used when the language can't be detected
+	LangText = "text"
+)
+
+// filenames maps well-known file names, which often lack an extension
+var filenames = map[string]string{
+	"makefile":       "makefile",
+	"gnumakefile":    "makefile",
+	"dockerfile":     "dockerfile",
+	"containerfile":  "dockerfile",
+	"go.mod":         "gomod",
+	"go.work":        "gomod",
+	"cmakelists.txt": "cmake",
+	"gemfile":        "ruby",
+	"rakefile":       "ruby",
+	"jenkinsfile":    "groovy",
+	"vagrantfile":    "ruby",
+	"procfile":       "yaml",
+	".bashrc":        "shell",
+	".zshrc":         "shell",
+	".profile":       "shell",
+	".gitignore":     "ignore",
+	".dockerignore":  "ignore",
+	".editorconfig":  "ini",
+}
+
+var extensions = map[string]string{
+	".go":         LangGo,
+	".py":         "python",
+	".pyi":        "python",
+	".js":         "javascript",
+	".mjs":        "javascript",
+	".cjs":        "javascript",
+	".jsx":        "javascript",
+	".ts":         "typescript",
+	".mts":        "typescript",
+	".cts":        "typescript",
+	".tsx":        "typescript",
+	".java":       "java",
+	".kt":         "kotlin",
+	".kts":        "kotlin",
+	".scala":      "scala",
+	".groovy":     "groovy",
+	".gradle":     "groovy",
+	".rs":         "rust",
+	".c":          "c",
+	".h":          "c",
+	".cc":         "cpp",
+	".cpp":        "cpp",
+	".cxx":        "cpp",
+	".hh":         "cpp",
+	".hpp":        "cpp",
+	".hxx":        "cpp",
+	".cs":         "csharp",
+	".fs":         "fsharp",
+	".swift":      "swift",
+	".m":          "objectivec",
+	".rb":         "ruby",
+	".php":        "php",
+	".pl":         "perl",
+	".pm":         "perl",
+	".lua":        "lua",
+	".r":          "r",
+	".dart":       "dart",
+	".ex":         "elixir",
+	".exs":        "elixir",
+	".erl":        "erlang",
+	".hs":         "haskell",
+	".ml":         "ocaml",
+	".clj":        "clojure",
+	".zig":        "zig",
+	".sh":         "shell",
+	".bash":       "shell",
+	".zsh":        "shell",
+	".fish":       "shell",
+	".ps1":        "powershell",
+	".sql":        "sql",
+	".proto":      "protobuf",
+	".graphql":    "graphql",
+	".gql":        "graphql",
+	".html":       "html",
+	".htm":        "html",
+	".vue":        "vue",
+	".svelte":     "svelte",
+	".css":        "css",
+	".scss":       "scss",
+	".sass":       "sass",
+	".less":       "less",
+	".xml":        "xml",
+	".tf":         "hcl",
+	".hcl":        "hcl",
+	".ini":        "ini",
+	".cfg":        "ini",
+	".dockerfile": "dockerfile",
+	".mk":         "makefile",
+	".md":         LangMarkdown,
+	".markdown":   LangMarkdown,
+	".mdx":        LangMarkdown,
+	".yaml":       LangYAML,
+	".yml":        LangYAML,
+	".json":       LangJSON,
+	".jsonc":      LangJSON,
+	".toml":       LangTOML,
+	".txt":        LangText,
+	".rst":        LangText,
+}
+
+// interpreters maps shebang interpreters to languages
+var interpreters = map[string]string{
+	"sh":      "shell",
+	"bash":    "shell",
+	"zsh":     "shell",
+	"dash":    "shell",
+	"ksh":     "shell",
+	"fish":    "shell",
+	"python":  "python",
+	"node":    "javascript",
+	"deno":    "typescript",
+	"ts-node": "typescript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+	"lua":     "lua",
+	"Rscript": "r",
+}
+
+// Detect returns the language of a file from its name, extension or
+// shebang line, or LangText when none match
+func Detect(path string, src []byte) string {
+	base := filepath.Base(path)
+	lower := strings.ToLower(base)
+
+	if lang, ok := filenames[lower]; ok {
+		return lang
+	}
+	// Dockerfile.dev, Makefile.common and friends
+	if prefix, _, ok := strings.Cut(lower, "."); ok {
+		if lang, ok := filenames[prefix]; ok && (lang == "dockerfile" || lang == "makefile") {
+			return lang
+		}
+	}
+
+	if lang, ok := extensions[strings.ToLower(filepath.Ext(base))]; ok {
+		return lang
+	}
+
+	if lang := shebang(src); lang != "" {
+		return lang
+	}
+
+	return LangText
+}
+
+// shebang returns the language named by a #! line, or ""
+func shebang(src []byte) string {
+	if !bytes.HasPrefix(src, []byte("#!")) {
+		return ""
+	}
+	line, _, _ := bytes.Cut(src[2:], []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interp := filepath.Base(fields[0])
+	// #!/usr/bin/env [-S] python3
+	if interp == "env" {
+		interp = ""
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "-") && !strings.Contains(f, "=") {
+				interp = f
+				break
+			}
+		}
+	}
+
+	// python3.12 -> python
+	interp = strings.TrimRight(interp, "0123456789.")
+	return interpreters[interp]
+}
@@ -0,0 +1,122 @@
+package chunking
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdHeading = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	mdFence   = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+)
+
+// MarkdownChunker splits Markdown at ATX headings. Each section's symbol is
+// its heading path, e.g. "Install > From source". Oversized sections are
+// split at paragraph boundaries; fenced code blocks are never split on a
+// heading-like line inside them.
+type MarkdownChunker struct {
+	opts Options
+}
+
+// NewMarkdownChunker creates a Markdown chunker
+func NewMarkdownChunker(opts Options) *MarkdownChunker {
+	return &MarkdownChunker{opts: opts}
+}
+
+type mdSection struct {
+	start  int
+	symbol string
+}
+
+func (c *MarkdownChunker) Chunk(path string, src []byte) ([]Chunk, error) {
+	l := splitLines(src)
+
+	// Walk lines tracking fences, collecting section starts and paragraph starts
+	sections := []mdSection{{start: 1}}
+	var paragraphs []int
+	var headings []string // headings[i] is the current level i+1 heading
+	var fence string
+	prevBlank := true
+	for i, text := range l.text {
+		n := i + 1
+
+		if m := mdFence.FindStringSubmatch(text); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+				if prevBlank {
+					paragraphs = append(paragraphs, n)
+				}
+			case strings.HasPrefix(m[1], fence):
+				fence = ""
+			}
+			prevBlank = false
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		if m := mdHeading.FindStringSubmatch(text); m != nil {
+			level := len(m[1])
+			headings = headings[:min(level-1, len(headings))]
+			for len(headings) < level-1 {
+				headings = append(headings, "")
+			}
+			headings = append(headings, m[2])
+			sections = append(sections, mdSection{start: n, symbol: headingPath(headings)})
+			prevBlank = false
+			continue
+		}
+
+		blank := strings.TrimSpace(text) == ""
+		if !blank && prevBlank {
+			paragraphs = append(paragraphs, n)
+		}
+		prevBlank = blank
+	}
+
+	var chunks []Chunk
+	for i, s := range sections {
+		end := l.count()
+		if i+1 < len(sections) {
+			end = sections[i+1].start - 1
+		}
+		if end < s.start {
+			continue
+		}
+
+		chunk := func(start, end int) Chunk {
+			return Chunk{
+				Content:    l.join(start, end),
+				FilePath:   path,
+				StartLine:  start,
+				EndLine:    end,
+				Language:   LangMarkdown,
+				ChunkType:  TypeSection,
+				SymbolName: s.symbol,
+			}
+		}
+
+		for _, part := range l.partition(s.start, end, paragraphs, c.opts.maxBytes()) {
+			// A paragraph or code block too large on its own falls back to line windows
+			if l.size(part[0], part[1]) > c.opts.maxBytes() {
+				chunks = append(chunks, windows(l, part[0], part[1], c.opts, chunk)...)
+				continue
+			}
+			chunks = append(chunks, chunk(part[0], part[1]))
+		}
+	}
+	return chunks, nil
+}
+
+// headingPath joins the non-empty headings, skipping levels the document jumped over
+func headingPath(headings []string) string {
+	var parts []string
+	for _, h := range headings {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
@@ -0,0 +1,92 @@
+package chunking
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownChunker(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts Options
+		want []string
+	}{
+		{
+			name: "sections",
+			src: `Intro before any heading.
+
+# Install
+
+Run the installer.
+
+## From source ##
+
+    make build
+
+### Skipped level
+#### Deep
+
+# Usage
+`,
+			want: []string{
+				"1-2 section",
+				"3-6 section Install",
+				"7-10 section Install > From source",
+				"11-11 section Install > From source > Skipped level",
+				"12-13 section Install > From source > Skipped level > Deep",
+				"14-14 section Usage",
+			},
+		},
+		{
+			name: "headings in code fences",
+			src:  "# Build\n\n```sh\n# not a heading\nmake\n```\n\n~~~\n## nor this\n~~~\n## Test\n",
+			want: []string{
+				"1-10 section Build",
+				"11-11 section Build > Test",
+			},
+		},
+		{
+			name: "level skipped from the top",
+			src:  "### Deep first\ntext\n# Top\n",
+			want: []string{
+				"1-2 section Deep first",
+				"3-3 section Top",
+			},
+		},
+		{
+			// 10 tokens is 40 bytes: the section splits before a paragraph
+			name: "oversized section",
+			opts: Options{MaxTokens: 10},
+			src:  "# Notes\n\nfirst paragraph here\n\nsecond paragraph\nstill second\n\nthird\n",
+			want: []string{
+				"1-4 section Notes",
+				"5-8 section Notes",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := NewMarkdownChunker(tt.opts).Chunk("README.md", []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkChunks(t, tt.src, chunks, tt.want)
+		})
+	}
+}
+
+func TestMarkdownOversizedParagraph(t *testing.T) {
+	// A paragraph with no break inside falls back to line windows
+	src := "# Log\n" + strings.TrimSuffix(numbered(12), "\n")
+	opts := Options{MaxTokens: 10, OverlapLines: 1}
+	chunks, err := NewMarkdownChunker(opts).Chunk("LOG.md", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, src, chunks, []string{
+		"1-5 section Log",
+		"5-9 section Log",
+		"9-13 section Log",
+	})
+}
@@ -0,0 +1,336 @@
+package chunking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// StructuredChunker splits YAML, JSON and TOML files along their key
+// hierarchy. Small sibling keys are grouped into one chunk; a key too large
+// for a chunk is split along its child keys, recursively, and its symbol is
+// its dotted key path, e.g. "services.api.environment".
+type StructuredChunker struct {
+	language string
+	opts     Options
+}
+
+// NewStructuredChunker creates a chunker for LangYAML, LangJSON or LangTOML
+func NewStructuredChunker(language string, opts Options) *StructuredChunker {
+	return &StructuredChunker{language: language, opts: opts}
+}
+
+// keyNode is a key and the line it's declared on
+type keyNode struct {
+	path     string
+	line     int
+	children []*keyNode
+}
+
+func (c *StructuredChunker) Chunk(path string, src []byte) ([]Chunk, error) {
+	l := splitLines(src)
+
+	var roots []*keyNode
+	switch c.language {
+	case LangYAML:
+		roots = yamlKeys(l)
+	case LangTOML:
+		roots = tomlKeys(l)
+	case LangJSON:
+		var err error
+		if roots, err = jsonKeys(src); err != nil {
+			return nil, fmt.Errorf("failed to parse json: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported structured language %q", c.language)
+	}
+
+	s := &structuredFile{path: path, language: c.language, lines: l, opts: c.opts}
+	s.sections(roots, 1, l.count(), "")
+	return s.chunks, nil
+}
+
+type structuredFile struct {
+	path     string
+	language string
+	lines    lines
+	opts     Options
+	chunks   []Chunk
+}
+
+// sections covers lines start..end, in which nodes are the outermost keys.
+// Lines before the first key belong to the first section.
+func (s *structuredFile) sections(nodes []*keyNode, start, end int, parent string) {
+	if len(nodes) == 0 {
+		s.windows(start, end, parent)
+		return
+	}
+
+	// rangeEnd is the last line of node i, just before its next sibling
+	rangeEnd := func(i int) int {
+		if i+1 < len(nodes) {
+			return nodes[i+1].line - 1
+		}
+		return end
+	}
+
+	maxBytes := s.opts.maxBytes()
+	for i := 0; i < len(nodes); {
+		from := nodes[i].line
+		if i == 0 {
+			from = start
+		}
+
+		if s.lines.size(from, rangeEnd(i)) > maxBytes {
+			n := nodes[i]
+			s.sections(n.children, from, rangeEnd(i), n.path)
+			i++
+			continue
+		}
+
+		// Group following siblings while they fit
+		j := i
+		for j+1 < len(nodes) && s.lines.size(from, rangeEnd(j+1)) <= maxBytes {
+			j++
+		}
+
+		symbol := parent
+		if j == i {
+			symbol = nodes[i].path
+		}
+		s.emit(from, rangeEnd(j), symbol)
+		i = j + 1
+	}
+}
+
+// windows splits a key with no children to recurse into by line windows
+func (s *structuredFile) windows(start, end int, symbol string) {
+	s.chunks = append(s.chunks, windows(s.lines, start, end, s.opts, func(start, end int) Chunk {
+		return s.chunk(start, end, symbol)
+	})...)
+}
+
+func (s *structuredFile) emit(start, end int, symbol string) {
+	s.chunks = append(s.chunks, s.chunk(start, end, symbol))
+}
+
+func (s *structuredFile) chunk(start, end int, symbol string) Chunk {
+	return Chunk{
+		Content:    s.lines.join(start, end),
+		FilePath:   s.path,
+		StartLine:  start,
+		EndLine:    end,
+		Language:   s.language,
+		ChunkType:  TypeSection,
+		SymbolName: symbol,
+	}
+}
+
+func joinKey(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+var (
+	yamlKey         = regexp.MustCompile(`^( *)("[^"]*"|'[^']*'|[^\s#'"{\[\-?][^:#]*?|-[^\s:#][^:#]*?)[ \t]*:(?:[ \t]+(.*))?$`)
+	yamlBlockScalar = regexp.MustCompile(`^[|>][-+0-9]*\s*(#.*)?$`)
+)
+
+// yamlKeys finds mapping keys by indentation. Keys inside sequences and
+// block scalar content are ignored.
+func yamlKeys(l lines) []*keyNode {
+	type level struct {
+		indent int
+		node   *keyNode
+	}
+
+	var roots []*keyNode
+	var stack []level
+	scalarIndent := -1 // indent of the key owning the block scalar being skipped
+	for i, text := range l.text {
+		trimmed := strings.TrimSpace(text)
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+
+		if scalarIndent >= 0 {
+			if trimmed == "" || indent > scalarIndent {
+				continue
+			}
+			scalarIndent = -1
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if trimmed == "---" || trimmed == "..." {
+			stack = stack[:0]
+			continue
+		}
+
+		m := yamlKey.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		key := strings.Trim(m[2], `"'`)
+		node := &keyNode{line: i + 1}
+		if len(stack) == 0 {
+			node.path = key
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1].node
+			node.path = joinKey(parent.path, key)
+			parent.children = append(parent.children, node)
+		}
+		stack = append(stack, level{indent: indent, node: node})
+
+		if yamlBlockScalar.MatchString(m[3]) {
+			scalarIndent = indent
+		}
+	}
+	return roots
+}
+
+var (
+	tomlTable = regexp.MustCompile(`^\s*\[\[?\s*([^\[\]]+?)\s*\]\]?\s*(#.*)?$`)
+	tomlKey   = regexp.MustCompile(`^\s*([A-Za-z0-9_\-]+|"[^"]*"|'[^']*')((?:\s*\.\s*(?:[A-Za-z0-9_\-]+|"[^"]*"|'[^']*'))*)\s*=`)
+)
+
+// tomlKeys returns the root keys and tables, with each table's keys as its
+// children. Lines inside multi-line strings are ignored.
+func tomlKeys(l lines) []*keyNode {
+	var roots []*keyNode
+	var table *keyNode
+	inString := ""
+	for i, text := range l.text {
+		if inString != "" {
+			if strings.Count(text, inString)%2 == 1 {
+				inString = ""
+			}
+			continue
+		}
+
+		if m := tomlTable.FindStringSubmatch(text); m != nil {
+			table = &keyNode{path: m[1], line: i + 1}
+			roots = append(roots, table)
+			continue
+		}
+
+		m := tomlKey.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		key := strings.Trim(m[1], `"'`) + strings.Join(strings.Fields(m[2]), "")
+
+		if table == nil {
+			roots = append(roots, &keyNode{path: key, line: i + 1})
+		} else {
+			table.children = append(table.children, &keyNode{path: joinKey(table.path, key), line: i + 1})
+		}
+
+		for _, delim := range []string{`"""`, `'''`} {
+			if strings.Count(text, delim)%2 == 1 {
+				inString = delim
+			}
+		}
+	}
+	return roots
+}
+
+// jsonKeys returns the keys of nested objects. Objects inside arrays aren't
+// descended into, since their keys repeat for every element.
+func jsonKeys(src []byte) ([]*keyNode, error) {
+	var newlines []int
+	for i, b := range src {
+		if b == '\n' {
+			newlines = append(newlines, i)
+		}
+	}
+	lineAt := func(offset int64) int {
+		return sort.SearchInts(newlines, int(offset)) + 1
+	}
+
+	type frame struct {
+		object    bool
+		record    bool
+		node      *keyNode // the key owning this container; nil at the root
+		expectKey bool
+		pending   *keyNode // the key whose value is being read
+	}
+
+	var roots []*keyNode
+	var stack []*frame
+	dec := json.NewDecoder(bytes.NewReader(src))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			if len(stack) > 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				f := &frame{object: t == '{', expectKey: true}
+				switch {
+				case top == nil:
+					f.record = true
+				case top.object && top.record:
+					f.record, f.node = true, top.pending
+				}
+				stack = append(stack, f)
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				if len(stack) > 0 {
+					stack[len(stack)-1].expectKey = true
+				}
+			}
+		default:
+			if top == nil || !top.object {
+				continue
+			}
+			if !top.expectKey {
+				top.expectKey = true
+				continue
+			}
+
+			top.expectKey = false
+			top.pending = nil
+			if !top.record {
+				continue
+			}
+
+			key, _ := t.(string)
+			node := &keyNode{line: lineAt(dec.InputOffset() - 1)}
+			if top.node == nil {
+				node.path = key
+				roots = append(roots, node)
+			} else {
+				node.path = joinKey(top.node.path, key)
+				top.node.children = append(top.node.children, node)
+			}
+			top.pending = node
+		}
+	}
+	return roots, nil
+}
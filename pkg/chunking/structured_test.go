@@ -0,0 +1,121 @@
+package chunking
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStructuredChunker(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		src      string
+		opts     Options
+		want     []string
+	}{
+		{
+			name:     "yaml fits",
+			language: LangYAML,
+			src:      "# config\nname: api\nport: 8080\n",
+			want:     []string{"1-3 section"},
+		},
+		{
+			// A large key splits along its children, the first taking the
+			// parent keys' lines above it
+			name:     "yaml nested",
+			language: LangYAML,
+			opts:     Options{MaxTokens: 15},
+			src: `name: api
+services:
+  api:
+    image: ghcr.io/acme/api:latest
+    environment:
+      LOG_LEVEL: debug
+  db:
+    image: postgres
+`,
+			want: []string{
+				"1-1 section name",
+				"2-4 section services.api.image",
+				"5-6 section services.api.environment",
+				"7-8 section services.db",
+			},
+		},
+		{
+			// Siblings that fit together take their parent's path
+			name:     "yaml grouped siblings",
+			language: LangYAML,
+			opts:     Options{MaxTokens: 10},
+			src:      "services:\n  a: 1\n  b: 2\n  c: " + strings.Repeat("x", 40) + "\n",
+			want: []string{
+				"1-3 section services",
+				"4-4 section services.c",
+			},
+		},
+		{
+			name:     "yaml block scalar",
+			language: LangYAML,
+			opts:     Options{MaxTokens: 10},
+			src:      "script: |\n  key: not a key\n  echo done\nafter: 1\n",
+			want: []string{
+				"1-3 section script",
+				"4-4 section after",
+			},
+		},
+		{
+			name:     "toml tables",
+			language: LangTOML,
+			opts:     Options{MaxTokens: 10},
+			src: `title = "x"
+
+[server]
+host = "localhost"
+port = 8080
+
+[[jobs]]
+name = "index"
+`,
+			want: []string{
+				"1-2 section title",
+				"3-4 section server.host",
+				"5-6 section server.port",
+				"7-8 section jobs",
+			},
+		},
+		{
+			name:     "json",
+			language: LangJSON,
+			opts:     Options{MaxTokens: 12},
+			src: `{
+  "name": "web",
+  "scripts": {
+    "build": "vite build",
+    "test": "vitest run"
+  },
+  "files": [{"path": "a"}]
+}
+`,
+			want: []string{
+				"1-2 section name",
+				"3-4 section scripts.build",
+				"5-6 section scripts.test",
+				"7-8 section files",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := NewStructuredChunker(tt.language, tt.opts).Chunk("config", []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkChunks(t, tt.src, chunks, tt.want)
+		})
+	}
+}
+
+func TestStructuredChunkerInvalidJSON(t *testing.T) {
+	if _, err := NewStructuredChunker(LangJSON, Options{}).Chunk("a.json", []byte(`{"a": [1, 2`)); err == nil {
+		t.Error("truncated JSON chunked without error")
+	}
+}
@@ -0,0 +1,49 @@
+package chunking
+
+// LineChunker splits files into overlapping windows of whole lines. It's the
+// fallback for languages without a structural chunker.
+type LineChunker struct {
+	opts Options
+}
+
+// NewLineChunker creates a line-window chunker
+func NewLineChunker(opts Options) *LineChunker {
+	return &LineChunker{opts: opts}
+}
+
+// Chunk returns windows of at most MaxTokens, each sharing OverlapLines
+// lines with the previous one
+func (c *LineChunker) Chunk(path string, src []byte) ([]Chunk, error) {
+	l := splitLines(src)
+	return windows(l, 1, l.count(), c.opts, func(start, end int) Chunk {
+		return Chunk{
+			Content:   l.join(start, end),
+			FilePath:  path,
+			StartLine: start,
+			EndLine:   end,
+			ChunkType: TypeBlock,
+		}
+	}), nil
+}
+
+// windows covers lines start..end with overlapping windows of at most
+// MaxTokens. A single line longer than the limit becomes its own window.
+func windows(l lines, start, end int, opts Options, chunk func(start, end int) Chunk) []Chunk {
+	maxBytes, overlap := opts.maxBytes(), opts.overlap()
+
+	var chunks []Chunk
+	for from := start; from <= end; {
+		to, size := from, len(l.text[from-1])+1
+		for to < end && size+len(l.text[to])+1 <= maxBytes {
+			size += len(l.text[to]) + 1
+			to++
+		}
+		chunks = append(chunks, chunk(from, to))
+		if to == end {
+			break
+		}
+		// Always advance, even if the window is shorter than the overlap
+		from = max(to-overlap+1, from+1)
+	}
+	return chunks
+}
@@ -0,0 +1,48 @@
+package chunking
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineChunker(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts Options
+		want []string
+	}{
+		{name: "fits", src: numbered(3), want: []string{"1-3 block"}},
+		{name: "no trailing newline", src: "a\nb", want: []string{"1-2 block"}},
+		{name: "empty", src: "", want: []string{"1-1 block"}},
+		// "line N" is 7 bytes with its newline, so 5 lines fit in 40 bytes
+		{
+			name: "overlapping windows",
+			src:  numbered(9),
+			opts: Options{MaxTokens: 10, OverlapLines: 2},
+			want: []string{"1-5 block", "4-8 block", "7-9 block"},
+		},
+		{
+			name: "no overlap",
+			src:  numbered(9),
+			opts: Options{MaxTokens: 10, OverlapLines: -1},
+			want: []string{"1-5 block", "6-9 block"},
+		},
+		{
+			// Windows still advance when the overlap is larger than a window
+			name: "long lines",
+			src:  strings.Repeat("x", 50) + "\n" + strings.Repeat("y", 50) + "\nz\n",
+			opts: Options{MaxTokens: 10, OverlapLines: 3},
+			want: []string{"1-1 block", "2-2 block", "3-3 block"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := NewLineChunker(tt.opts).Chunk("notes.txt", []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkChunks(t, tt.src, chunks, tt.want)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Package embeddings turns text into vectors for semantic search
+package embeddings
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDimensionMismatch is returned when an embedding has the wrong length for its model
+var ErrDimensionMismatch = errors.New("embedding dimension mismatch")
+
+// Embedder generates embeddings with a single model
+type Embedder interface {
+	// Model identifies the model; embeddings from different models are not comparable
+	Model() string
+	// Dimensions is the length of every vector Embed returns
+	Dimensions() int
+	// Embed returns one vector per input, in input order
+	Embed(ctx context.Context, inputs []string) ([][]float32, error)
+}
+
+// Stats is the usage of one or more Embed calls
+type Stats struct {
+	// Tokens is the number of tokens billed by the provider
+	Tokens int64
+	// CacheHits and CacheMisses count inputs served from and missing from a cache
+	CacheHits   int64
+	CacheMisses int64
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a context whose Embed calls report their usage to fn.
+// fn may be called concurrently.
+func WithRecorder(ctx context.Context, fn func(Stats)) context.Context {
+	return context.WithValue(ctx, recorderKey{}, fn)
+}
+
+// Record reports usage to the context's recorder, if any
+func Record(ctx context.Context, stats Stats) {
+	if fn, ok := ctx.Value(recorderKey{}).(func(Stats)); ok {
+		fn(stats)
+	}
+}
@@ -0,0 +1,160 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DefaultLocalDimensions is the vector size of the local embedder when none is configured
+const DefaultLocalDimensions = 384
+
+// Feature weights: whole words matter most, identifier parts less, and
+// character trigrams just enough to match spelling variants
+const (
+	wordWeight    = 1.0
+	subWeight     = 0.6
+	bigramWeight  = 0.5
+	trigramWeight = 0.2
+)
+
+// Local embeds text offline by feature hashing. Each input is tokenized into
+// words, identifiers are split into their camelCase and snake_case parts,
+// and words, parts, word bigrams and character trigrams are hashed into a
+// fixed number of signed buckets. The result is L2-normalized, so cosine
+// similarity reflects shared vocabulary.
+//
+// Vectors depend only on the input and the dimensions, never on the
+// machine or process, so they're stable across runs.
+type Local struct {
+	dims int
+}
+
+// NewLocal creates a local embedder producing vectors of the given size
+func NewLocal(dims int) *Local {
+	if dims <= 0 {
+		dims = DefaultLocalDimensions
+	}
+	return &Local{dims: dims}
+}
+
+// Model includes the dimensions, since vectors of different sizes can't be mixed
+func (e *Local) Model() string {
+	return fmt.Sprintf("local-hash-v1-%d", e.dims)
+}
+
+func (e *Local) Dimensions() int {
+	return e.dims
+}
+
+func (e *Local) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	vectors := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vectors[i] = e.embed(input)
+	}
+	return vectors, nil
+}
+
+func (e *Local) embed(text string) []float32 {
+	v := make([]float64, e.dims)
+	add := func(feature string, weight float64) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(feature))
+		sum := h.Sum64()
+		// The top bit picks the sign so collisions tend to cancel out
+		sign := 1.0
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		v[sum%uint64(e.dims)] += sign * weight
+	}
+
+	var prev string
+	for _, word := range words(text) {
+		lower := strings.ToLower(word)
+		add("w:"+lower, wordWeight)
+		if prev != "" {
+			add("b:"+prev+" "+lower, bigramWeight)
+		}
+		prev = lower
+
+		parts := splitIdentifier(word)
+		for _, part := range parts {
+			part = strings.ToLower(part)
+			if len(parts) > 1 {
+				add("s:"+part, subWeight)
+			}
+			for _, tri := range trigrams(part) {
+				add("c:"+tri, trigramWeight)
+			}
+		}
+	}
+
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+
+	out := make([]float32, e.dims)
+	if norm == 0 {
+		// Empty input still needs a unit vector for cosine similarity
+		out[0] = 1
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for i, x := range v {
+		out[i] = float32(x / norm)
+	}
+	return out
+}
+
+// words splits text on anything that isn't a letter, digit or underscore
+func words(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// splitIdentifier splits snake_case, camelCase and PascalCase identifiers,
+// keeping acronyms together: "HTTPServer_v2" -> HTTP, Server, v, 2
+func splitIdentifier(word string) []string {
+	var parts []string
+	for _, segment := range strings.Split(word, "_") {
+		runes := []rune(segment)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			boundary := unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+				unicode.IsLetter(prev) != unicode.IsLetter(cur) ||
+				// The last capital of an acronym starts the next word: HTTPServer
+				unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if boundary {
+				parts = append(parts, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, string(runes[start:]))
+		}
+	}
+	return parts
+}
+
+// trigrams returns the character trigrams of a word padded with boundary markers
+func trigrams(word string) []string {
+	runes := []rune("^" + word + "$")
+	if len(runes) < 3 {
+		return nil
+	}
+	out := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		out = append(out, string(runes[i:i+3]))
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package embeddings
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestLocalEmbed(t *testing.T) {
+	tests := []struct {
+		name  string
+		dims  int
+		input string
+	}{
+		{name: "code", dims: 384, input: "func (s *Server) Start() error { return s.listen() }"},
+		{name: "prose", dims: 256, input: "Repositories are re-indexed when their default branch moves."},
+		{name: "unicode", dims: 64, input: "größe := maß * 2"},
+		{name: "empty", dims: 32, input: ""},
+		{name: "punctuation only", dims: 32, input: "{}();"},
+		{name: "default size", dims: 0, input: "parseHTTPRequest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewLocal(tt.dims)
+			wantDims := tt.dims
+			if wantDims == 0 {
+				wantDims = DefaultLocalDimensions
+			}
+			if e.Dimensions() != wantDims {
+				t.Errorf("Dimensions() = %d, want %d", e.Dimensions(), wantDims)
+			}
+
+			vectors, err := e.Embed(context.Background(), []string{tt.input, tt.input})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(vectors[0]) != wantDims {
+				t.Errorf("vector has %d dimensions, want %d", len(vectors[0]), wantDims)
+			}
+			if !slices.Equal(vectors[0], vectors[1]) {
+				t.Error("identical inputs gave different vectors")
+			}
+			// A fresh embedder gives the same vector, as a restarted process would
+			again, _ := NewLocal(tt.dims).Embed(context.Background(), []string{tt.input})
+			if !slices.Equal(vectors[0], again[0]) {
+				t.Error("a new embedder gave a different vector")
+			}
+			if norm := math.Sqrt(cosine(vectors[0], vectors[0])); math.Abs(norm-1) > 1e-5 {
+				t.Errorf("norm = %f, want 1", norm)
+			}
+		})
+	}
+}
+
+func TestLocalSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		// min and max bound the cosine similarity
+		min, max float64
+	}{
+		{a: "parseHTTPRequest", b: "parse_http_request", min: 0.5, max: 1},
+		{a: "ParseHTTPRequest", b: "parseHttpRequest", min: 0.5, max: 1},
+		{a: "parseHTTPRequest", b: "renderTemplate", min: -0.2, max: 0.2},
+		{a: "open the vector store", b: "vector store opened", min: 0.3, max: 1},
+	}
+	e := NewLocal(DefaultLocalDimensions)
+	for _, tt := range tests {
+		vectors, err := e.Embed(context.Background(), []string{tt.a, tt.b})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sim := cosine(vectors[0], vectors[1]); sim < tt.min || sim > tt.max {
+			t.Errorf("similarity of %q and %q = %.3f, want between %.1f and %.1f", tt.a, tt.b, sim, tt.min, tt.max)
+		}
+	}
+}
+
+// TestLocalGolden pins the hashing, so a change that would invalidate stored
+// vectors without a new model name fails here
+func TestLocalGolden(t *testing.T) {
+	vectors, err := NewLocal(64).Embed(context.Background(), []string{"parseHTTPRequest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float32{0, -0.11952286, 0, 0, 0, 0, 0, 0.35856858, 0, 0, 0, -0.11952286, 0, 0, 0, 0}
+	for i, w := range want {
+		if math.Abs(float64(vectors[0][i]-w)) > 1e-6 {
+			t.Fatalf("vector prefix = %v, want %v", vectors[0][:len(want)], want)
+		}
+	}
+}
+
+func TestSplitIdentifier(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{word: "parseHTTPRequest", want: []string{"parse", "HTTP", "Request"}},
+		{word: "HTTPServer_v2", want: []string{"HTTP", "Server", "v", "2"}},
+		{word: "parse_http_request", want: []string{"parse", "http", "request"}},
+		{word: "ID", want: []string{"ID"}},
+		{word: "sha256Sum", want: []string{"sha", "256", "Sum"}},
+	}
+	for _, tt := range tests {
+		if got := splitIdentifier(tt.word); !slices.Equal(got, tt.want) {
+			t.Errorf("splitIdentifier(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func cosine(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
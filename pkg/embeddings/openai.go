@@ -0,0 +1,336 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DefaultBaseURL = "https://api.openai.com/v1"
+	DefaultModel   = "text-embedding-3-small"
+
+	// defaultMaxBatchInputs is the API's limit on inputs per request
+	defaultMaxBatchInputs = 2048
+	// defaultMaxBatchTokens is the API's limit on total tokens per request
+	defaultMaxBatchTokens = 300_000
+	// defaultMaxInputTokens is the context length of OpenAI's embedding models
+	defaultMaxInputTokens = 8191
+	defaultMaxRetries     = 5
+
+	// bytesPerToken underestimates real tokenizers on purpose, so inputs
+	// truncated to the limit by this estimate are accepted by the API
+	bytesPerToken = 3
+
+	minRetryDelay = 500 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
+// knownDimensions are the native output sizes of OpenAI's models
+var knownDimensions = map[string]int{
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+	"text-embedding-ada-002": 1536,
+}
+
+// OpenAIOptions configures an OpenAI-compatible embeddings client
+type OpenAIOptions struct {
+	APIKey string
+	// BaseURL is the API root, e.g. https://api.openai.com/v1; the client posts to {BaseURL}/embeddings
+	BaseURL string
+	Model   string
+	// Dimensions is required for models not known to the client. For OpenAI
+	// models that support shortened embeddings, a smaller value is requested
+	// from the API.
+	Dimensions int
+	// MaxBatchInputs and MaxBatchTokens bound each request
+	MaxBatchInputs int
+	MaxBatchTokens int
+	// MaxInputTokens truncates longer inputs
+	MaxInputTokens int
+	// MaxRetries is the number of retries after a 429, 5xx or network error
+	MaxRetries int
+	// HTTPClient defaults to a client with a 60 second timeout
+	HTTPClient *http.Client
+}
+
+// OpenAI calls the /embeddings endpoint of OpenAI or a compatible server
+type OpenAI struct {
+	opts           OpenAIOptions
+	dimensions     int
+	sendDimensions bool
+}
+
+// NewOpenAI creates a client, filling in defaults for unset options
+func NewOpenAI(opts OpenAIOptions) (*OpenAI, error) {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultBaseURL
+	}
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+	if opts.Model == "" {
+		opts.Model = DefaultModel
+	}
+	if opts.MaxBatchInputs <= 0 {
+		opts.MaxBatchInputs = defaultMaxBatchInputs
+	}
+	if opts.MaxBatchTokens <= 0 {
+		opts.MaxBatchTokens = defaultMaxBatchTokens
+	}
+	if opts.MaxInputTokens <= 0 {
+		opts.MaxInputTokens = defaultMaxInputTokens
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	} else if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	c := &OpenAI{opts: opts, dimensions: opts.Dimensions}
+	native, known := knownDimensions[opts.Model]
+	switch {
+	case known && opts.Dimensions <= 0:
+		c.dimensions = native
+	case known && opts.Dimensions != native:
+		c.sendDimensions = true
+	case !known && opts.Dimensions <= 0:
+		return nil, fmt.Errorf("dimensions must be configured for model %q", opts.Model)
+	}
+
+	return c, nil
+}
+
+func (c *OpenAI) Model() string {
+	return c.opts.Model
+}
+
+func (c *OpenAI) Dimensions() int {
+	return c.dimensions
+}
+
+// Embed embeds inputs in as few requests as the batch limits allow.
+// Inputs over MaxInputTokens are truncated.
+func (c *OpenAI) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(inputs))
+
+	for start := 0; start < len(inputs); {
+		end, tokens := start, 0
+		batch := make([]string, 0, min(len(inputs)-start, c.opts.MaxBatchInputs))
+		for end < len(inputs) && len(batch) < c.opts.MaxBatchInputs {
+			input := truncate(inputs[end], c.opts.MaxInputTokens)
+			n := estimateTokens(input)
+			if len(batch) > 0 && tokens+n > c.opts.MaxBatchTokens {
+				break
+			}
+			batch = append(batch, input)
+			tokens += n
+			end++
+		}
+
+		batchVectors, err := c.embedBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batchVectors...)
+		start = end
+	}
+
+	return vectors, nil
+}
+
+type embeddingRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	EncodingFormat string   `json:"encoding_format"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Usage struct {
+		TotalTokens int64 `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// APIError is a non-success response from the embeddings endpoint
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Type != "" {
+		return fmt.Sprintf("embeddings api: %d %s: %s", e.StatusCode, e.Type, e.Message)
+	}
+	return fmt.Sprintf("embeddings api: %d: %s", e.StatusCode, e.Message)
+}
+
+// Retryable reports whether the request may succeed if sent again
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+func (c *OpenAI) embedBatch(ctx context.Context, batch []string) ([][]float32, error) {
+	req := embeddingRequest{
+		Model:          c.opts.Model,
+		Input:          batch,
+		EncodingFormat: "float",
+	}
+	if c.sendDimensions {
+		req.Dimensions = c.dimensions
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *embeddingResponse
+	for attempt := 0; ; attempt++ {
+		var retryAfter time.Duration
+		resp, retryAfter, err = c.post(ctx, body)
+		if err == nil {
+			break
+		}
+
+		var apiErr *APIError
+		retryable := !errors.As(err, &apiErr) || apiErr.Retryable()
+		if !retryable || attempt >= c.opts.MaxRetries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		delay := max(retryAfter, backoff(attempt))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	if len(resp.Data) != len(batch) {
+		return nil, fmt.Errorf("embeddings api returned %d embeddings for %d inputs", len(resp.Data), len(batch))
+	}
+	sort.Slice(resp.Data, func(i, j int) bool {
+		return resp.Data[i].Index < resp.Data[j].Index
+	})
+
+	vectors := make([][]float32, len(batch))
+	for i, d := range resp.Data {
+		if len(d.Embedding) != c.dimensions {
+			return nil, fmt.Errorf("%w: model %s returned %d, expected %d", ErrDimensionMismatch, c.opts.Model, len(d.Embedding), c.dimensions)
+		}
+		vectors[i] = d.Embedding
+	}
+
+	Record(ctx, Stats{Tokens: resp.Usage.TotalTokens})
+	return vectors, nil
+}
+
+// post sends one request, returning the server's Retry-After hint on failure
+func (c *OpenAI) post(ctx context.Context, body []byte) (*embeddingResponse, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.opts.BaseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.opts.APIKey)
+	}
+
+	res, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, retryAfter(res.Header), apiError(res)
+	}
+
+	var out embeddingResponse
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode embeddings response: %w", err)
+	}
+	return &out, 0, nil
+}
+
+func apiError(res *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"error"`
+	}
+	apiErr := &APIError{StatusCode: res.StatusCode}
+	if json.Unmarshal(raw, &body) == nil && body.Error.Message != "" {
+		apiErr.Message, apiErr.Type = body.Error.Message, body.Error.Type
+	} else {
+		apiErr.Message = strings.TrimSpace(string(raw))
+	}
+	return apiErr
+}
+
+// retryAfter parses the retry-after-ms and Retry-After headers
+func retryAfter(h http.Header) time.Duration {
+	if ms, err := strconv.ParseFloat(h.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return min(time.Duration(ms*float64(time.Millisecond)), maxRetryDelay)
+	}
+
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil && secs > 0 {
+		return min(time.Duration(secs*float64(time.Second)), maxRetryDelay)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return min(max(time.Until(t), 0), maxRetryDelay)
+	}
+	return 0
+}
+
+// backoff doubles the delay with each attempt, with jitter so concurrent
+// workers don't retry in lockstep
+func backoff(attempt int) time.Duration {
+	delay := min(minRetryDelay<<min(attempt, 10), maxRetryDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func estimateTokens(s string) int {
+	return (len(s) + bytesPerToken - 1) / bytesPerToken
+}
+
+// truncate cuts s to about maxTokens, on a UTF-8 boundary
+func truncate(s string, maxTokens int) string {
+	limit := maxTokens * bytesPerToken
+	if len(s) <= limit {
+		return s
+	}
+	s = s[:limit]
+	// Drop a rune cut in half
+	if r, size := utf8.DecodeLastRuneInString(s); r == utf8.RuneError && size <= 1 {
+		i := len(s) - 1
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		s = s[:i]
+	}
+	return s
+}
@@ -0,0 +1,227 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// fakeAPI is an embeddings endpoint that records each request's inputs.
+// Each embedding holds its input's length and index, and the data is
+// returned in reverse order, as the API is free to do.
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests []embeddingRequest
+	times    []time.Time
+	// fail answers the nth request (from 0) with a status and headers
+	fail map[int]func(w http.ResponseWriter)
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req embeddingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	n := len(f.requests)
+	f.requests = append(f.requests, req)
+	f.times = append(f.times, time.Now())
+	fail := f.fail[n]
+	f.mu.Unlock()
+
+	if fail != nil {
+		fail(w)
+		return
+	}
+
+	var resp embeddingResponse
+	for i := len(req.Input) - 1; i >= 0; i-- {
+		resp.Data = append(resp.Data, struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}{Index: i, Embedding: []float32{float32(len(req.Input[i])), float32(i)}})
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (f *fakeAPI) batchSizes() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var sizes []int
+	for _, req := range f.requests {
+		sizes = append(sizes, len(req.Input))
+	}
+	return sizes
+}
+
+func newTestClient(t *testing.T, api *fakeAPI, opts OpenAIOptions) *OpenAI {
+	t.Helper()
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	opts.BaseURL = srv.URL + "/v1/"
+	if opts.Model == "" {
+		opts.Model, opts.Dimensions = "test-embedding", 2
+	}
+	c, err := NewOpenAI(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestOpenAIBatching(t *testing.T) {
+	// Seven inputs estimated at 1 to 7 tokens
+	inputs := make([]string, 7)
+	for i := range inputs {
+		inputs[i] = strings.Repeat("x", 3*(i+1))
+	}
+
+	tests := []struct {
+		name string
+		opts OpenAIOptions
+		want []int
+	}{
+		{name: "one request", want: []int{7}},
+		{name: "by count", opts: OpenAIOptions{MaxBatchInputs: 3}, want: []int{3, 3, 1}},
+		// 1+2+3+4 tokens fit in 10, then 5, then 6, then 7
+		{name: "by tokens", opts: OpenAIOptions{MaxBatchTokens: 10}, want: []int{4, 1, 1, 1}},
+		// An input over the token limit still goes alone rather than stalling
+		{name: "oversized input", opts: OpenAIOptions{MaxBatchTokens: 2}, want: []int{1, 1, 1, 1, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeAPI{}
+			c := newTestClient(t, api, tt.opts)
+
+			vectors, err := c.Embed(context.Background(), inputs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := api.batchSizes(); !slices.Equal(got, tt.want) {
+				t.Errorf("batch sizes = %v, want %v", got, tt.want)
+			}
+			// Vectors come back in input order, whatever order the API used
+			for i, v := range vectors {
+				if int(v[0]) != len(inputs[i]) {
+					t.Errorf("vector %d is for an input of %v bytes, want %d", i, v[0], len(inputs[i]))
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAITruncation(t *testing.T) {
+	api := &fakeAPI{}
+	c := newTestClient(t, api, OpenAIOptions{MaxInputTokens: 4})
+
+	// 12 bytes fit; the cut falls inside a two-byte rune, which is dropped
+	inputs := []string{"short", strings.Repeat("a", 20), "aaaaaaaaaaaé!"}
+	if _, err := c.Embed(context.Background(), inputs); err != nil {
+		t.Fatal(err)
+	}
+
+	got := api.requests[0].Input
+	want := []string{"short", strings.Repeat("a", 12), "aaaaaaaaaaa"}
+	if !slices.Equal(got, want) {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+	for _, s := range got {
+		if !utf8.ValidString(s) {
+			t.Errorf("sent invalid UTF-8 %q", s)
+		}
+	}
+}
+
+func TestOpenAIRetry(t *testing.T) {
+	status := func(code int, header ...string) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			for i := 0; i+1 < len(header); i += 2 {
+				w.Header().Set(header[i], header[i+1])
+			}
+			w.WriteHeader(code)
+			fmt.Fprintf(w, `{"error": {"message": "status %d", "type": "test"}}`, code)
+		}
+	}
+
+	t.Run("honours Retry-After", func(t *testing.T) {
+		api := &fakeAPI{fail: map[int]func(http.ResponseWriter){
+			0: status(http.StatusTooManyRequests, "Retry-After", "1"),
+			1: status(http.StatusBadGateway),
+		}}
+		c := newTestClient(t, api, OpenAIOptions{})
+
+		if _, err := c.Embed(context.Background(), []string{"a"}); err != nil {
+			t.Fatal(err)
+		}
+		if len(api.requests) != 3 {
+			t.Fatalf("sent %d requests, want 3", len(api.requests))
+		}
+		if wait := api.times[1].Sub(api.times[0]); wait < time.Second {
+			t.Errorf("retried after %s, want at least the 1s Retry-After", wait)
+		}
+	})
+
+	t.Run("gives up after MaxRetries", func(t *testing.T) {
+		api := &fakeAPI{fail: map[int]func(http.ResponseWriter){
+			0: status(http.StatusServiceUnavailable, "retry-after-ms", "1"),
+			1: status(http.StatusServiceUnavailable, "retry-after-ms", "1"),
+		}}
+		c := newTestClient(t, api, OpenAIOptions{MaxRetries: 1})
+
+		_, err := c.Embed(context.Background(), []string{"a"})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("err = %v, want a 503 APIError", err)
+		}
+		if len(api.requests) != 2 {
+			t.Errorf("sent %d requests, want 2", len(api.requests))
+		}
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		api := &fakeAPI{fail: map[int]func(http.ResponseWriter){0: status(http.StatusBadRequest)}}
+		c := newTestClient(t, api, OpenAIOptions{})
+
+		_, err := c.Embed(context.Background(), []string{"a"})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Type != "test" || apiErr.Message != "status 400" {
+			t.Fatalf("err = %v, want the decoded 400 APIError", err)
+		}
+		if len(api.requests) != 1 {
+			t.Errorf("sent %d requests, want 1", len(api.requests))
+		}
+	})
+}
+
+func TestOpenAIDimensions(t *testing.T) {
+	api := &fakeAPI{}
+	c := newTestClient(t, api, OpenAIOptions{Model: "text-embedding-3-small", Dimensions: 2})
+	if _, err := c.Embed(context.Background(), []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := api.requests[0].Dimensions; got != 2 {
+		t.Errorf("requested %d dimensions, want 2", got)
+	}
+
+	// The native size isn't requested, and a mismatched response fails
+	api = &fakeAPI{}
+	c = newTestClient(t, api, OpenAIOptions{Model: "text-embedding-3-small"})
+	if _, err := c.Embed(context.Background(), []string{"a"}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("err = %v, want ErrDimensionMismatch", err)
+	}
+	if got := api.requests[0].Dimensions; got != 0 {
+		t.Errorf("requested %d dimensions for the native size", got)
+	}
+}
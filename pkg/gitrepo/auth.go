@@ -0,0 +1,79 @@
+package gitrepo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gomantics/semantix/pkg/secrets"
+)
+
+// tokenUsernames are the usernames each provider expects alongside a token
+var tokenUsernames = map[string]string{
+	"github":    "x-access-token",
+	"gitlab":    "oauth2",
+	"bitbucket": "x-token-auth",
+}
+
+// userinfoPattern matches credentials embedded in URLs
+var userinfoPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/@\s]+@`)
+
+// basicAuth returns the token as the credentials of an HTTP basic
+// Authorization header
+func basicAuth(provider string, token secrets.Value) string {
+	username, ok := tokenUsernames[provider]
+	if !ok {
+		username = "oauth2"
+	}
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + token.Reveal()))
+}
+
+// authEnv returns the environment that has git send the token as HTTPS
+// basic auth to the remote's host only. Config from the environment stays
+// out of the command line, where any local user could read it. Non-HTTPS
+// remotes and empty tokens get none.
+func authEnv(remote, provider string, token secrets.Value) []string {
+	if token == "" {
+		return nil
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil
+	}
+
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.https://" + u.Host + "/.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + basicAuth(provider, token),
+	}
+}
+
+// checkTokenHost fails unless the token, if any, would only be sent to its
+// own host. Tokens are only sent to HTTPS remotes.
+func checkTokenHost(remote, host string, token secrets.Value) error {
+	if token == "" {
+		return nil
+	}
+	u, err := url.Parse(remote)
+	if err != nil || u.Scheme != "https" {
+		return nil
+	}
+	if host == "" || !strings.EqualFold(u.Host, host) {
+		return fmt.Errorf("%w: remote is on %s", ErrTokenHost, u.Host)
+	}
+	return nil
+}
+
+// sanitize removes the token, its encodings and any URL credentials from
+// git output
+func sanitize(s, provider string, token secrets.Value) string {
+	if token != "" {
+		s = strings.ReplaceAll(s, basicAuth(provider, token), "***")
+		s = strings.ReplaceAll(s, token.Reveal(), "***")
+		s = strings.ReplaceAll(s, url.QueryEscape(token.Reveal()), "***")
+	}
+	return userinfoPattern.ReplaceAllString(s, "${1}***@")
+}
@@ -0,0 +1,394 @@
+// Package gitrepo clones and updates git repositories using the git CLI.
+package gitrepo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomantics/semantix/pkg/secrets"
+)
+
+var (
+	ErrTimeout     = errors.New("git operation timed out")
+	ErrRepoTooBig  = errors.New("repository exceeds maximum size")
+	ErrInvalidPath = errors.New("local repository path must be absolute")
+	// ErrHistoryUnavailable means a commit isn't reachable in the local,
+	// possibly shallow, history
+	ErrHistoryUnavailable = errors.New("commit history not available locally")
+	ErrFileNotFound       = errors.New("file not found at commit")
+	// ErrNotAFile is returned for directories and submodules
+	ErrNotAFile     = errors.New("path is not a file")
+	ErrFileTooLarge = errors.New("file exceeds maximum size")
+	// ErrTokenHost means the token would be sent to a host other than its own
+	ErrTokenHost = errors.New("git token is not for this host")
+)
+
+// Options configures a clone or update
+type Options struct {
+	// URL is a remote URL, a file:// URL or an absolute local path
+	URL string
+	// Dir is the working copy directory
+	Dir string
+	// Branch to check out; empty means the remote's default branch
+	Branch string
+	// Provider selects the username sent with Token to HTTPS remotes
+	Provider string
+	// Token authenticates HTTPS remotes; it is never written to disk or
+	// passed on the command line
+	Token secrets.Value
+	// TokenHost is the host[:port] Token belongs to; Sync fails with
+	// ErrTokenHost rather than send it to an HTTPS remote on another host
+	TokenHost string
+	// Depth limits history; 0 fetches full history
+	Depth int
+	// Exclude lists gitignore-style patterns left out of the checkout
+	Exclude []string
+	// Timeout bounds the whole operation; 0 means no limit
+	Timeout time.Duration
+	// MaxSizeBytes bounds the on-disk size of the checkout; 0 means no limit
+	MaxSizeBytes int64
+}
+
+// Head describes the checked-out commit
+type Head struct {
+	Commit  string `json:"commit"`
+	Branch  string `json:"branch"`
+	Message string `json:"message"`
+}
+
+// Sync clones the repository into opts.Dir, or fetches and fast-forwards the
+// existing checkout to the remote branch, and returns the resulting HEAD.
+// Errors never contain the token.
+func Sync(ctx context.Context, opts Options) (*Head, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	remote, err := remoteURL(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTokenHost(remote, opts.TokenHost, opts.Token); err != nil {
+		return nil, err
+	}
+
+	g := &git{
+		token:    opts.Token,
+		provider: opts.Provider,
+		env:      authEnv(remote, opts.Provider, opts.Token),
+	}
+
+	fresh := !isCheckout(opts.Dir)
+	if fresh {
+		err = clone(ctx, g, opts, remote)
+	} else {
+		err = update(ctx, g, opts, remote)
+	}
+	if err != nil {
+		if fresh {
+			_ = os.RemoveAll(opts.Dir)
+		}
+		return nil, err
+	}
+
+	if opts.MaxSizeBytes > 0 {
+		size, err := dirSize(opts.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if size > opts.MaxSizeBytes {
+			_ = os.RemoveAll(opts.Dir)
+			return nil, fmt.Errorf("%w: %d bytes (limit %d)", ErrRepoTooBig, size, opts.MaxSizeBytes)
+		}
+	}
+
+	return ReadHead(ctx, opts.Dir)
+}
+
+// ReadHead returns the commit, branch and commit message checked out in dir
+func ReadHead(ctx context.Context, dir string) (*Head, error) {
+	g := &git{dir: dir}
+
+	commit, err := g.output(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	branch, err := g.output(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := g.output(ctx, "log", "-1", "--format=%B")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Head{Commit: commit, Branch: branch, Message: message}, nil
+}
+
+// CommitsBetween counts the commits in from..to.
+// Returns ErrHistoryUnavailable when from isn't an ancestor of to in the local
+// history, which is the norm for shallow checkouts.
+func CommitsBetween(ctx context.Context, dir, from, to string) (int, error) {
+	g := &git{dir: dir}
+
+	// --is-ancestor exits 1 for "no" and 128 for unknown commits; both mean we can't count
+	if err := g.run(ctx, "merge-base", "--is-ancestor", from, to); err != nil {
+		var gitErr *Error
+		if errors.As(err, &gitErr) {
+			return 0, ErrHistoryUnavailable
+		}
+		return 0, err
+	}
+
+	out, err := g.output(ctx, "rev-list", "--count", from+".."+to)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// ReadFile returns the content of path as committed in commit, reading the
+// object database rather than the working tree, so nothing outside the
+// repository can be reached. Symlinks return their target path.
+func ReadFile(ctx context.Context, dir, commit, path string, maxSize int64) ([]byte, error) {
+	if strings.ContainsAny(path, "\n\x00") {
+		return nil, ErrFileNotFound
+	}
+	g := &git{dir: dir}
+
+	// Object names go through stdin so a path can't be taken for an option
+	info, err := g.exec(ctx, commit+":"+path+"\n", "cat-file", "--batch-check")
+	if err != nil {
+		return nil, err
+	}
+	// "<sha> <type> <size>", or "<name> missing"
+	fields := strings.Fields(info)
+	if len(fields) != 3 {
+		return nil, ErrFileNotFound
+	}
+	if fields[1] != "blob" {
+		return nil, ErrNotAFile
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected cat-file output %q", info)
+	}
+	if maxSize > 0 && size > maxSize {
+		return nil, fmt.Errorf("%w (%d bytes)", ErrFileTooLarge, size)
+	}
+
+	out, err := g.exec(ctx, "", "cat-file", "blob", fields[0])
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+func clone(ctx context.Context, g *git, opts Options, remote string) error {
+	if err := os.MkdirAll(filepath.Dir(opts.Dir), 0o755); err != nil {
+		return err
+	}
+	// Leftovers from an interrupted clone would make git refuse the directory
+	if err := os.RemoveAll(opts.Dir); err != nil {
+		return err
+	}
+
+	args := []string{"clone", "--no-checkout", "--single-branch"}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", fmt.Sprint(opts.Depth))
+	}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	args = append(args, "--", remote, opts.Dir)
+
+	if err := g.run(ctx, args...); err != nil {
+		return err
+	}
+
+	g.dir = opts.Dir
+
+	if err := sparseCheckout(ctx, g, opts.Exclude); err != nil {
+		return err
+	}
+
+	return g.run(ctx, "checkout", "--force", "HEAD")
+}
+
+func update(ctx context.Context, g *git, opts Options, remote string) error {
+	g.dir = opts.Dir
+
+	branch := opts.Branch
+	if branch == "" {
+		current, err := g.output(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+		if err != nil {
+			return err
+		}
+		branch = current
+	}
+
+	args := []string{"fetch", "--force", "--no-tags"}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", fmt.Sprint(opts.Depth))
+	}
+	args = append(args, "--", remote, branch)
+
+	if err := g.run(ctx, args...); err != nil {
+		return err
+	}
+
+	if err := sparseCheckout(ctx, g, opts.Exclude); err != nil {
+		return err
+	}
+
+	// The checkout is ours and read-only, so move the branch to the fetched commit
+	// directly; shallow history means git can't always prove a fast-forward.
+	if err := g.run(ctx, "checkout", "--force", "-B", branch, "FETCH_HEAD"); err != nil {
+		return err
+	}
+
+	return g.run(ctx, "clean", "-ffdx")
+}
+
+// sparseCheckout excludes the given patterns from the working tree
+func sparseCheckout(ctx context.Context, g *git, exclude []string) error {
+	if len(exclude) == 0 {
+		return g.run(ctx, "sparse-checkout", "disable")
+	}
+
+	var patterns strings.Builder
+	patterns.WriteString("/*\n")
+	for _, p := range exclude {
+		patterns.WriteString("!" + p + "\n")
+	}
+
+	return g.runInput(ctx, patterns.String(), "sparse-checkout", "set", "--no-cone", "--stdin")
+}
+
+// remoteURL turns absolute local paths into file:// URLs so clone depth is honoured
+func remoteURL(raw string) (string, error) {
+	if strings.Contains(raw, "://") || !isLocalPath(raw) {
+		return raw, nil
+	}
+	if !filepath.IsAbs(raw) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPath, raw)
+	}
+	return "file://" + filepath.ToSlash(filepath.Clean(raw)), nil
+}
+
+// isLocalPath reports whether raw looks like a filesystem path rather than an scp-style remote
+func isLocalPath(raw string) bool {
+	return strings.HasPrefix(raw, "/") || strings.HasPrefix(raw, ".") || filepath.IsAbs(raw)
+}
+
+// isCheckout reports whether dir already holds a git working copy
+func isCheckout(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil && info.IsDir()
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// git runs git commands non-interactively, scrubbing the token from errors
+type git struct {
+	dir      string
+	token    secrets.Value
+	provider string
+	// env is added to the environment of every command
+	env []string
+}
+
+func (g *git) run(ctx context.Context, args ...string) error {
+	_, err := g.exec(ctx, "", args...)
+	return err
+}
+
+func (g *git) runInput(ctx context.Context, stdin string, args ...string) error {
+	_, err := g.exec(ctx, stdin, args...)
+	return err
+}
+
+func (g *git) output(ctx context.Context, args ...string) (string, error) {
+	out, err := g.exec(ctx, "", args...)
+	return strings.TrimSpace(out), err
+}
+
+func (g *git) exec(ctx context.Context, stdin string, args ...string) (string, error) {
+	// Disable credential helpers so a misconfigured host can't prompt or leak credentials
+	fullArgs := append([]string{"-c", "credential.helper=", "-c", "core.askPass="}, args...)
+
+	cmd := exec.CommandContext(ctx, "git", fullArgs...)
+	cmd.Dir = g.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ASKPASS=",
+		"SSH_ASKPASS=",
+		"GIT_SSH_COMMAND=ssh -o BatchMode=yes",
+		"LC_ALL=C",
+	)
+	cmd.Env = append(cmd.Env, g.env...)
+	cmd.WaitDelay = 5 * time.Second
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%w: git %s", ErrTimeout, args[0])
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", &Error{Command: args[0], Message: sanitize(msg, g.provider, g.token)}
+	}
+
+	return stdout.String(), nil
+}
+
+// Error is a failed git command with credentials removed from its output
+type Error struct {
+	Command string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("git %s: %s", e.Command, e.Message)
+}
@@ -0,0 +1,84 @@
+// Package glob matches slash-separated file paths against glob patterns
+package glob
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// ErrBadPattern is returned for malformed patterns
+var ErrBadPattern = errors.New("invalid glob pattern")
+
+// Validate reports whether pattern is well formed
+func Validate(pattern string) error {
+	if pattern == "" {
+		return ErrBadPattern
+	}
+	for _, seg := range strings.Split(pattern, "/") {
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return ErrBadPattern
+		}
+	}
+	return nil
+}
+
+// Match reports whether name matches pattern. Segments use path.Match
+// syntax, and a "**" segment matches any number of directories. A pattern
+// without a slash matches the base name, so "*.go" matches Go files at any
+// depth. A trailing slash matches everything under a directory. Malformed
+// patterns match nothing; check them with Validate.
+func Match(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	name = strings.TrimPrefix(name, "/")
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// MatchAny reports whether name matches at least one of patterns
+func MatchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if Match(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse runs of ** and try every split point
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
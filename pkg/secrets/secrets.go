@@ -0,0 +1,113 @@
+// Package secrets provides authenticated encryption for credentials stored at rest.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNoKeys     = errors.New("no encryption keys configured")
+	ErrUnknownKey = errors.New("unknown encryption key id")
+	ErrDecrypt    = errors.New("failed to decrypt secret")
+)
+
+// Keyring holds the AES-GCM keys available for decryption, one of which is
+// active and used for all new ciphertexts. Keeping retired keys in the ring
+// allows existing rows to be read while they are re-encrypted.
+type Keyring struct {
+	keys   map[string]cipher.AEAD
+	active string
+}
+
+// ParseKeyring parses a comma-separated list of id:base64key pairs.
+// Keys must decode to 16, 24 or 32 bytes (AES-128/192/256).
+func ParseKeyring(spec, activeID string) (*Keyring, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, ErrNoKeys
+	}
+
+	ring := &Keyring{keys: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.Split(spec, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" || encoded == "" {
+			return nil, fmt.Errorf("invalid key ring entry: expected id:base64key")
+		}
+		if _, exists := ring.keys[id]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64", id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+
+		ring.keys[id] = aead
+		if ring.active == "" {
+			ring.active = id
+		}
+	}
+
+	if activeID != "" {
+		if _, ok := ring.keys[activeID]; !ok {
+			return nil, fmt.Errorf("%w: active key %q is not in the key ring", ErrUnknownKey, activeID)
+		}
+		ring.active = activeID
+	}
+
+	return ring, nil
+}
+
+// ActiveKeyID returns the ID of the key used by Encrypt
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Encrypt seals plaintext with the active key, returning the key ID and
+// nonce-prefixed ciphertext. The additional data must be supplied again to decrypt.
+func (k *Keyring) Encrypt(plaintext, additionalData []byte) (string, []byte, error) {
+	aead := k.keys[k.active]
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return k.active, aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt with the named key
+func (k *Keyring) Decrypt(keyID string, sealed, additionalData []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,34 @@
+package secrets
+
+const redacted = "[REDACTED]"
+
+// Value is a plaintext secret that redacts itself when formatted, logged or
+// marshalled, so it can't leak through zap fields or error messages by accident.
+// Call Reveal to get the underlying string.
+type Value string
+
+// Reveal returns the plaintext secret
+func (v Value) Reveal() string {
+	return string(v)
+}
+
+// Hint returns the last four characters of the secret for display, or an empty
+// string if the secret is too short for that to be safe
+func (v Value) Hint() string {
+	if len(v) < 12 {
+		return ""
+	}
+	return string(v[len(v)-4:])
+}
+
+func (v Value) String() string {
+	return redacted
+}
+
+func (v Value) GoString() string {
+	return redacted
+}
+
+func (v Value) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
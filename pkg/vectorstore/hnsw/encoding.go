@@ -0,0 +1,158 @@
+package hnsw
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// errCorrupt is returned when persisted data fails its checksum or is malformed
+var errCorrupt = errors.New("corrupt vector store data")
+
+// maxField bounds lengths read from disk so corrupt data can't trigger huge allocations
+const maxField = 1 << 30
+
+// encoder appends the binary encoding shared by the WAL and snapshots
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+func (e *encoder) varint(v int64) {
+	e.buf = binary.AppendVarint(e.buf, v)
+}
+
+func (e *encoder) byte(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.uvarint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) floats(v []float32) {
+	e.uvarint(uint64(len(v)))
+	for _, x := range v {
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, math.Float32bits(x))
+	}
+}
+
+func (e *encoder) slots(v []uint32) {
+	e.uvarint(uint64(len(v)))
+	for _, x := range v {
+		e.uvarint(uint64(x))
+	}
+}
+
+// decoder reads the encoding written by encoder, remembering the first error
+type decoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		d.err = err
+	}
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		d.fail(err)
+	}
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	if err != nil {
+		d.fail(err)
+	}
+	return v
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	b, err := d.r.ReadByte()
+	if err != nil {
+		d.fail(err)
+	}
+	return b
+}
+
+func (d *decoder) length() int {
+	n := d.uvarint()
+	if n > maxField {
+		d.fail(errCorrupt)
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.length()
+	if d.err != nil {
+		return nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		d.fail(err)
+	}
+	return b
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes())
+}
+
+func (d *decoder) floats() []float32 {
+	n := d.length()
+	if d.err != nil {
+		return nil
+	}
+	raw := make([]byte, 4*n)
+	if _, err := io.ReadFull(d.r, raw); err != nil {
+		d.fail(err)
+		return nil
+	}
+	v := make([]float32, n)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[4*i:]))
+	}
+	return v
+}
+
+func (d *decoder) slots() []uint32 {
+	n := d.length()
+	if d.err != nil {
+		return nil
+	}
+	v := make([]uint32, n)
+	for i := range v {
+		v[i] = uint32(d.uvarint())
+	}
+	return v
+}
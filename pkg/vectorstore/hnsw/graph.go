@@ -0,0 +1,412 @@
+package hnsw
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/gomantics/semantix/pkg/vectorstore"
+)
+
+// node is one point in the graph. Vectors are normalized on insert, so the
+// dot product is the cosine similarity.
+type node struct {
+	id      string
+	vec     []float32
+	payload vectorstore.Payload
+	// friends holds the neighbour slots of each level, 0 to the node's level
+	friends [][]uint32
+	deleted bool
+}
+
+func (n *node) level() int {
+	return len(n.friends) - 1
+}
+
+// graph is a hierarchical navigable small world graph (Malkov & Yashunin).
+// Deleted nodes stay in the graph as tombstones, still routing searches,
+// until repair reconnects their neighbours and frees their slots.
+type graph struct {
+	nodes []*node // indexed by slot; nil slots are free
+	free  []uint32
+	entry int // slot of the entry point, -1 when empty
+	top   int // level of the entry point
+
+	m              int
+	efConstruction int
+	levelMult      float64
+	rng            *rand.Rand
+
+	// visitedPool recycles visited sets across searches, which run concurrently
+	visitedPool sync.Pool
+}
+
+func newGraph(m, efConstruction int) *graph {
+	return &graph{
+		entry:          -1,
+		m:              m,
+		efConstruction: efConstruction,
+		levelMult:      1 / math.Log(float64(m)),
+		rng:            rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// visited returns an empty visited set sized for the graph
+func (g *graph) visited() *visitedSet {
+	v, _ := g.visitedPool.Get().(*visitedSet)
+	if v == nil {
+		v = &visitedSet{}
+	}
+	v.reset(len(g.nodes))
+	return v
+}
+
+// maxFriends is the neighbour limit of a level; level 0 is twice as dense
+func (g *graph) maxFriends(level int) int {
+	if level == 0 {
+		return 2 * g.m
+	}
+	return g.m
+}
+
+func (g *graph) randomLevel() int {
+	return int(math.Floor(-math.Log(1-g.rng.Float64()) * g.levelMult))
+}
+
+// insert adds n to the graph and returns its slot. n.friends must be nil.
+func (g *graph) insert(n *node) uint32 {
+	level := g.randomLevel()
+	n.friends = make([][]uint32, level+1)
+	slot := g.alloc(n)
+
+	if g.entry < 0 {
+		g.entry, g.top = int(slot), level
+		return slot
+	}
+
+	ep := []uint32{uint32(g.entry)}
+	for l := g.top; l > level; l-- {
+		ep = g.searchLayer(n.vec, ep, 1, l, nil).slots()
+	}
+
+	for l := min(level, g.top); l >= 0; l-- {
+		found := g.searchLayer(n.vec, ep, g.efConstruction, l, nil)
+		ep = found.slots()
+
+		n.friends[l] = g.selectNeighbours(found.sorted(), g.m)
+		for _, f := range n.friends[l] {
+			g.connect(f, slot, l)
+		}
+	}
+
+	if level > g.top {
+		g.entry, g.top = int(slot), level
+	}
+	return slot
+}
+
+func (g *graph) alloc(n *node) uint32 {
+	if len(g.free) > 0 {
+		slot := g.free[len(g.free)-1]
+		g.free = g.free[:len(g.free)-1]
+		g.nodes[slot] = n
+		return slot
+	}
+	g.nodes = append(g.nodes, n)
+	return uint32(len(g.nodes) - 1)
+}
+
+// connect adds an edge from one node to another, pruning the neighbour list
+// with the selection heuristic when it's full
+func (g *graph) connect(from, to uint32, level int) {
+	n := g.nodes[from]
+	n.friends[level] = append(n.friends[level], to)
+	if len(n.friends[level]) <= g.maxFriends(level) {
+		return
+	}
+
+	candidates := make([]candidate, len(n.friends[level]))
+	for i, f := range n.friends[level] {
+		candidates[i] = candidate{slot: f, dist: distance(n.vec, g.nodes[f].vec)}
+	}
+	slices.SortFunc(candidates, compareCandidates)
+	n.friends[level] = g.selectNeighbours(candidates, g.maxFriends(level))
+}
+
+// selectNeighbours picks up to m of the candidates, sorted nearest first,
+// preferring ones that aren't closer to an already selected neighbour than
+// to the base node, so edges span clusters. Remaining slots are filled with
+// the nearest discarded candidates.
+func (g *graph) selectNeighbours(candidates []candidate, m int) []uint32 {
+	selected := make([]uint32, 0, m)
+	var discarded []uint32
+
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		good := true
+		for _, s := range selected {
+			if distance(g.nodes[c.slot].vec, g.nodes[s].vec) < c.dist {
+				good = false
+				break
+			}
+		}
+		if good {
+			selected = append(selected, c.slot)
+		} else {
+			discarded = append(discarded, c.slot)
+		}
+	}
+
+	for _, d := range discarded {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, d)
+	}
+	return selected
+}
+
+// searchLayer returns up to ef nodes nearest to q on one level, starting
+// from the entry points. When accept is set, only accepted nodes are
+// returned, though every node is still traversed.
+func (g *graph) searchLayer(q []float32, entry []uint32, ef, level int, accept func(*node) bool) *results {
+	visited := g.visited()
+	defer g.visitedPool.Put(visited)
+	cands := &minHeap{}
+	found := &results{limit: ef}
+
+	for _, ep := range entry {
+		visited.visit(ep)
+		c := candidate{slot: ep, dist: distance(q, g.nodes[ep].vec)}
+		heap.Push(cands, c)
+		if accept == nil || accept(g.nodes[ep]) {
+			found.push(c)
+		}
+	}
+
+	for cands.Len() > 0 {
+		c := heap.Pop(cands).(candidate)
+		if found.full() && c.dist > found.worst() {
+			break
+		}
+
+		for _, f := range g.nodes[c.slot].friends[level] {
+			if !visited.visit(f) {
+				continue
+			}
+
+			d := distance(q, g.nodes[f].vec)
+			if found.full() && d >= found.worst() {
+				continue
+			}
+			heap.Push(cands, candidate{slot: f, dist: d})
+			if accept == nil || accept(g.nodes[f]) {
+				found.push(candidate{slot: f, dist: d})
+			}
+		}
+	}
+
+	return found
+}
+
+// search returns up to ef accepted nodes nearest to q
+func (g *graph) search(q []float32, ef int, accept func(*node) bool) []candidate {
+	if g.entry < 0 {
+		return nil
+	}
+
+	ep := []uint32{uint32(g.entry)}
+	for l := g.top; l > 0; l-- {
+		ep = g.searchLayer(q, ep, 1, l, nil).slots()
+	}
+	return g.searchLayer(q, ep, ef, 0, accept).sorted()
+}
+
+// repair removes tombstones: every edge to a deleted node is replaced with
+// the best of the node's remaining neighbours and the deleted node's own
+// neighbours, then the deleted slots are freed
+func (g *graph) repair() {
+	for slot, n := range g.nodes {
+		if n == nil || n.deleted {
+			continue
+		}
+		for l, friends := range n.friends {
+			if !slices.ContainsFunc(friends, func(f uint32) bool { return g.nodes[f].deleted }) {
+				continue
+			}
+
+			seen := map[uint32]struct{}{uint32(slot): {}}
+			var candidates []candidate
+			add := func(f uint32) {
+				if _, ok := seen[f]; ok || g.nodes[f].deleted {
+					return
+				}
+				seen[f] = struct{}{}
+				candidates = append(candidates, candidate{slot: f, dist: distance(n.vec, g.nodes[f].vec)})
+			}
+			for _, f := range friends {
+				if g.nodes[f].deleted {
+					for _, ff := range g.nodes[f].friends[l] {
+						add(ff)
+					}
+				} else {
+					add(f)
+				}
+			}
+
+			slices.SortFunc(candidates, compareCandidates)
+			n.friends[l] = g.selectNeighbours(candidates, g.maxFriends(l))
+		}
+	}
+
+	// Only deleted nodes can still point at deleted nodes, so they can all go
+	for slot, n := range g.nodes {
+		if n != nil && n.deleted {
+			g.nodes[slot] = nil
+			g.free = append(g.free, uint32(slot))
+		}
+	}
+
+	if g.entry >= 0 && g.nodes[g.entry] == nil {
+		g.entry, g.top = -1, 0
+		for slot, n := range g.nodes {
+			if n != nil && (g.entry < 0 || n.level() > g.top) {
+				g.entry, g.top = slot, n.level()
+			}
+		}
+	}
+}
+
+// distance is the cosine distance between normalized vectors
+func distance(a, b []float32) float32 {
+	return 1 - dot(a, b)
+}
+
+func dot(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// normalize scales v to unit length in place; zero vectors are left alone
+func normalize(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	inv := float32(1 / math.Sqrt(sum))
+	for i := range v {
+		v[i] *= inv
+	}
+}
+
+// visitedSet marks slots with a generation number, so clearing it between
+// searches is free
+type visitedSet struct {
+	marks []uint32
+	gen   uint32
+}
+
+func (v *visitedSet) reset(size int) {
+	v.gen++
+	if v.gen == 0 || len(v.marks) < size {
+		v.marks = make([]uint32, max(size, 2*len(v.marks)))
+		v.gen = 1
+	}
+}
+
+// visit marks a slot, reporting whether it was unvisited
+func (v *visitedSet) visit(slot uint32) bool {
+	if v.marks[slot] == v.gen {
+		return false
+	}
+	v.marks[slot] = v.gen
+	return true
+}
+
+type candidate struct {
+	slot uint32
+	dist float32
+}
+
+func compareCandidates(a, b candidate) int {
+	switch {
+	case a.dist < b.dist:
+		return -1
+	case a.dist > b.dist:
+		return 1
+	}
+	return 0
+}
+
+// minHeap pops the nearest candidate first
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// maxHeap pops the farthest candidate first
+type maxHeap struct{ minHeap }
+
+func (h maxHeap) Less(i, j int) bool { return h.minHeap[i].dist > h.minHeap[j].dist }
+
+// results keeps the nearest limit candidates seen
+type results struct {
+	h     maxHeap
+	limit int
+}
+
+func (r *results) push(c candidate) {
+	heap.Push(&r.h, c)
+	if r.h.Len() > r.limit {
+		heap.Pop(&r.h)
+	}
+}
+
+func (r *results) full() bool {
+	return r.h.Len() >= r.limit
+}
+
+func (r *results) worst() float32 {
+	return r.h.minHeap[0].dist
+}
+
+func (r *results) slots() []uint32 {
+	out := make([]uint32, len(r.h.minHeap))
+	for i, c := range r.h.minHeap {
+		out[i] = c.slot
+	}
+	return out
+}
+
+// sorted returns the candidates nearest first
+func (r *results) sorted() []candidate {
+	out := slices.Clone(r.h.minHeap)
+	slices.SortFunc(out, compareCandidates)
+	return out
+}
@@ -0,0 +1,516 @@
+// Package hnsw implements vectorstore.Store in process, with an HNSW graph
+// held in memory and persisted under a data directory.
+//
+// Every mutation is appended to a write-ahead log and synced before it's
+// applied, so acknowledged writes survive a crash. Once the log grows past
+// a threshold the whole store is written to a snapshot and the log starts
+// over. Only one process may open a data directory at a time.
+package hnsw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/gomantics/semantix/pkg/vectorstore"
+)
+
+const (
+	DefaultM              = 16
+	DefaultEfConstruction = 100
+	DefaultEfSearch       = 100
+	DefaultSnapshotBytes  = 64 << 20
+
+	// defaultBruteForceLimit is the candidate count below which filtered
+	// searches scan exactly instead of walking the graph
+	defaultBruteForceLimit = 20_000
+
+	// repairRatio is the fraction of tombstones that triggers a graph repair
+	// when snapshotting
+	repairRatio = 0.1
+)
+
+var (
+	// ErrClosed is returned by every method after Close
+	ErrClosed = errors.New("vector store is closed")
+	// ErrNoCollection is returned by writes and searches before EnsureCollection
+	ErrNoCollection = errors.New("vector collection not created")
+)
+
+// Options configures an embedded store
+type Options struct {
+	// Dir holds the snapshot and write-ahead log; it's created if missing
+	Dir string
+	// M is the number of neighbours per node and level (twice that on level 0)
+	M int
+	// EfConstruction is the candidate list size when inserting
+	EfConstruction int
+	// EfSearch is the minimum candidate list size when searching; higher is
+	// more accurate and slower
+	EfSearch int
+	// SnapshotBytes is the log size that triggers a snapshot
+	SnapshotBytes int64
+	// BruteForceLimit is the number of filter candidates below which
+	// searches compare every candidate instead of walking the graph
+	BruteForceLimit int
+}
+
+// Store is a vectorstore.Store kept in memory and persisted to Options.Dir.
+// Only cosine distance is supported.
+type Store struct {
+	opts Options
+
+	mu         sync.RWMutex
+	g          *graph
+	dimensions int
+	seq        uint64
+	ids        map[string]uint32
+	// Payload indexes of live nodes, used to narrow filtered searches
+	byWorkspace map[int64]map[uint32]struct{}
+	byRepo      map[int64]map[uint32]struct{}
+	byFile      map[int64]map[uint32]struct{}
+	live        int
+	deleted     int
+
+	wal *wal
+	// snapshotErr is the last snapshot failure; the log keeps every write meanwhile
+	snapshotErr error
+	closed      bool
+}
+
+var _ vectorstore.Store = (*Store)(nil)
+
+// Open loads the store in opts.Dir, replaying writes logged since the last
+// snapshot
+func Open(opts Options) (*Store, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("data directory is required")
+	}
+	if opts.M <= 1 {
+		opts.M = DefaultM
+	}
+	if opts.EfConstruction <= 0 {
+		opts.EfConstruction = DefaultEfConstruction
+	}
+	if opts.EfSearch <= 0 {
+		opts.EfSearch = DefaultEfSearch
+	}
+	if opts.SnapshotBytes <= 0 {
+		opts.SnapshotBytes = DefaultSnapshotBytes
+	}
+	if opts.BruteForceLimit <= 0 {
+		opts.BruteForceLimit = defaultBruteForceLimit
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	snap, err := readSnapshot(opts.Dir, newGraph(opts.M, opts.EfConstruction))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		opts:        opts,
+		g:           snap.g,
+		dimensions:  snap.dimensions,
+		seq:         snap.seq,
+		ids:         make(map[string]uint32),
+		byWorkspace: make(map[int64]map[uint32]struct{}),
+		byRepo:      make(map[int64]map[uint32]struct{}),
+		byFile:      make(map[int64]map[uint32]struct{}),
+	}
+	for slot, n := range s.g.nodes {
+		switch {
+		case n == nil:
+		case n.deleted:
+			s.deleted++
+		default:
+			s.track(uint32(slot), n)
+		}
+	}
+
+	s.wal, err = openWAL(filepath.Join(opts.Dir, walFile))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.wal.replay(func(body []byte) error {
+		r, err := decodeRecord(body)
+		if err != nil {
+			return err
+		}
+		// Records up to the snapshot's are already in it
+		if r.seq > s.seq {
+			s.apply(r)
+		}
+		return nil
+	})
+	if err != nil {
+		_ = s.wal.close()
+		return nil, err
+	}
+
+	s.maybeSnapshot()
+	return s, nil
+}
+
+func (s *Store) EnsureCollection(ctx context.Context, dimensions int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.usable(); err != nil {
+		return err
+	}
+	if dimensions <= 0 {
+		return fmt.Errorf("invalid vector size %d", dimensions)
+	}
+	if s.dimensions == dimensions {
+		return nil
+	}
+	// An empty store can switch models
+	if s.dimensions != 0 && s.live+s.deleted > 0 {
+		return fmt.Errorf("%w: store has %d dimensions, embedder has %d",
+			vectorstore.ErrDimensionMismatch, s.dimensions, dimensions)
+	}
+
+	return s.write(record{op: opDimensions, dimensions: dimensions})
+}
+
+func (s *Store) Upsert(ctx context.Context, points []vectorstore.Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.usable(); err != nil {
+		return err
+	}
+	if s.dimensions == 0 {
+		return ErrNoCollection
+	}
+	for _, p := range points {
+		if len(p.Vector) != s.dimensions {
+			return fmt.Errorf("%w: point %s has %d dimensions, store has %d",
+				vectorstore.ErrDimensionMismatch, p.ID, len(p.Vector), s.dimensions)
+		}
+	}
+
+	return s.write(record{op: opUpsert, points: points})
+}
+
+func (s *Store) DeleteByFilter(ctx context.Context, filter vectorstore.Filter) error {
+	if filter.IsEmpty() {
+		return vectorstore.ErrEmptyFilter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.usable(); err != nil {
+		return err
+	}
+	if len(s.matching(filter)) == 0 {
+		return nil
+	}
+
+	return s.write(record{op: opDelete, filter: filter})
+}
+
+func (s *Store) Search(ctx context.Context, params vectorstore.SearchParams) ([]vectorstore.Result, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	if s.dimensions == 0 {
+		return nil, ErrNoCollection
+	}
+	if len(params.Vector) != s.dimensions {
+		return nil, fmt.Errorf("%w: query has %d dimensions, store has %d",
+			vectorstore.ErrDimensionMismatch, len(params.Vector), s.dimensions)
+	}
+
+	q := slices.Clone(params.Vector)
+	normalize(q)
+
+	k := max(params.Limit, 1) + max(params.Offset, 0)
+	accept := func(n *node) bool {
+		return !n.deleted && matches(params.Filter, n.payload)
+	}
+
+	var found []candidate
+	if slots, ok := s.candidates(params.Filter); ok && len(slots) <= s.opts.BruteForceLimit {
+		// Exact search over a small filtered set beats a graph walk that
+		// mostly visits other tenants' nodes
+		top := &results{limit: k}
+		for _, slot := range slots {
+			if n := s.g.nodes[slot]; accept(n) {
+				top.push(candidate{slot: slot, dist: distance(q, n.vec)})
+			}
+		}
+		found = top.sorted()
+	} else {
+		found = s.g.search(q, max(s.opts.EfSearch, k), accept)
+	}
+
+	if params.Offset >= len(found) {
+		return nil, nil
+	}
+	found = found[max(params.Offset, 0):min(k, len(found))]
+
+	out := make([]vectorstore.Result, 0, len(found))
+	for _, c := range found {
+		score := 1 - c.dist
+		if params.MinScore > 0 && score < params.MinScore {
+			break
+		}
+		n := s.g.nodes[c.slot]
+		out = append(out, vectorstore.Result{ID: n.id, Score: score, Payload: n.payload})
+	}
+	return out, nil
+}
+
+func (s *Store) Count(ctx context.Context, filter vectorstore.Filter) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.usable(); err != nil {
+		return 0, err
+	}
+	if filter.IsEmpty() {
+		return int64(s.live), nil
+	}
+	return int64(len(s.matching(filter))), nil
+}
+
+// Health reports a closed store or a failing snapshot
+func (s *Store) Health(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.usable(); err != nil {
+		return err
+	}
+	if s.snapshotErr != nil {
+		return fmt.Errorf("snapshot failed: %w", s.snapshotErr)
+	}
+	return nil
+}
+
+// Close snapshots the store, so the next Open doesn't replay the log, and
+// releases the data directory
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var err error
+	if s.wal.size > 0 {
+		err = s.snapshot()
+	}
+	return errors.Join(err, s.wal.close())
+}
+
+func (s *Store) usable() error {
+	if s.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// write logs a record and applies it. Callers hold the write lock.
+func (s *Store) write(r record) error {
+	r.seq = s.seq + 1
+	body, err := encodeRecord(r)
+	if err != nil {
+		return err
+	}
+	if err := s.wal.append(body); err != nil {
+		return fmt.Errorf("failed to write vector log: %w", err)
+	}
+
+	s.apply(r)
+	s.maybeSnapshot()
+	return nil
+}
+
+// apply performs a logged mutation in memory
+func (s *Store) apply(r record) {
+	s.seq = r.seq
+
+	switch r.op {
+	case opDimensions:
+		s.dimensions = r.dimensions
+	case opUpsert:
+		for _, p := range r.points {
+			s.upsert(p)
+		}
+	case opDelete:
+		for _, slot := range s.matching(r.filter) {
+			s.remove(slot)
+		}
+	}
+}
+
+func (s *Store) upsert(p vectorstore.Point) {
+	vec := slices.Clone(p.Vector)
+	normalize(vec)
+
+	if slot, ok := s.ids[p.ID]; ok {
+		// Re-indexing unchanged chunks shouldn't leave tombstones behind
+		if n := s.g.nodes[slot]; n.payload == p.Payload && slices.Equal(n.vec, vec) {
+			return
+		}
+		s.remove(slot)
+	}
+
+	n := &node{id: p.ID, vec: vec, payload: p.Payload}
+	s.track(s.g.insert(n), n)
+}
+
+// remove turns a live node into a tombstone
+func (s *Store) remove(slot uint32) {
+	n := s.g.nodes[slot]
+	n.deleted = true
+	delete(s.ids, n.id)
+	untrack(s.byWorkspace, n.payload.WorkspaceID, slot)
+	untrack(s.byRepo, n.payload.RepoID, slot)
+	untrack(s.byFile, n.payload.FileID, slot)
+	s.live--
+	s.deleted++
+}
+
+// track adds a live node to the ID map and payload indexes
+func (s *Store) track(slot uint32, n *node) {
+	s.ids[n.id] = slot
+	addTo(s.byWorkspace, n.payload.WorkspaceID, slot)
+	addTo(s.byRepo, n.payload.RepoID, slot)
+	addTo(s.byFile, n.payload.FileID, slot)
+	s.live++
+}
+
+// candidates narrows a filter to the live slots of its most selective
+// indexed condition. ok is false when no indexed condition applies.
+func (s *Store) candidates(f vectorstore.Filter) (slots []uint32, ok bool) {
+	var best []map[uint32]struct{}
+	bestSize := -1
+	consider := func(sets []map[uint32]struct{}) {
+		size := 0
+		for _, set := range sets {
+			size += len(set)
+		}
+		if bestSize < 0 || size < bestSize {
+			best, bestSize = sets, size
+		}
+	}
+
+	if f.WorkspaceID != 0 {
+		consider([]map[uint32]struct{}{s.byWorkspace[f.WorkspaceID]})
+	}
+	if len(f.RepoIDs) > 0 {
+		consider(lookup(s.byRepo, f.RepoIDs))
+	}
+	if len(f.FileIDs) > 0 {
+		consider(lookup(s.byFile, f.FileIDs))
+	}
+	if bestSize < 0 {
+		return nil, false
+	}
+
+	// Sets of distinct IDs are disjoint, so no deduplication is needed
+	slots = make([]uint32, 0, bestSize)
+	for _, set := range best {
+		for slot := range set {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, true
+}
+
+// matching returns the slots of live nodes matching the filter
+func (s *Store) matching(f vectorstore.Filter) []uint32 {
+	slots, ok := s.candidates(f)
+	if !ok {
+		for slot, n := range s.g.nodes {
+			if n != nil && !n.deleted {
+				slots = append(slots, uint32(slot))
+			}
+		}
+	}
+
+	return slices.DeleteFunc(slots, func(slot uint32) bool {
+		return !matches(f, s.g.nodes[slot].payload)
+	})
+}
+
+// maybeSnapshot snapshots once the log is large enough. Failures are kept
+// for Health; the log still holds every write, so nothing is lost.
+func (s *Store) maybeSnapshot() {
+	if s.wal.size < s.opts.SnapshotBytes {
+		return
+	}
+	s.snapshotErr = s.snapshot()
+}
+
+func (s *Store) snapshot() error {
+	if s.deleted > 0 && float64(s.deleted) >= repairRatio*float64(s.live+s.deleted) {
+		s.g.repair()
+		s.deleted = 0
+	}
+
+	err := writeSnapshot(s.opts.Dir, snapshot{seq: s.seq, dimensions: s.dimensions, g: s.g})
+	if err != nil {
+		return err
+	}
+	return s.wal.truncate(0)
+}
+
+func matches(f vectorstore.Filter, p vectorstore.Payload) bool {
+	return (f.WorkspaceID == 0 || f.WorkspaceID == p.WorkspaceID) &&
+		(len(f.RepoIDs) == 0 || slices.Contains(f.RepoIDs, p.RepoID)) &&
+		(len(f.FileIDs) == 0 || slices.Contains(f.FileIDs, p.FileID)) &&
+		(len(f.FilePaths) == 0 || slices.Contains(f.FilePaths, p.FilePath)) &&
+		(len(f.Languages) == 0 || slices.Contains(f.Languages, p.Language)) &&
+		(len(f.ChunkTypes) == 0 || slices.Contains(f.ChunkTypes, p.ChunkType))
+}
+
+func lookup(index map[int64]map[uint32]struct{}, keys []int64) []map[uint32]struct{} {
+	sets := make([]map[uint32]struct{}, 0, len(keys))
+	for _, k := range slices.Compact(slices.Sorted(slices.Values(keys))) {
+		if set, ok := index[k]; ok {
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
+
+func addTo(index map[int64]map[uint32]struct{}, key int64, slot uint32) {
+	set, ok := index[key]
+	if !ok {
+		set = make(map[uint32]struct{})
+		index[key] = set
+	}
+	set[slot] = struct{}{}
+}
+
+func untrack(index map[int64]map[uint32]struct{}, key int64, slot uint32) {
+	if set, ok := index[key]; ok {
+		delete(set, slot)
+		if len(set) == 0 {
+			delete(index, key)
+		}
+	}
+}
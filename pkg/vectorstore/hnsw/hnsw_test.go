@@ -0,0 +1,353 @@
+package hnsw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/gomantics/semantix/pkg/vectorstore"
+)
+
+const testDimensions = 32
+
+// randomPoints returns n points spread over two workspaces, with ten chunks
+// per file
+func randomPoints(rng *rand.Rand, n int) []vectorstore.Point {
+	points := make([]vectorstore.Point, n)
+	for i := range points {
+		vec := make([]float32, testDimensions)
+		for j := range vec {
+			vec[j] = rng.Float32()*2 - 1
+		}
+		points[i] = vectorstore.Point{
+			ID:     fmt.Sprintf("p%d", i),
+			Vector: vec,
+			Payload: vectorstore.Payload{
+				WorkspaceID: int64(i%2 + 1),
+				RepoID:      int64(i%2 + 1),
+				FileID:      int64(i / 10),
+				ChunkIndex:  i % 10,
+			},
+		}
+	}
+	return points
+}
+
+func openStore(t *testing.T, opts Options) *Store {
+	t.Helper()
+	s, err := Open(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.EnsureCollection(context.Background(), testDimensions); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// crash drops the store without the snapshot Close takes, as a killed
+// process would
+func crash(s *Store) {
+	_ = s.wal.close()
+}
+
+// exactTop returns the IDs of the k points nearest to q among those accept
+// keeps
+func exactTop(points []vectorstore.Point, q []float32, k int, accept func(vectorstore.Point) bool) []string {
+	qn := slices.Clone(q)
+	normalize(qn)
+	type scored struct {
+		id   string
+		dist float32
+	}
+	var all []scored
+	for _, p := range points {
+		if !accept(p) {
+			continue
+		}
+		v := slices.Clone(p.Vector)
+		normalize(v)
+		all = append(all, scored{p.ID, distance(qn, v)})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].dist < all[j].dist })
+	ids := make([]string, 0, k)
+	for _, s := range all[:min(k, len(all))] {
+		ids = append(ids, s.id)
+	}
+	return ids
+}
+
+func resultIDs(results []vectorstore.Result) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func search(t *testing.T, s *Store, params vectorstore.SearchParams) []string {
+	t.Helper()
+	results, err := s.Search(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resultIDs(results)
+}
+
+func TestSearchRecall(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	points := randomPoints(rng, 2000)
+	s := openStore(t, Options{Dir: t.TempDir()})
+	defer s.Close()
+	if err := s.Upsert(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+
+	const k, queries = 10, 50
+	var found int
+	for range queries {
+		q := randomPoints(rng, 1)[0].Vector
+		want := exactTop(points, q, k, func(vectorstore.Point) bool { return true })
+		got := search(t, s, vectorstore.SearchParams{Vector: q, Limit: k})
+		for _, id := range got {
+			if slices.Contains(want, id) {
+				found++
+			}
+		}
+	}
+	if recall := float64(found) / (k * queries); recall < 0.9 {
+		t.Errorf("recall@%d = %.2f, want at least 0.9", k, recall)
+	}
+
+	// A point is its own nearest neighbour
+	got := search(t, s, vectorstore.SearchParams{Vector: points[7].Vector, Limit: 1})
+	if !slices.Equal(got, []string{"p7"}) {
+		t.Errorf("nearest to p7 = %v", got)
+	}
+}
+
+func TestFilteredSearch(t *testing.T) {
+	points := randomPoints(rand.New(rand.NewPCG(3, 4)), 500)
+	ctx := context.Background()
+	workspace := make(map[string]int64, len(points))
+	for _, p := range points {
+		workspace[p.ID] = p.Payload.WorkspaceID
+	}
+
+	// A limit below the candidate count walks the graph; one above it
+	// compares every candidate
+	for _, limit := range []int{1, 1000} {
+		t.Run(fmt.Sprintf("brute force limit %d", limit), func(t *testing.T) {
+			s := openStore(t, Options{Dir: t.TempDir(), BruteForceLimit: limit})
+			defer s.Close()
+			if err := s.Upsert(ctx, points); err != nil {
+				t.Fatal(err)
+			}
+
+			q := points[0].Vector
+			filter := vectorstore.Filter{WorkspaceID: 2}
+			got := search(t, s, vectorstore.SearchParams{Vector: q, Filter: filter, Limit: 5})
+			if len(got) != 5 {
+				t.Fatalf("found %d results, want 5", len(got))
+			}
+			for _, id := range got {
+				if workspace[id] != 2 {
+					t.Errorf("result %s is in workspace %d", id, workspace[id])
+				}
+			}
+			if limit > len(points) {
+				want := exactTop(points, q, 5, func(p vectorstore.Point) bool { return p.Payload.WorkspaceID == 2 })
+				if !slices.Equal(got, want) {
+					t.Errorf("exact filtered search = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	points := randomPoints(rand.New(rand.NewPCG(5, 6)), 200)
+	ctx := context.Background()
+	s := openStore(t, Options{Dir: t.TempDir()})
+	defer s.Close()
+	if err := s.Upsert(ctx, points); err != nil {
+		t.Fatal(err)
+	}
+
+	// File 3 holds p30..p39
+	if err := s.DeleteByFilter(ctx, vectorstore.Filter{FileIDs: []int64{3}}); err != nil {
+		t.Fatal(err)
+	}
+	if s.live != 190 || s.deleted != 10 {
+		t.Errorf("live, deleted = %d, %d, want 190, 10", s.live, s.deleted)
+	}
+	if n, _ := s.Count(ctx, vectorstore.Filter{FileIDs: []int64{3}}); n != 0 {
+		t.Errorf("count of deleted file = %d", n)
+	}
+	deleted := make(map[string]bool)
+	for _, p := range points[30:40] {
+		deleted[p.ID] = true
+	}
+	for _, p := range points[30:40] {
+		for _, id := range search(t, s, vectorstore.SearchParams{Vector: p.Vector, Limit: 5}) {
+			if deleted[id] {
+				t.Errorf("search returned deleted point %s", id)
+			}
+		}
+	}
+
+	// Upserting a changed point tombstones the old node; an unchanged one
+	// is left alone
+	changed := points[0]
+	changed.Vector = points[1].Vector
+	if err := s.Upsert(ctx, []vectorstore.Point{changed, points[2]}); err != nil {
+		t.Fatal(err)
+	}
+	if s.live != 190 || s.deleted != 11 {
+		t.Errorf("after upsert live, deleted = %d, %d, want 190, 11", s.live, s.deleted)
+	}
+
+	if err := s.DeleteByFilter(ctx, vectorstore.Filter{}); !errors.Is(err, vectorstore.ErrEmptyFilter) {
+		t.Errorf("empty filter delete = %v, want ErrEmptyFilter", err)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	points := randomPoints(rand.New(rand.NewPCG(7, 8)), 300)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// A small threshold snapshots part way through, leaving later writes
+	// in the log only
+	s := openStore(t, Options{Dir: dir, SnapshotBytes: 32 << 10})
+	for i := 0; i < len(points); i += 50 {
+		if err := s.Upsert(ctx, points[i:i+50]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.DeleteByFilter(ctx, vectorstore.Filter{RepoIDs: []int64{1}, FileIDs: []int64{0}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, snapshotFile)); err != nil {
+		t.Fatalf("no snapshot written: %v", err)
+	}
+	if s.wal.size == 0 {
+		t.Fatal("log is empty; the test needs writes after the snapshot")
+	}
+
+	q := points[42].Vector
+	want := search(t, s, vectorstore.SearchParams{Vector: q, Limit: 10})
+	wantCount, _ := s.Count(ctx, vectorstore.Filter{})
+	crash(s)
+
+	s = openStore(t, Options{Dir: dir, SnapshotBytes: 32 << 10})
+	if got, _ := s.Count(ctx, vectorstore.Filter{}); got != wantCount || got != 295 {
+		t.Errorf("count after reopen = %d, want %d", got, wantCount)
+	}
+	if got := search(t, s, vectorstore.SearchParams{Vector: q, Limit: 10}); !slices.Equal(got, want) {
+		t.Errorf("search after reopen = %v, want %v", got, want)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Close snapshots, so the next open has nothing to replay
+	s = openStore(t, Options{Dir: dir})
+	defer s.Close()
+	if s.wal.size != 0 {
+		t.Errorf("log holds %d bytes after a clean close", s.wal.size)
+	}
+	if got := search(t, s, vectorstore.SearchParams{Vector: q, Limit: 10}); !slices.Equal(got, want) {
+		t.Errorf("search after clean reopen = %v, want %v", got, want)
+	}
+
+	if err := s.EnsureCollection(ctx, testDimensions*2); err == nil {
+		t.Error("changing the size of a populated store succeeded")
+	}
+}
+
+func TestTornLogTail(t *testing.T) {
+	points := randomPoints(rand.New(rand.NewPCG(9, 10)), 30)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openStore(t, Options{Dir: dir})
+	for _, p := range points {
+		if err := s.Upsert(ctx, []vectorstore.Point{p}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	crash(s)
+
+	// Cut the last record short, as a crash mid-write would
+	path := filepath.Join(dir, walFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openStore(t, Options{Dir: dir})
+	if n, _ := s.Count(ctx, vectorstore.Filter{}); n != 29 {
+		t.Errorf("count after torn tail = %d, want 29", n)
+	}
+	if _, ok := s.ids["p29"]; ok {
+		t.Error("torn record was applied")
+	}
+
+	// New records follow the last intact one and survive another crash
+	if err := s.Upsert(ctx, points[29:]); err != nil {
+		t.Fatal(err)
+	}
+	crash(s)
+
+	s = openStore(t, Options{Dir: dir})
+	defer s.Close()
+	if n, _ := s.Count(ctx, vectorstore.Filter{}); n != 30 {
+		t.Errorf("count after rewrite = %d, want 30", n)
+	}
+	got := search(t, s, vectorstore.SearchParams{Vector: points[29].Vector, Limit: 1})
+	if !slices.Equal(got, []string{"p29"}) {
+		t.Errorf("nearest to p29 = %v", got)
+	}
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	points := randomPoints(rand.New(rand.NewPCG(11, 12)), 3)
+	points[1].Payload.SymbolName = "Store.Search"
+	points[2].Payload.Content = "func main() {}\n"
+
+	for _, r := range []record{
+		{seq: 1, op: opDimensions, dimensions: testDimensions},
+		{seq: 2, op: opUpsert, points: points},
+		{seq: 3, op: opDelete, filter: vectorstore.Filter{WorkspaceID: 1, FilePaths: []string{"main.go"}}},
+	} {
+		body, err := encodeRecord(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodeRecord(body)
+		if err != nil {
+			t.Fatalf("decode op %d: %v", r.op, err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(r) {
+			t.Errorf("round trip of op %d = %+v, want %+v", r.op, got, r)
+		}
+
+		// Every truncation of a record fails to decode rather than
+		// yielding a different record
+		for n := range len(body) {
+			if _, err := decodeRecord(body[:n]); err == nil {
+				t.Errorf("op %d decoded from %d of %d bytes", r.op, n, len(body))
+			}
+		}
+	}
+}
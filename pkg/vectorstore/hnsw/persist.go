@@ -0,0 +1,385 @@
+package hnsw
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gomantics/semantix/pkg/vectorstore"
+)
+
+const (
+	walFile      = "wal.log"
+	snapshotFile = "snapshot.bin"
+
+	snapshotMagic = "SXHNSW1\n"
+)
+
+// WAL record operations
+const (
+	opDimensions byte = iota + 1
+	opUpsert
+	opDelete
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// wal is an append-only log of mutations since the last snapshot. Each
+// record is a 4-byte length, a 4-byte CRC-32C and the body; a torn record
+// at the end, left by a crash mid-write, is discarded on open.
+type wal struct {
+	f    *os.File
+	size int64
+}
+
+func openWAL(path string) (*wal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &wal{f: f}, nil
+}
+
+// replay calls fn with the body of every intact record, then truncates any
+// torn tail so new records follow the last good one
+func (w *wal) replay(fn func(body []byte) error) error {
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(w.f)
+	var offset int64
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			break
+		}
+		n := binary.LittleEndian.Uint32(header[0:4])
+		sum := binary.LittleEndian.Uint32(header[4:8])
+		if n > maxField {
+			break
+		}
+		body := make([]byte, n)
+		if _, err := io.ReadFull(r, body); err != nil {
+			break
+		}
+		if crc32.Checksum(body, crcTable) != sum {
+			break
+		}
+		if err := fn(body); err != nil {
+			return err
+		}
+		offset += 8 + int64(n)
+	}
+
+	if err := w.f.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := w.f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	w.size = offset
+	return nil
+}
+
+// append writes a record and syncs it to disk. A failed write is rolled
+// back so later records aren't hidden behind a torn one.
+func (w *wal) append(body []byte) error {
+	record := make([]byte, 8, 8+len(body))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(body)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.Checksum(body, crcTable))
+	record = append(record, body...)
+
+	_, err := w.f.Write(record)
+	if err == nil {
+		err = w.f.Sync()
+	}
+	if err != nil {
+		if terr := w.truncate(w.size); terr != nil {
+			return errors.Join(err, terr)
+		}
+		return err
+	}
+
+	w.size += int64(len(record))
+	return nil
+}
+
+func (w *wal) truncate(size int64) error {
+	if err := w.f.Truncate(size); err != nil {
+		return err
+	}
+	if _, err := w.f.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+	w.size = size
+	return w.f.Sync()
+}
+
+func (w *wal) close() error {
+	return w.f.Close()
+}
+
+// record is a decoded WAL entry
+type record struct {
+	seq        uint64
+	op         byte
+	dimensions int
+	points     []vectorstore.Point
+	filter     vectorstore.Filter
+}
+
+func encodeRecord(r record) ([]byte, error) {
+	var e encoder
+	e.uvarint(r.seq)
+	e.byte(r.op)
+
+	switch r.op {
+	case opDimensions:
+		e.uvarint(uint64(r.dimensions))
+	case opUpsert:
+		e.uvarint(uint64(len(r.points)))
+		for _, p := range r.points {
+			payload, err := json.Marshal(p.Payload)
+			if err != nil {
+				return nil, err
+			}
+			e.string(p.ID)
+			e.floats(p.Vector)
+			e.bytes(payload)
+		}
+	case opDelete:
+		filter, err := json.Marshal(r.filter)
+		if err != nil {
+			return nil, err
+		}
+		e.bytes(filter)
+	}
+
+	return e.buf, nil
+}
+
+func decodeRecord(body []byte) (record, error) {
+	d := &decoder{r: bufio.NewReader(bytes.NewReader(body))}
+	r := record{seq: d.uvarint(), op: d.byte()}
+
+	switch r.op {
+	case opDimensions:
+		r.dimensions = int(d.uvarint())
+	case opUpsert:
+		n := d.length()
+		for i := 0; i < n && d.err == nil; i++ {
+			p := vectorstore.Point{ID: d.string(), Vector: d.floats()}
+			if payload := d.bytes(); d.err == nil {
+				d.fail(json.Unmarshal(payload, &p.Payload))
+			}
+			r.points = append(r.points, p)
+		}
+	case opDelete:
+		if filter := d.bytes(); d.err == nil {
+			d.fail(json.Unmarshal(filter, &r.filter))
+		}
+	default:
+		d.fail(errCorrupt)
+	}
+
+	if d.err != nil {
+		return record{}, fmt.Errorf("%w: wal record: %w", errCorrupt, d.err)
+	}
+	return r, nil
+}
+
+// snapshot is the state persisted in a snapshot file
+type snapshot struct {
+	seq        uint64
+	dimensions int
+	g          *graph
+}
+
+// writeSnapshot atomically replaces the snapshot in dir: it writes a
+// temporary file, syncs it, renames it over the old one and syncs the
+// directory. The file ends with a CRC-32C of everything before it.
+func writeSnapshot(dir string, s snapshot) error {
+	tmp, err := os.CreateTemp(dir, snapshotFile+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := crc32.New(crcTable)
+	w := bufio.NewWriterSize(io.MultiWriter(tmp, h), 1<<20)
+
+	e := &encoder{}
+	e.buf = append(e.buf, snapshotMagic...)
+	e.uvarint(s.seq)
+	e.uvarint(uint64(s.dimensions))
+	e.varint(int64(s.g.entry))
+	e.uvarint(uint64(s.g.top))
+	e.uvarint(uint64(len(s.g.nodes)))
+
+	for _, n := range s.g.nodes {
+		if n == nil {
+			e.byte(0)
+		} else {
+			payload, err := json.Marshal(n.payload)
+			if err != nil {
+				return err
+			}
+			e.byte(1)
+			e.string(n.id)
+			e.floats(n.vec)
+			e.bytes(payload)
+			e.byte(boolByte(n.deleted))
+			e.uvarint(uint64(len(n.friends)))
+			for _, friends := range n.friends {
+				e.slots(friends)
+			}
+		}
+
+		// Flush per node to keep memory flat
+		if _, err := w.Write(e.buf); err != nil {
+			return err
+		}
+		e.buf = e.buf[:0]
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := binary.Write(tmp, binary.LittleEndian, h.Sum32()); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, snapshotFile)); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// readSnapshot loads the snapshot in dir into a graph with the given
+// parameters, returning a zero snapshot when none exists
+func readSnapshot(dir string, g *graph) (snapshot, error) {
+	f, err := os.Open(filepath.Join(dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshot{g: g}, nil
+	}
+	if err != nil {
+		return snapshot{}, err
+	}
+	defer f.Close()
+
+	if err := verifySnapshot(f); err != nil {
+		return snapshot{}, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return snapshot{}, err
+	}
+
+	d := &decoder{r: bufio.NewReaderSize(f, 1<<20)}
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(d.r, magic); err != nil || string(magic) != snapshotMagic {
+		return snapshot{}, fmt.Errorf("%w: unknown snapshot format", errCorrupt)
+	}
+
+	s := snapshot{g: g, seq: d.uvarint(), dimensions: int(d.uvarint())}
+	g.entry = int(d.varint())
+	g.top = int(d.uvarint())
+	count := d.length()
+
+	g.nodes = make([]*node, count)
+	for slot := 0; slot < count && d.err == nil; slot++ {
+		if d.byte() == 0 {
+			g.free = append(g.free, uint32(slot))
+			continue
+		}
+
+		n := &node{id: d.string(), vec: d.floats()}
+		if payload := d.bytes(); d.err == nil {
+			d.fail(json.Unmarshal(payload, &n.payload))
+		}
+		n.deleted = d.byte() == 1
+		levels := d.length()
+		for l := 0; l < levels && d.err == nil; l++ {
+			n.friends = append(n.friends, d.slots())
+		}
+		g.nodes[slot] = n
+	}
+	if d.err != nil {
+		return snapshot{}, fmt.Errorf("%w: snapshot: %w", errCorrupt, d.err)
+	}
+
+	// Slots and sizes are trusted by the graph code, so check them once here
+	for _, n := range g.nodes {
+		if n == nil {
+			continue
+		}
+		if len(n.vec) != s.dimensions || len(n.friends) == 0 {
+			return snapshot{}, fmt.Errorf("%w: snapshot: malformed node", errCorrupt)
+		}
+		for _, friends := range n.friends {
+			for _, f := range friends {
+				if int(f) >= count || g.nodes[f] == nil {
+					return snapshot{}, fmt.Errorf("%w: snapshot: dangling edge", errCorrupt)
+				}
+			}
+		}
+	}
+	if g.entry >= count || (g.entry >= 0 && g.nodes[g.entry] == nil) {
+		return snapshot{}, fmt.Errorf("%w: snapshot: bad entry point", errCorrupt)
+	}
+
+	return s, nil
+}
+
+func verifySnapshot(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < 4 {
+		return fmt.Errorf("%w: snapshot truncated", errCorrupt)
+	}
+
+	h := crc32.New(crcTable)
+	if _, err := io.Copy(h, io.LimitReader(f, info.Size()-4)); err != nil {
+		return err
+	}
+	var sum uint32
+	if err := binary.Read(f, binary.LittleEndian, &sum); err != nil {
+		return err
+	}
+	if sum != h.Sum32() {
+		return fmt.Errorf("%w: snapshot checksum mismatch", errCorrupt)
+	}
+	return nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
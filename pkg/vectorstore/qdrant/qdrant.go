@@ -0,0 +1,349 @@
+// Package qdrant implements vectorstore.Store with Qdrant's REST API
+package qdrant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gomantics/semantix/pkg/vectorstore"
+)
+
+const (
+	DefaultURL        = "http://localhost:6333"
+	DefaultCollection = "semantix_chunks"
+	DefaultDistance   = "Cosine"
+
+	// defaultUpsertBatch keeps request bodies well under Qdrant's 32MB limit
+	// at 3072 dimensions
+	defaultUpsertBatch = 256
+)
+
+// payloadIndexes are the payload fields searches and deletes filter on
+var payloadIndexes = []struct {
+	field  string
+	schema string
+}{
+	{"workspace_id", "integer"},
+	{"repo_id", "integer"},
+	{"file_id", "integer"},
+	{"file_path", "keyword"},
+	{"language", "keyword"},
+	{"chunk_type", "keyword"},
+}
+
+// Options configures a Qdrant client
+type Options struct {
+	// URL is the REST endpoint, e.g. http://localhost:6333
+	URL        string
+	APIKey     string
+	Collection string
+	// Distance is Cosine, Dot, Euclid or Manhattan
+	Distance string
+	// UpsertBatch bounds the points sent per upsert request
+	UpsertBatch int
+	// HTTPClient defaults to a client with a 30 second timeout
+	HTTPClient *http.Client
+}
+
+// Store is a vectorstore.Store backed by one Qdrant collection
+type Store struct {
+	opts Options
+	base string
+}
+
+var _ vectorstore.Store = (*Store)(nil)
+
+// New creates a client, filling in defaults for unset options
+func New(opts Options) *Store {
+	if opts.URL == "" {
+		opts.URL = DefaultURL
+	}
+	opts.URL = strings.TrimRight(opts.URL, "/")
+	if opts.Collection == "" {
+		opts.Collection = DefaultCollection
+	}
+	if opts.Distance == "" {
+		opts.Distance = DefaultDistance
+	}
+	if opts.UpsertBatch <= 0 {
+		opts.UpsertBatch = defaultUpsertBatch
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &Store{
+		opts: opts,
+		base: opts.URL + "/collections/" + url.PathEscape(opts.Collection),
+	}
+}
+
+func (s *Store) EnsureCollection(ctx context.Context, dimensions int) error {
+	size, err := s.vectorSize(ctx)
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		err = s.do(ctx, http.MethodPut, s.base, map[string]any{
+			"vectors": map[string]any{"size": dimensions, "distance": s.opts.Distance},
+		}, nil)
+		// Another replica may have created it first
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+			return s.EnsureCollection(ctx, dimensions)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create collection: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to read collection: %w", err)
+	case size != dimensions:
+		return fmt.Errorf("%w: collection %s has %d dimensions, embedder has %d",
+			vectorstore.ErrDimensionMismatch, s.opts.Collection, size, dimensions)
+	}
+
+	// Creating an index that exists is a no-op
+	for _, idx := range payloadIndexes {
+		err := s.do(ctx, http.MethodPut, s.base+"/index?wait=true", map[string]any{
+			"field_name":   idx.field,
+			"field_schema": idx.schema,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create payload index %s: %w", idx.field, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) vectorSize(ctx context.Context) (int, error) {
+	var info struct {
+		Config struct {
+			Params struct {
+				Vectors struct {
+					Size int `json:"size"`
+				} `json:"vectors"`
+			} `json:"params"`
+		} `json:"config"`
+	}
+	if err := s.do(ctx, http.MethodGet, s.base, nil, &info); err != nil {
+		return 0, err
+	}
+	return info.Config.Params.Vectors.Size, nil
+}
+
+func (s *Store) Upsert(ctx context.Context, points []vectorstore.Point) error {
+	type point struct {
+		ID      string              `json:"id"`
+		Vector  []float32           `json:"vector"`
+		Payload vectorstore.Payload `json:"payload"`
+	}
+
+	for start := 0; start < len(points); start += s.opts.UpsertBatch {
+		end := min(start+s.opts.UpsertBatch, len(points))
+
+		batch := make([]point, 0, end-start)
+		for _, p := range points[start:end] {
+			batch = append(batch, point{ID: p.ID, Vector: p.Vector, Payload: p.Payload})
+		}
+
+		err := s.do(ctx, http.MethodPut, s.base+"/points?wait=true", map[string]any{"points": batch}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to upsert points: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) DeleteByFilter(ctx context.Context, filter vectorstore.Filter) error {
+	if filter.IsEmpty() {
+		return vectorstore.ErrEmptyFilter
+	}
+
+	err := s.do(ctx, http.MethodPost, s.base+"/points/delete?wait=true", map[string]any{
+		"filter": toFilter(filter),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete points: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Search(ctx context.Context, params vectorstore.SearchParams) ([]vectorstore.Result, error) {
+	req := map[string]any{
+		"vector":       params.Vector,
+		"limit":        max(params.Limit, 1),
+		"offset":       max(params.Offset, 0),
+		"with_payload": true,
+	}
+	if !params.Filter.IsEmpty() {
+		req["filter"] = toFilter(params.Filter)
+	}
+	if params.MinScore > 0 {
+		req["score_threshold"] = params.MinScore
+	}
+
+	var points []struct {
+		ID      any                 `json:"id"`
+		Score   float32             `json:"score"`
+		Payload vectorstore.Payload `json:"payload"`
+	}
+	if err := s.do(ctx, http.MethodPost, s.base+"/points/search", req, &points); err != nil {
+		return nil, fmt.Errorf("failed to search points: %w", err)
+	}
+
+	results := make([]vectorstore.Result, len(points))
+	for i, p := range points {
+		results[i] = vectorstore.Result{ID: fmt.Sprint(p.ID), Score: p.Score, Payload: p.Payload}
+	}
+	return results, nil
+}
+
+func (s *Store) Count(ctx context.Context, filter vectorstore.Filter) (int64, error) {
+	req := map[string]any{"exact": true}
+	if !filter.IsEmpty() {
+		req["filter"] = toFilter(filter)
+	}
+
+	var out struct {
+		Count int64 `json:"count"`
+	}
+	if err := s.do(ctx, http.MethodPost, s.base+"/points/count", req, &out); err != nil {
+		return 0, fmt.Errorf("failed to count points: %w", err)
+	}
+	return out.Count, nil
+}
+
+// Health checks that every shard is ready; it doesn't require the collection
+func (s *Store) Health(ctx context.Context) error {
+	req, err := s.newRequest(ctx, http.MethodGet, s.opts.URL+"/readyz", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return apiError(res)
+	}
+	return nil
+}
+
+// condition is a Qdrant field condition
+type condition struct {
+	Key   string `json:"key"`
+	Match any    `json:"match"`
+}
+
+func toFilter(f vectorstore.Filter) map[string]any {
+	var must []condition
+	if f.WorkspaceID != 0 {
+		must = append(must, condition{"workspace_id", map[string]any{"value": f.WorkspaceID}})
+	}
+	must = appendAny(must, "repo_id", f.RepoIDs)
+	must = appendAny(must, "file_id", f.FileIDs)
+	must = appendAny(must, "file_path", f.FilePaths)
+	must = appendAny(must, "language", f.Languages)
+	must = appendAny(must, "chunk_type", f.ChunkTypes)
+	return map[string]any{"must": must}
+}
+
+func appendAny[T any](must []condition, key string, values []T) []condition {
+	if len(values) == 0 {
+		return must
+	}
+	return append(must, condition{key, map[string]any{"any": values}})
+}
+
+// do sends a JSON request and decodes the "result" field of the response into out
+func (s *Store) do(ctx context.Context, method, endpoint string, body, out any) error {
+	var r io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(raw)
+	}
+
+	req, err := s.newRequest(ctx, method, endpoint, r)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return apiError(res)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("failed to decode qdrant response: %w", err)
+	}
+	if err := json.Unmarshal(envelope.Result, out); err != nil {
+		return fmt.Errorf("failed to decode qdrant response: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) newRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.opts.APIKey != "" {
+		req.Header.Set("api-key", s.opts.APIKey)
+	}
+	return req, nil
+}
+
+// APIError is an error response from Qdrant
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("qdrant: %d: %s", e.StatusCode, e.Message)
+}
+
+func apiError(res *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+
+	var body struct {
+		Status struct {
+			Error string `json:"error"`
+		} `json:"status"`
+	}
+	apiErr := &APIError{StatusCode: res.StatusCode}
+	if json.Unmarshal(raw, &body) == nil && body.Status.Error != "" {
+		apiErr.Message = body.Status.Error
+	} else {
+		apiErr.Message = strings.TrimSpace(string(raw))
+	}
+	return apiErr
+}
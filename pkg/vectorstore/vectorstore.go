@@ -0,0 +1,105 @@
+// Package vectorstore stores chunk embeddings and searches them by similarity
+package vectorstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrDimensionMismatch is returned when a collection exists with a different vector size
+	ErrDimensionMismatch = errors.New("vector dimension mismatch")
+	// ErrEmptyFilter is returned by DeleteByFilter when the filter matches everything
+	ErrEmptyFilter = errors.New("filter must have at least one condition")
+)
+
+// Store is a vector database holding one collection of chunk points.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// EnsureCollection creates the collection and its payload indexes if
+	// they don't exist. Returns ErrDimensionMismatch if the collection
+	// exists with a different vector size.
+	EnsureCollection(ctx context.Context, dimensions int) error
+	// Upsert inserts or replaces points by ID
+	Upsert(ctx context.Context, points []Point) error
+	// DeleteByFilter removes every point matching the filter. An empty
+	// filter is rejected rather than deleting everything.
+	DeleteByFilter(ctx context.Context, filter Filter) error
+	// Search returns the points closest to the vector, best first
+	Search(ctx context.Context, params SearchParams) ([]Result, error)
+	// Count returns the number of points matching the filter
+	Count(ctx context.Context, filter Filter) (int64, error)
+	// Health reports whether the store is reachable and ready
+	Health(ctx context.Context) error
+}
+
+// Point is one embedded chunk
+type Point struct {
+	// ID must be a UUID; use PointID for deterministic IDs
+	ID      string
+	Vector  []float32
+	Payload Payload
+}
+
+// Payload is the chunk metadata stored with each vector
+type Payload struct {
+	WorkspaceID int64  `json:"workspace_id"`
+	RepoID      int64  `json:"repo_id"`
+	FileID      int64  `json:"file_id"`
+	FilePath    string `json:"file_path"`
+	Language    string `json:"language"`
+	Content     string `json:"content"`
+	ContentHash string `json:"content_hash"`
+	ChunkIndex  int    `json:"chunk_index"`
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+	ChunkType   string `json:"chunk_type"`
+	SymbolName  string `json:"symbol_name,omitempty"`
+}
+
+// Filter restricts points by payload. Conditions are ANDed; the values of a
+// list are ORed. Zero values don't filter.
+type Filter struct {
+	WorkspaceID int64
+	RepoIDs     []int64
+	FileIDs     []int64
+	FilePaths   []string
+	Languages   []string
+	ChunkTypes  []string
+}
+
+// IsEmpty reports whether the filter matches every point
+func (f Filter) IsEmpty() bool {
+	return f.WorkspaceID == 0 && len(f.RepoIDs) == 0 && len(f.FileIDs) == 0 &&
+		len(f.FilePaths) == 0 && len(f.Languages) == 0 && len(f.ChunkTypes) == 0
+}
+
+// SearchParams are the parameters of a similarity search
+type SearchParams struct {
+	Vector []float32
+	Filter Filter
+	Limit  int
+	Offset int
+	// MinScore drops results scoring lower; 0 keeps everything
+	MinScore float32
+}
+
+// Result is a point returned by Search
+type Result struct {
+	ID    string
+	Score float32
+	// Payload is the stored chunk metadata
+	Payload Payload
+}
+
+// PointID derives a stable UUID for a chunk, so re-indexing the same chunk
+// replaces its point instead of adding a duplicate
+func PointID(repoID int64, filePath string, chunkIndex int) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d\x00%s\x00%d", repoID, filePath, chunkIndex))
+	// Mark as a version 8 (custom) RFC 9562 UUID
+	sum[6] = sum[6]&0x0f | 0x80
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}